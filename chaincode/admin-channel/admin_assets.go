@@ -7,6 +7,7 @@ type Order struct {
 	CreatedAt       time.Time `json:"CreatedAt"`
 	CreatorID       string    `json:"CreatorID"`
 	DeliveryDate    time.Time `json:"DeliveryDate"`
+	DocType         string    `json:"DocType"`
 	FlagReason      string    `json:"FlagReason"`
 	ID              string    `json:"ID"`
 	IsAccepted      bool      `json:"IsAccepted"`
@@ -16,6 +17,7 @@ type Order struct {
 	PlanID          string    `json:"PlanID"`
 	ProductDetails  string    `json:"ProductDetails"`
 	ReceiverID      string    `json:"ReceiverID"`
+	SchemaVersion   int       `json:"SchemaVersion"`
 	Status          string    `json:"Status"`
 	TotalOrderValue float32   `json:"TotalOrderValue"`
 	UpdatedAt       time.Time `json:"UpdatedAt"`
@@ -26,6 +28,7 @@ type Plan struct {
 	AllFactoriesApproved bool      `json:"AllFactoriesApproved"`
 	CreatedAt            time.Time `json:"CreatedAt"`
 	CreatorID            string    `json:"CreatorID"`
+	DocType              string    `json:"DocType"`
 	Factories            []string  `json:"Factories"`
 	FlagReason           string    `json:"FlagReason"`
 	ID                   string    `json:"ID"`
@@ -35,12 +38,14 @@ type Plan struct {
 	Notes                string    `json:"Notes"`
 	OrderID              string    `json:"OrderID"`
 	ProductionPlan       string    `json:"ProductionPlan"`
+	SchemaVersion        int       `json:"SchemaVersion"`
 	Status               string    `json:"Status"`
 	UpdatedAt            time.Time `json:"UpdatedAt"`
 }
 
 // Asset: Factory
 type Factory struct {
+	DocType            string    `json:"DocType"`
 	FactoryOwner       string    `json:"FactoryOwner"`
 	FlagReason         string    `json:"FlagReason"`
 	ID                 string    `json:"ID"`
@@ -51,6 +56,7 @@ type Factory struct {
 	Name               string    `json:"Name"`
 	Notes              string    `json:"Notes"`
 	PastFulfillment    bool      `json:"PastFulfillment"`
+	SchemaVersion      int       `json:"SchemaVersion"`
 	StartDate          time.Time `json:"StartDate"`
 	Status             string    `json:"Status"`
 	UpdatedAt          time.Time `json:"UpdatedAt"`
@@ -3,10 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
-	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/history"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
 )
 
 // AssetExists returns true when asset with given ID exists in world state
@@ -19,7 +21,7 @@ func (s *SmartContract) AssetExists(ctx contractapi.TransactionContextInterface,
 	return assetJSON != nil, nil
 }
 
-// SetFlag sets the isFlagged field of an asset with a specific ID given the flagReason
+// SetFlag sets the isFlagged field of an asset with a specific ID given the flagReason. Contains the following checks: 1) SPEC_IsValidFlag
 func (s *SmartContract) SetFlag(ctx contractapi.TransactionContextInterface, id string, isFlagged bool, flagReason string) error {
 	// Retrieve the asset from the world state using the provided ID
 	assetJSON, err := ctx.GetStub().GetState(id)
@@ -42,15 +44,24 @@ func (s *SmartContract) SetFlag(ctx contractapi.TransactionContextInterface, id
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
 	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	// Update the updatedAt field
-	asset["UpdatedAt"] = time.Now()
+	asset["UpdatedAt"] = txTimestamp
 	// Marshal the updated asset back to JSON
 	updatedAssetJSON, err := json.Marshal(asset)
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %v", err)
 	}
 	// Save the updated asset to the world state
-	return ctx.GetStub().PutState(id, updatedAssetJSON)
+	if err := ctx.GetStub().PutState(id, updatedAssetJSON); err != nil {
+		return err
+	}
+	// Emit the AssetFlagged event
+	return events.Emit(ctx, events.AssetFlagged, events.AssetFlaggedData{AssetID: id, FlagReason: flagReason, IsFlagged: isFlagged})
 }
 
 // SetNotes sets the notes field of an asset with a specific ID
@@ -71,15 +82,24 @@ func (s *SmartContract) SetNotes(ctx contractapi.TransactionContextInterface, id
 	}
 	// Update the notes field
 	asset["Notes"] = notes
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	// Update the updatedAt field
-	asset["UpdatedAt"] = time.Now()
+	asset["UpdatedAt"] = txTimestamp
 	// Marshal the updated asset back to JSON
 	updatedAssetJSON, err := json.Marshal(asset)
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %v", err)
 	}
 	// Save the updated asset to the world state
-	return ctx.GetStub().PutState(id, updatedAssetJSON)
+	if err := ctx.GetStub().PutState(id, updatedAssetJSON); err != nil {
+		return err
+	}
+	// Emit the AssetNotesUpdated event
+	return events.Emit(ctx, events.AssetNotesUpdated, events.AssetNotesUpdatedData{AssetID: id})
 }
 
 // GetAsset retrieves an asset with a specific ID from the world state
@@ -101,6 +121,11 @@ func (s *SmartContract) GetAsset(ctx contractapi.TransactionContextInterface, id
 	return asset, nil
 }
 
+// GetAssetHistory retrieves every committed version of an asset with a specific ID from the ledger's history, newest first
+func (s *SmartContract) GetAssetHistory(ctx contractapi.TransactionContextInterface, id string) ([]*history.AssetVersion, error) {
+	return history.GetAssetHistory(ctx, id)
+}
+
 // GetAllAssets retrieves all records from the world state
 func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface) ([]map[string]interface{}, error) {
 	// Define a composite key prefix that includes the document type
@@ -147,33 +172,28 @@ func (s *SmartContract) GetAllAssetsCount(ctx contractapi.TransactionContextInte
 	return count, nil
 }
 
-// GetAllAssetsOfType is a helper function not meant for direct client invocation as it retrieves all records from the world state that contain the specified substring in their keys but returns the records as an interface
+// GetAllAssetsOfType is a helper function not meant for direct client invocation as it retrieves all records of the specified record type, reading only the partition of the type index that holds them, but returns the records as an interface
 func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInterface, recordType string) (interface{}, error) {
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	// Retrieve the IDs of the records of the type
+	ids, err := index.IDsOfType(ctx, recordType)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
 
 	var orders []*Order
 	var plans []*Plan
 	var factories []*Factory
 
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
+	for _, id := range ids {
+		recordJSON, err := ctx.GetStub().GetState(id)
 		if err != nil {
-			return nil, err
-		}
-
-		// Check if the key contains the specified substring
-		if !strings.Contains(queryResponse.Key, recordType) {
-			continue
+			return nil, fmt.Errorf("failed to read record %s: %v", id, err)
 		}
 
 		switch recordType {
 		case "order_":
 			var order Order
-			err = json.Unmarshal(queryResponse.Value, &order)
+			err = json.Unmarshal(recordJSON, &order)
 			if err != nil {
 				return nil, err
 			}
@@ -181,7 +201,7 @@ func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInt
 
 		case "plan_":
 			var plan Plan
-			err = json.Unmarshal(queryResponse.Value, &plan)
+			err = json.Unmarshal(recordJSON, &plan)
 			if err != nil {
 				return nil, err
 			}
@@ -189,7 +209,7 @@ func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInt
 
 		case "factory_":
 			var factory Factory
-			err = json.Unmarshal(queryResponse.Value, &factory)
+			err = json.Unmarshal(recordJSON, &factory)
 			if err != nil {
 				return nil, err
 			}
@@ -240,27 +260,13 @@ func (s *SmartContract) GetAllFactories(ctx contractapi.TransactionContextInterf
 	return records.([]*Factory), nil
 }
 
-// GetAllAssetsOfTypeCount retrieves the count of records from the world state that contain the specified substring in their keys
+// GetAllAssetsOfTypeCount retrieves the count of records of the specified record type from the type index
 func (s *SmartContract) GetAllAssetsOfTypeCount(ctx contractapi.TransactionContextInterface, recordType string) (int, error) {
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	// Retrieve the IDs of the records of the type
+	ids, err := index.IDsOfType(ctx, recordType)
 	if err != nil {
 		return 0, err
 	}
-	defer resultsIterator.Close()
-
-	count := 0
-
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return 0, err
-		}
-
-		// Check if the key contains the specified substring
-		if strings.Contains(queryResponse.Key, recordType) {
-			count++
-		}
-	}
 
-	return count, nil
+	return len(ids), nil
 }
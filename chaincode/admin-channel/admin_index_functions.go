@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// BackfillIndexes records the type key of every order, plan and factory. It is meant to be invoked once on ledgers created before the type index existed, as the GetAll* queries only read the records recorded in the type index. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole
+func (s *SmartContract) BackfillIndexes(ctx contractapi.TransactionContextInterface) (*index.Result, error) {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Ensure that the function is invoked by an organization allowed to backfill the indexes
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Backfill, policy.AssetType); err != nil {
+		return nil, err
+	}
+
+	return index.Backfill(ctx)
+}
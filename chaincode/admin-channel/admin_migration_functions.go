@@ -0,0 +1,48 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/schema"
+)
+
+// migrations upgrades the assets written by earlier versions of the contract, one schema version per step. Append a step whenever a field is added to an asset or changes meaning
+var migrations = schema.Migrations{
+	schema.AddDocType, // to version 1
+}
+
+// schemaVersion is the version of the schema new assets are written under
+var schemaVersion = migrations.CurrentVersion()
+
+// MigrateAssets upgrades at most pageSize assets from schema version fromVersion to toVersion, e.g., from version 0, which every asset written before schema versions were recorded is at, to the current version, in key order starting from startKey. It is invoked again with the returned NextStartKey until HasMore is false. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole, 2) SPEC_IsValidMigration
+func (s *SmartContract) MigrateAssets(ctx contractapi.TransactionContextInterface, fromVersion int, toVersion int, startKey string, pageSize int) (*schema.Result, error) {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Ensure that the function is invoked by an organization allowed to migrate assets
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Migrate, policy.AssetType); err != nil {
+		return nil, err
+	}
+	// Ensure that the migration is between known schema versions
+	if err := SPEC_IsValidMigration(fromVersion, toVersion, schemaVersion, pageSize); err != nil {
+		return nil, err
+	}
+
+	result, err := migrations.Migrate(ctx, fromVersion, toVersion, startKey, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	// Emit the AssetsMigrated event
+	if err := events.Emit(ctx, events.AssetsMigrated, events.AssetsMigratedData{AssetIDs: result.MigratedIDs, FromVersion: fromVersion, HasMore: result.HasMore, ToVersion: toVersion}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAssetsPendingMigration returns the assets written under an older schema version than the current one, sorted by ID
+func (s *SmartContract) GetAssetsPendingMigration(ctx contractapi.TransactionContextInterface) ([]*schema.PendingRecord, error) {
+	return migrations.Pending(ctx)
+}
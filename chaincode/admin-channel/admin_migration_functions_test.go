@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/schema"
+)
+
+func TestMigrateAssets(t *testing.T) {
+	s := &SmartContract{}
+	ctx := newTestContext(t)
+	// order_0 and factory_0 were written before schema versions were recorded
+	ctx.Stub().Seed("factory_0", []byte(`{"ID":"factory_0","Name":"Factory Zero","Status":"approved"}`))
+	ctx.Stub().Seed("order_0", []byte(`{"ID":"order_0","ReceiverID":"Org6MSP","Status":"issued","TotalOrderValue":500}`))
+	submit(t, ctx, "Org1MSP", func() error { return createOrder(s, ctx, "order_1", "Org6MSP") })
+
+	getPending := func() string {
+		t.Helper()
+		var pending []*schema.PendingRecord
+		err := ctx.Evaluate("Org3MSP", func() (err error) {
+			pending, err = s.GetAssetsPendingMigration(ctx)
+			return err
+		})
+		checkErr(t, err, "")
+		var ids []string
+		for _, record := range pending {
+			ids = append(ids, fmt.Sprintf("%s@%d", record.ID, record.SchemaVersion))
+		}
+		return fmt.Sprint(ids)
+	}
+	if got := getPending(); got != "[factory_0@0 order_0@0]" {
+		t.Fatalf("expected the two legacy assets to be pending, got %s", got)
+	}
+
+	tests := []struct {
+		name        string
+		mspID       string
+		fromVersion int
+		toVersion   int
+		wantErr     string
+	}{
+		{"auditor", "Org3MSP", 0, schemaVersion, "not invoked by an allowed organization"},
+		{"unknown version", "Org2MSP", 0, schemaVersion + 1, "is newer than the current schema version"},
+		{"downgrade", "Org2MSP", schemaVersion, 0, "must go from an older to a newer schema version"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ctx.Submit(test.mspID, func() error {
+				_, err := s.MigrateAssets(ctx, test.fromVersion, test.toVersion, "", 10)
+				return err
+			})
+			checkErr(t, err, test.wantErr)
+		})
+	}
+
+	// The agent migrates one asset per page, each page resuming where the previous one stopped, until none is left
+	startKey := ""
+	for _, want := range []string{"[factory_0] true order_0", "[order_0] false "} {
+		var result *schema.Result
+		submit(t, ctx, "Org2MSP", func() (err error) {
+			result, err = s.MigrateAssets(ctx, 0, schemaVersion, startKey, 1)
+			return err
+		})
+		startKey = result.NextStartKey
+		if got := fmt.Sprint(result.MigratedIDs, " ", result.HasMore, " ", result.NextStartKey); got != want {
+			t.Errorf("expected page %s, got %s", want, got)
+		}
+		event := checkLastEvent(t, ctx, events.AssetsMigrated)
+		if data := event.Data.(*events.AssetsMigratedData); fmt.Sprint(data.AssetIDs) != fmt.Sprint(result.MigratedIDs) || data.ToVersion != schemaVersion {
+			t.Errorf("unexpected event data %+v", data)
+		}
+	}
+	if got := getPending(); got != "[]" {
+		t.Errorf("expected no pending assets, got %s", got)
+	}
+	order := getOrder(t, ctx, "order_0")
+	if order.DocType != "order_" || order.SchemaVersion != schemaVersion || order.TotalOrderValue != 500 {
+		t.Errorf("unexpected migrated order %+v", order)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+)
+
+// The *Page types are single pages of the paginated GetAll* queries. Bookmark is passed to the next call to continue and is empty after the last page
+
+// AssetPage is a page of GetAllAssetsWithPagination
+type AssetPage struct {
+	Assets              []map[string]interface{} `json:"Assets"`
+	Bookmark            string                   `json:"Bookmark"`
+	FetchedRecordsCount int                      `json:"FetchedRecordsCount"`
+}
+
+// OrderPage is a page of GetAllOrdersWithPagination
+type OrderPage struct {
+	Bookmark            string   `json:"Bookmark"`
+	FetchedRecordsCount int      `json:"FetchedRecordsCount"`
+	Orders              []*Order `json:"Orders"`
+}
+
+// PlanPage is a page of GetAllPlansWithPagination
+type PlanPage struct {
+	Bookmark            string  `json:"Bookmark"`
+	FetchedRecordsCount int     `json:"FetchedRecordsCount"`
+	Plans               []*Plan `json:"Plans"`
+}
+
+// FactoryPage is a page of GetAllFactoriesWithPagination
+type FactoryPage struct {
+	Bookmark            string     `json:"Bookmark"`
+	Factories           []*Factory `json:"Factories"`
+	FetchedRecordsCount int        `json:"FetchedRecordsCount"`
+}
+
+// GetAllAssetsWithPagination retrieves a page of at most pageSize records from the world state, starting from the bookmark. Like every paginated query, it can only be evaluated, not submitted. Contains the following specifications: 1) SPEC_IsValidPageSize
+func (s *SmartContract) GetAllAssetsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AssetPage, error) {
+	// Ensure that the page size is within bounds
+	if err := SPEC_IsValidPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination("", "", pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state by range: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &AssetPage{Assets: []map[string]interface{}{}, Bookmark: metadata.GetBookmark()}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through results: %v", err)
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		page.Assets = append(page.Assets, record)
+	}
+	page.FetchedRecordsCount = len(page.Assets)
+	return page, nil
+}
+
+// GetAllAssetsOfTypeWithPagination is a helper function not meant for direct client invocation as it retrieves a page of at most pageSize records of the specified record type, starting from the bookmark and reading only the partition of the type index that holds them, but returns the page as an interface. Contains the following specifications: 1) SPEC_IsValidPageSize
+func (s *SmartContract) GetAllAssetsOfTypeWithPagination(ctx contractapi.TransactionContextInterface, recordType string, pageSize int32, bookmark string) (interface{}, error) {
+	// Ensure that the page size is within bounds
+	if err := SPEC_IsValidPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	// Retrieve the IDs of a page of records of the type
+	ids, nextBookmark, err := index.IDsOfTypeWithPagination(ctx, recordType, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	recordJSONs := [][]byte{}
+	for _, id := range ids {
+		recordJSON, err := ctx.GetStub().GetState(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read record %s: %v", id, err)
+		}
+		recordJSONs = append(recordJSONs, recordJSON)
+	}
+
+	switch recordType {
+	case "order_":
+		page := &OrderPage{Bookmark: nextBookmark, FetchedRecordsCount: len(recordJSONs)}
+		return page, unmarshalRecords(recordJSONs, &page.Orders)
+	case "plan_":
+		page := &PlanPage{Bookmark: nextBookmark, FetchedRecordsCount: len(recordJSONs)}
+		return page, unmarshalRecords(recordJSONs, &page.Plans)
+	case "factory_":
+		page := &FactoryPage{Bookmark: nextBookmark, FetchedRecordsCount: len(recordJSONs)}
+		return page, unmarshalRecords(recordJSONs, &page.Factories)
+	default:
+		return nil, fmt.Errorf("invalid record type: %s", recordType)
+	}
+}
+
+// GetAllOrdersWithPagination retrieves a page of orders
+func (s *SmartContract) GetAllOrdersWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*OrderPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "order_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*OrderPage), nil
+}
+
+// GetAllPlansWithPagination retrieves a page of plans
+func (s *SmartContract) GetAllPlansWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*PlanPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "plan_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*PlanPage), nil
+}
+
+// GetAllFactoriesWithPagination retrieves a page of factories
+func (s *SmartContract) GetAllFactoriesWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*FactoryPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "factory_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*FactoryPage), nil
+}
+
+// unmarshalRecords unmarshals the JSON of each record into the slice records points to, which is empty rather than nil when there are no records
+func unmarshalRecords(recordJSONs [][]byte, records interface{}) error {
+	arrayJSON := append(append([]byte("["), bytes.Join(recordJSONs, []byte(","))...), ']')
+	if err := json.Unmarshal(arrayJSON, records); err != nil {
+		return fmt.Errorf("failed to unmarshal records: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetAllOrdersWithPagination(t *testing.T) {
+	s := &SmartContract{}
+	ctx := newTestContext(t)
+	for _, orderID := range []string{"order_1", "order_2", "order_3"} {
+		submit(t, ctx, "Org1MSP", func() error { return createOrder(s, ctx, orderID, "Org6MSP") })
+	}
+	submit(t, ctx, "Org1MSP", func() error { return createFactory(s, ctx, "factory_1") })
+
+	first, err := s.GetAllOrdersWithPagination(ctx, 2, "")
+	checkErr(t, err, "")
+	if first.FetchedRecordsCount != 2 || first.Bookmark == "" || first.Orders[0].ID != "order_1" {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+	last, err := s.GetAllOrdersWithPagination(ctx, 2, first.Bookmark)
+	checkErr(t, err, "")
+	if last.FetchedRecordsCount != 1 || last.Bookmark != "" || last.Orders[0].ID != "order_3" {
+		t.Errorf("unexpected last page %+v", last)
+	}
+
+	plans, err := s.GetAllPlansWithPagination(ctx, 2, "")
+	checkErr(t, err, "")
+	if plans.Plans == nil || plans.FetchedRecordsCount != 0 {
+		t.Errorf("expected an empty page of plans, got %+v", plans)
+	}
+	_, err = s.GetAllFactoriesWithPagination(ctx, 1001, "")
+	checkErr(t, err, "the page size must be between 1 and 1000, got 1001")
+}
+
+func TestGetAllAssetsWithPagination(t *testing.T) {
+	s := &SmartContract{}
+	ctx := newTestContext(t)
+	submit(t, ctx, "Org1MSP", func() error { return createOrder(s, ctx, "order_1", "Org6MSP") })
+	submit(t, ctx, "Org1MSP", func() error { return createFactory(s, ctx, "factory_1") })
+
+	page, err := s.GetAllAssetsWithPagination(ctx, 1, "")
+	checkErr(t, err, "")
+	if page.FetchedRecordsCount != 1 || fmt.Sprint(page.Assets[0]["ID"]) != "factory_1" || page.Bookmark == "" {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+	page, err = s.GetAllAssetsWithPagination(ctx, 1, page.Bookmark)
+	checkErr(t, err, "")
+	if page.FetchedRecordsCount != 1 || fmt.Sprint(page.Assets[0]["ID"]) != "order_1" || page.Bookmark != "" {
+		t.Errorf("unexpected last page %+v", page)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/richquery"
+)
+
+// QueryAssets retrieves a page of at most pageSize orders, plans and factories matching a CouchDB selector, starting from the bookmark, e.g., the accepted orders with {"DocType": "order_", "Status": "accepted"}. It needs a CouchDB state database and can only be evaluated, not submitted. Contains the following specifications: 1) SPEC_IsValidSelector, 2) SPEC_IsValidPageSize
+func (s *SmartContract) QueryAssets(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int32, bookmark string) (*AssetPage, error) {
+	// Ensure that the selector is a JSON object with at least one condition
+	if err := SPEC_IsValidSelector(selectorJSON); err != nil {
+		return nil, err
+	}
+	// Ensure that the page size is within bounds
+	if err := SPEC_IsValidPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	records, nextBookmark, err := richquery.Records(ctx, selectorJSON, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AssetPage{Assets: []map[string]interface{}{}, Bookmark: nextBookmark}
+	for _, record := range records {
+		var asset map[string]interface{}
+		if err := json.Unmarshal(record.Value, &asset); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", record.Key, err)
+		}
+		page.Assets = append(page.Assets, asset)
+	}
+	page.FetchedRecordsCount = len(page.Assets)
+	return page, nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestQueryAssets(t *testing.T) {
+	s := &SmartContract{}
+	ctx := newTestContext(t)
+	for _, orderID := range []string{"order_1", "order_2", "order_3"} {
+		submit(t, ctx, "Org1MSP", func() error { return createOrder(s, ctx, orderID, "Org6MSP") })
+	}
+	submit(t, ctx, "Org1MSP", func() error { return createFactory(s, ctx, "factory_1") })
+
+	// Only orders are issued, two at a time
+	first, err := s.QueryAssets(ctx, `{"Status": "issued"}`, 2, "")
+	checkErr(t, err, "")
+	if first.FetchedRecordsCount != 2 || first.Bookmark == "" || first.Assets[0]["ID"] != "order_1" {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+	last, err := s.QueryAssets(ctx, `{"Status": "issued"}`, 2, first.Bookmark)
+	checkErr(t, err, "")
+	if last.FetchedRecordsCount != 1 || last.Bookmark != "" || last.Assets[0]["ID"] != "order_3" {
+		t.Errorf("unexpected last page %+v", last)
+	}
+
+	// The role policy and the index keys are never returned
+	all, err := s.QueryAssets(ctx, `{"ID": {"$exists": true}}`, 10, "")
+	checkErr(t, err, "")
+	if all.FetchedRecordsCount != 4 {
+		t.Errorf("expected the 4 records, got %+v", all)
+	}
+	none, err := s.QueryAssets(ctx, `{"DocType": "plan_"}`, 10, "")
+	checkErr(t, err, "")
+	if none.Assets == nil || none.FetchedRecordsCount != 0 {
+		t.Errorf("expected an empty page, got %+v", none)
+	}
+
+	_, err = s.QueryAssets(ctx, `{}`, 10, "")
+	checkErr(t, err, "the selector must have at least one condition")
+	_, err = s.QueryAssets(ctx, `{"Status": "issued"}`, 0, "")
+	checkErr(t, err, "the page size must be between 1 and 1000, got 0")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// defaultRolePolicy returns the role policy in force until SetRolePolicy stores one. The retailer and the auditor approve plans and factories, and the retailer and the agent set the status of orders, govern the policy, migrate assets to new schema versions and backfill the type index
+func defaultRolePolicy() *policy.RolePolicy {
+	return &policy.RolePolicy{
+		Roles: map[string]*policy.Role{
+			policy.Agent: {
+				MSPIDs: []string{"Org2MSP"},
+				Operations: map[string][]string{
+					"order_":         {policy.SetStatus},
+					policy.AssetType: {policy.Update, policy.Migrate, policy.Backfill},
+				},
+			},
+			policy.Auditor: {
+				MSPIDs: []string{"Org3MSP"},
+				Operations: map[string][]string{
+					"factory_": {policy.Approve},
+					"plan_":    {policy.Approve},
+				},
+			},
+			policy.FullPackageSupplier: {
+				MSPIDs:     []string{"Org6MSP"},
+				Operations: map[string][]string{},
+			},
+			policy.RawMaterialSupplier: {
+				MSPIDs:     []string{"Org4MSP"},
+				Operations: map[string][]string{},
+			},
+			policy.Retailer: {
+				MSPIDs: []string{"Org1MSP"},
+				Operations: map[string][]string{
+					"factory_":       {policy.Approve},
+					"order_":         {policy.SetStatus},
+					"plan_":          {policy.Approve},
+					policy.AssetType: {policy.Update, policy.Migrate, policy.Backfill},
+				},
+			},
+			policy.TextileMill: {
+				MSPIDs:     []string{"Org5MSP"},
+				Operations: map[string][]string{},
+			},
+		},
+	}
+}
+
+// ReadRolePolicy returns the role policy in force
+func ReadRolePolicy(ctx contractapi.TransactionContextInterface) (*policy.RolePolicy, error) {
+	return policy.Read(ctx, defaultRolePolicy())
+}
+
+// GetRolePolicy retrieves the role policy in force, which is the default policy (Version 0) until SetRolePolicy is first invoked
+func (s *SmartContract) GetRolePolicy(ctx contractapi.TransactionContextInterface) (*policy.RolePolicy, error) {
+	return ReadRolePolicy(ctx)
+}
+
+// SetRolePolicy replaces the role policy in force, e.g., to add a second textile mill to the textile-mill role. UpdatedAt, UpdatedBy and Version are set by the contract. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole, 2) SPEC_IsValidRolePolicy
+func (s *SmartContract) SetRolePolicy(ctx contractapi.TransactionContextInterface, rolePolicyJSON string) error {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to update the role policy
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Update, policy.AssetType); err != nil {
+		return err
+	}
+
+	var newRolePolicy policy.RolePolicy
+	err = json.Unmarshal([]byte(rolePolicyJSON), &newRolePolicy)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal role policy: %v", err)
+	}
+	// Ensure that the new role policy is well formed and still allows someone to update it
+	if err := SPEC_IsValidRolePolicy(&newRolePolicy); err != nil {
+		return err
+	}
+
+	// Retrieve the invoking organization's MSP ID
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	newRolePolicy.UpdatedAt = txTimestamp
+	newRolePolicy.UpdatedBy = clientMSPID
+	newRolePolicy.Version = rolePolicy.Version + 1
+
+	// Save the role policy to the world state
+	if err := policy.Write(ctx, &newRolePolicy); err != nil {
+		return err
+	}
+	// Emit the RolePolicyUpdated event
+	return events.Emit(ctx, events.RolePolicyUpdated, events.RolePolicyUpdatedData{Roles: newRolePolicy.RoleNames(), Version: newRolePolicy.Version})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+func TestSetRolePolicy(t *testing.T) {
+	secondAuditor := defaultRolePolicy()
+	secondAuditor.Roles[policy.Auditor].MSPIDs = append(secondAuditor.Roles[policy.Auditor].MSPIDs, "Org7MSP")
+	secondAuditorJSON, err := json.Marshal(secondAuditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		mspID   string
+		wantErr string
+	}{
+		{"retailer", "Org1MSP", ""},
+		{"agent", "Org2MSP", ""},
+		{"auditor", "Org3MSP", "not invoked by an allowed organization"},
+		{"full-package supplier", "Org6MSP", "not invoked by an allowed organization"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &SmartContract{}
+			ctx := newTestContext(t)
+			err := ctx.Submit(test.mspID, func() error { return s.SetRolePolicy(ctx, string(secondAuditorJSON)) })
+			checkErr(t, err, test.wantErr)
+			if test.wantErr != "" {
+				return
+			}
+
+			event := checkLastEvent(t, ctx, events.RolePolicyUpdated)
+			if data := event.Data.(*events.RolePolicyUpdatedData); data.Version != 1 {
+				t.Errorf("unexpected event data %+v", data)
+			}
+			rolePolicy, err := s.GetRolePolicy(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rolePolicy.UpdatedBy != test.mspID || !rolePolicy.HasRole("Org7MSP", policy.Auditor) {
+				t.Errorf("unexpected role policy %+v", rolePolicy)
+			}
+		})
+	}
+}
+
+func TestApprovalByOnboardedAuditor(t *testing.T) {
+	s := &SmartContract{}
+	ctx := newTestContext(t)
+	submit(t, ctx, "Org6MSP", func() error { return createFactory(s, ctx, "factory_1") })
+
+	// Org7MSP is not an auditor until the policy says so
+	err := ctx.Submit("Org7MSP", func() error { return s.SetFactoryApproval(ctx, "factory_1", true) })
+	checkErr(t, err, "Organizations allowed to approve factory_: [Org1MSP Org3MSP]")
+
+	rolePolicy := defaultRolePolicy()
+	rolePolicy.Roles[policy.Auditor].MSPIDs = []string{"Org7MSP"}
+	rolePolicyJSON, err := json.Marshal(rolePolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	submit(t, ctx, "Org2MSP", func() error { return s.SetRolePolicy(ctx, string(rolePolicyJSON)) })
+
+	// The replaced auditor is refused, and the new auditor's approval counts as the auditor's
+	err = ctx.Submit("Org3MSP", func() error { return s.SetFactoryApproval(ctx, "factory_1", true) })
+	checkErr(t, err, "not invoked by an allowed organization")
+	submit(t, ctx, "Org7MSP", func() error { return s.SetFactoryApproval(ctx, "factory_1", true) })
+	submit(t, ctx, "Org1MSP", func() error { return s.SetFactoryApproval(ctx, "factory_1", true) })
+	submit(t, ctx, "Org1MSP", func() error { return s.SetFactoryStatus(ctx, "factory_1") })
+	factory, err := s.GetAsset(ctx, "factory_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if factory["Status"] != "approved" || factory["IsAuditorApproved"] != true {
+		t.Errorf("expected the factory to be approved by the auditor, got %v", factory)
+	}
+}
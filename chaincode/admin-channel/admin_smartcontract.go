@@ -7,6 +7,10 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
 )
 
 // SmartContract provides functions for managing an Asset
@@ -33,10 +37,17 @@ func (s *SmartContract) CreateOrder(ctx contractapi.TransactionContextInterface,
 	if err != nil {
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	order := Order{
 		CreatedAt:       createdAt,
 		CreatorID:       clientMSPID,
 		DeliveryDate:    deliveryDate,
+		DocType:         "order_",
 		FlagReason:      flagReason,
 		ID:              orderID,
 		IsAccepted:      false,
@@ -46,9 +57,10 @@ func (s *SmartContract) CreateOrder(ctx contractapi.TransactionContextInterface,
 		PlanID:          "",
 		ProductDetails:  productDetails,
 		ReceiverID:      receiverID,
+		SchemaVersion:   schemaVersion,
 		Status:          "issued",
 		TotalOrderValue: totalOrderValue,
-		UpdatedAt:       time.Now(),
+		UpdatedAt:       txTimestamp,
 	}
 	// Ensure that the dates are in chronological order
 	if err := SPEC_Chronology(order.CreatedAt, order.UpdatedAt, order.DeliveryDate); err != nil {
@@ -60,7 +72,15 @@ func (s *SmartContract) CreateOrder(ctx contractapi.TransactionContextInterface,
 		return err
 	}
 	// Save the order to the world state
-	return ctx.GetStub().PutState(orderID, orderJSON)
+	if err := ctx.GetStub().PutState(orderID, orderJSON); err != nil {
+		return err
+	}
+	// Index the order by type
+	if err := index.PutType(ctx, orderID); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: orderID, AssetType: "order_"})
 }
 
 // CreatePlan issues a new asset (plan) to the state with select attributes. Contains the following 5 specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_AssetExists, 4) SPEC_IsValidFlag, 5) SPEC_Chronology
@@ -92,10 +112,17 @@ func (s *SmartContract) CreatePlan(ctx contractapi.TransactionContextInterface,
 	if err != nil {
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	plan := Plan{
 		AllFactoriesApproved: false,
 		CreatedAt:            createdAt,
 		CreatorID:            clientMSPID,
+		DocType:              "plan_",
 		Factories:            factoryIDs,
 		FlagReason:           flagReason,
 		ID:                   planID,
@@ -105,8 +132,9 @@ func (s *SmartContract) CreatePlan(ctx contractapi.TransactionContextInterface,
 		Notes:                notes,
 		OrderID:              orderID,
 		ProductionPlan:       productionPlan,
+		SchemaVersion:        schemaVersion,
 		Status:               "issued",
-		UpdatedAt:            time.Now(),
+		UpdatedAt:            txTimestamp,
 	}
 	// Ensure that the dates are in chronological order
 	if err := SPEC_Chronology(plan.CreatedAt, plan.UpdatedAt); err != nil {
@@ -118,7 +146,15 @@ func (s *SmartContract) CreatePlan(ctx contractapi.TransactionContextInterface,
 		return err
 	}
 	// Save the plan to the world state
-	return ctx.GetStub().PutState(planID, planJSON)
+	if err := ctx.GetStub().PutState(planID, planJSON); err != nil {
+		return err
+	}
+	// Index the plan by type
+	if err := index.PutType(ctx, planID); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: planID, AssetType: "plan_"})
 }
 
 // CreateFactory issues a new asset (factory) to the world state with select attributes. Contains the following 4 specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsValidFlag, 4) SPEC_Chronology
@@ -135,7 +171,14 @@ func (s *SmartContract) CreateFactory(ctx contractapi.TransactionContextInterfac
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
 	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	factory := Factory{
+		DocType:            "factory_",
 		FactoryOwner:       factoryOwner,
 		FlagReason:         flagReason,
 		ID:                 factoryID,
@@ -146,9 +189,10 @@ func (s *SmartContract) CreateFactory(ctx contractapi.TransactionContextInterfac
 		Name:               name,
 		Notes:              notes,
 		PastFulfillment:    pastFulfillment,
+		SchemaVersion:      schemaVersion,
 		StartDate:          startDate,
 		Status:             "pending",
-		UpdatedAt:          time.Now(),
+		UpdatedAt:          txTimestamp,
 	}
 	// Ensure that the dates are in chronological order
 	if err := SPEC_Chronology(factory.StartDate, factory.UpdatedAt); err != nil {
@@ -160,7 +204,15 @@ func (s *SmartContract) CreateFactory(ctx contractapi.TransactionContextInterfac
 		return err
 	}
 	// Save the factory to the world state
-	return ctx.GetStub().PutState(factoryID, factoryJSON)
+	if err := ctx.GetStub().PutState(factoryID, factoryJSON); err != nil {
+		return err
+	}
+	// Index the factory by type
+	if err := index.PutType(ctx, factoryID); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: factoryID, AssetType: "factory_"})
 }
 
 // SetOrderAcceptance updates the IsAccepted field of an Order. Contains the following 3 specifications: 1) SPEC_IsInvokedByAllowedOrg, 2) SPEC_AssetExists, 3) SPEC_Chronology
@@ -190,8 +242,13 @@ func (s *SmartContract) SetOrderAcceptance(ctx contractapi.TransactionContextInt
 	order.PlanID = planID
 	// Update the IsAccepted field
 	order.IsAccepted = acceptance
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	// Update the updatedAt field
-	order.UpdatedAt = time.Now()
+	order.UpdatedAt = txTimestamp
 	// Ensure that the dates are in chronological order
 	if err := SPEC_Chronology(order.CreatedAt, order.UpdatedAt, order.DeliveryDate); err != nil {
 		return err
@@ -205,14 +262,19 @@ func (s *SmartContract) SetOrderAcceptance(ctx contractapi.TransactionContextInt
 	if err != nil {
 		return fmt.Errorf("failed to update order: %v", err)
 	}
-
-	return nil
+	// Emit the OrderAcceptanceChanged event
+	return events.Emit(ctx, events.OrderAcceptanceChanged, events.OrderAcceptanceChangedData{IsAccepted: order.IsAccepted, OrderID: orderID, PlanID: order.PlanID})
 }
 
-// SetOrderStatus updates the Status field of an Order based on specific conditions. Contains the following 2 specifications: 1) SPEC_IsInvokedByAllowedOrg, 2) SPEC_IsReadyforApproval
+// SetOrderStatus updates the Status field of an Order based on specific conditions. Contains the following 2 specifications: 1) SPEC_IsInvokedByAllowedRole, 2) SPEC_IsReadyforApproval
 func (s *SmartContract) SetOrderStatus(ctx contractapi.TransactionContextInterface, orderID string, newStatus string) error {
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to set the status of orders
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.SetStatus, "order_"); err != nil {
 		return err
 	}
 	// Retrieve the order from the world state
@@ -250,8 +312,14 @@ func (s *SmartContract) SetOrderStatus(ctx contractapi.TransactionContextInterfa
 		return fmt.Errorf("new status must be 'accepted', 'cancelled', or 'rejected', got: '%s' instead", newStatus)
 	}
 	// Update the order status and updatedAt
+	previousStatus := order.Status
 	order.Status = newStatus
-	order.UpdatedAt = time.Now()
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	order.UpdatedAt = txTimestamp
 	// Marshal the updated order and put it back in the world state
 	updatedOrderJSON, err := json.Marshal(order)
 	if err != nil {
@@ -261,14 +329,19 @@ func (s *SmartContract) SetOrderStatus(ctx contractapi.TransactionContextInterfa
 	if err != nil {
 		return fmt.Errorf("failed to update order: %v", err)
 	}
-
-	return nil
+	// Emit the OrderStatusChanged event
+	return events.Emit(ctx, events.OrderStatusChanged, events.OrderStatusChangedData{OrderID: orderID, PreviousStatus: previousStatus, Status: order.Status})
 }
 
-// SetPlanApproval updates the relevant fields of a plan based on whether the retailer or auditor invokes it. Contains the following 1 specification: 1) SPEC_IsInvokedByAllowedOrg
+// SetPlanApproval updates the relevant fields of a plan based on whether the retailer or auditor invokes it. Contains the following 1 specification: 1) SPEC_IsInvokedByAllowedRole
 func (s *SmartContract) SetPlanApproval(ctx contractapi.TransactionContextInterface, planID string, approval bool) error {
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org3MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to approve plans
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Approve, "plan_"); err != nil {
 		return err
 	}
 	// Retrieve the plan from the world state
@@ -290,14 +363,19 @@ func (s *SmartContract) SetPlanApproval(ctx contractapi.TransactionContextInterf
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 	// Check who is invoking and whether to update the approval status
-	if clientMSPID == "Org1MSP" && approval {
+	if rolePolicy.HasRole(clientMSPID, policy.Retailer) && approval {
 		plan.IsRetailerApproved = true
 	}
-	if clientMSPID == "Org3MSP" && approval {
+	if rolePolicy.HasRole(clientMSPID, policy.Auditor) && approval {
 		plan.IsAuditorApproved = true
 	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	// Update the updatedAt field
-	plan.UpdatedAt = time.Now()
+	plan.UpdatedAt = txTimestamp
 	// Marshal the updated order and put it back in the world state
 	updatedPlanJSON, err := json.Marshal(plan)
 	if err != nil {
@@ -307,7 +385,8 @@ func (s *SmartContract) SetPlanApproval(ctx contractapi.TransactionContextInterf
 	if err != nil {
 		return fmt.Errorf("failed to update plan: %v", err)
 	}
-	return nil
+	// Emit the PlanApproved event
+	return events.Emit(ctx, events.PlanApproved, events.PlanApprovedData{Approval: approval, IsAuditorApproved: plan.IsAuditorApproved, IsRetailerApproved: plan.IsRetailerApproved, PlanID: planID, Status: plan.Status})
 }
 
 // SetPlanStatus updates the Status field of a Plan based on whether it is approved by the retailer and auditor, and also if all factories are approved. Contains the following 1 specification: 1) SPEC_IsReadyforApproval
@@ -352,8 +431,13 @@ func (s *SmartContract) SetPlanStatus(ctx contractapi.TransactionContextInterfac
 	}
 	// Set the status to "approved" if all conditions are met
 	plan.Status = "approved"
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	// Update the updatedAt field
-	plan.UpdatedAt = time.Now()
+	plan.UpdatedAt = txTimestamp
 	// Marshal the updated plan and put it back in the world state
 	updatedPlanJSON, err := json.Marshal(plan)
 	if err != nil {
@@ -363,13 +447,19 @@ func (s *SmartContract) SetPlanStatus(ctx contractapi.TransactionContextInterfac
 	if err != nil {
 		return fmt.Errorf("failed to update plan: %v", err)
 	}
-	return nil
+	// Emit the PlanApproved event
+	return events.Emit(ctx, events.PlanApproved, events.PlanApprovedData{Approval: true, IsAuditorApproved: plan.IsAuditorApproved, IsRetailerApproved: plan.IsRetailerApproved, PlanID: planID, Status: plan.Status})
 }
 
-// SetFactoryApproval updates the IsRetailerApproved and IsAuditorApproved fields of a Factory based on specific conditions. Contains the following 1 specification: 1) SPEC_IsInvokedByAllowedOrg. Only works for Org1MSP and Org3MSP.
+// SetFactoryApproval updates the IsRetailerApproved and IsAuditorApproved fields of a Factory based on specific conditions. Contains the following 1 specification: 1) SPEC_IsInvokedByAllowedRole. Only works for members of the retailer and auditor roles.
 func (s *SmartContract) SetFactoryApproval(ctx contractapi.TransactionContextInterface, factoryID string, approval bool) error {
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org3MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to approve factories
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Approve, "factory_"); err != nil {
 		return err
 	}
 	// Retrieve the factory from the world state
@@ -391,14 +481,19 @@ func (s *SmartContract) SetFactoryApproval(ctx contractapi.TransactionContextInt
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 	// Check who is invoking and whether to update the approval status
-	if clientMSPID == "Org1MSP" && approval {
+	if rolePolicy.HasRole(clientMSPID, policy.Retailer) && approval {
 		factory.IsRetailerApproved = true
 	}
-	if clientMSPID == "Org3MSP" && approval {
+	if rolePolicy.HasRole(clientMSPID, policy.Auditor) && approval {
 		factory.IsAuditorApproved = true
 	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	// Update the updatedAt field
-	factory.UpdatedAt = time.Now()
+	factory.UpdatedAt = txTimestamp
 	// Marshal the updated factory and put it back in the world state
 	updatedFactoryJSON, err := json.Marshal(factory)
 	if err != nil {
@@ -408,7 +503,8 @@ func (s *SmartContract) SetFactoryApproval(ctx contractapi.TransactionContextInt
 	if err != nil {
 		return fmt.Errorf("failed to update factory: %v", err)
 	}
-	return nil
+	// Emit the FactoryApproved event
+	return events.Emit(ctx, events.FactoryApproved, events.FactoryApprovedData{Approval: approval, FactoryID: factoryID, IsAuditorApproved: factory.IsAuditorApproved, IsRetailerApproved: factory.IsRetailerApproved, Status: factory.Status})
 }
 
 // SetFactoryStatus updates the Status field of a Factory based on whether it was approved by both: auditor and retailer. Contains the following 1 specification: 1) SPEC_IsReadyforApproval
@@ -432,8 +528,13 @@ func (s *SmartContract) SetFactoryStatus(ctx contractapi.TransactionContextInter
 	}
 	// Set the status to "approved" if all conditions are met
 	factory.Status = "approved"
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	// Update the updatedAt field
-	factory.UpdatedAt = time.Now()
+	factory.UpdatedAt = txTimestamp
 	// Marshal the updated factory and put it back in the world state
 	updatedFactoryJSON, err := json.Marshal(factory)
 	if err != nil {
@@ -443,7 +544,8 @@ func (s *SmartContract) SetFactoryStatus(ctx contractapi.TransactionContextInter
 	if err != nil {
 		return fmt.Errorf("failed to update factory: %v", err)
 	}
-	return nil
+	// Emit the FactoryApproved event
+	return events.Emit(ctx, events.FactoryApproved, events.FactoryApprovedData{Approval: true, FactoryID: factoryID, IsAuditorApproved: factory.IsAuditorApproved, IsRetailerApproved: factory.IsRetailerApproved, Status: factory.Status})
 }
 
 func main() {
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+)
+
+var (
+	// before is earlier than every transaction timestamp of the test ledger
+	before = chaincodetest.GenesisTime.AddDate(0, -1, 0)
+	// after is later than every transaction timestamp of the test ledger
+	after = chaincodetest.GenesisTime.AddDate(1, 0, 0)
+)
+
+func newTestContext(t *testing.T) *chaincodetest.TransactionContext {
+	t.Helper()
+	return chaincodetest.NewTransactionContext("admin", "Org1MSP")
+}
+
+// submit runs fn as a transaction of the given organization and fails the test if it returns an error
+func submit(t *testing.T, ctx *chaincodetest.TransactionContext, mspID string, fn func() error) {
+	t.Helper()
+	if err := ctx.Submit(mspID, fn); err != nil {
+		t.Fatalf("setup transaction failed: %v", err)
+	}
+}
+
+// checkErr fails the test unless err matches wantErr, where an empty wantErr means no error
+func checkErr(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" && err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wantErr != "" && (err == nil || !strings.Contains(err.Error(), wantErr)) {
+		t.Fatalf("expected error containing %q, got %v", wantErr, err)
+	}
+}
+
+// checkLastEvent fails the test unless the last committed event has the given name and a valid envelope
+func checkLastEvent(t *testing.T, ctx *chaincodetest.TransactionContext, name string) *events.Event {
+	t.Helper()
+	last := ctx.Stub().LastEvent()
+	if last == nil {
+		t.Fatalf("expected event %s, got none", name)
+	}
+	event, err := events.Decode(last.EventName, last.Payload)
+	if err != nil {
+		t.Fatalf("failed to decode event %s: %v", last.EventName, err)
+	}
+	if event.Name != name {
+		t.Fatalf("expected event %s, got %s", name, event.Name)
+	}
+	return event
+}
+
+func getOrder(t *testing.T, ctx *chaincodetest.TransactionContext, id string) Order {
+	t.Helper()
+	var order Order
+	orderJSON, _ := ctx.GetStub().GetState(id)
+	if err := json.Unmarshal(orderJSON, &order); err != nil {
+		t.Fatalf("failed to read order %s: %v", id, err)
+	}
+	return order
+}
+
+func createOrder(s *SmartContract, ctx contractapi.TransactionContextInterface, orderID string, receiverID string) error {
+	return s.CreateOrder(ctx, before, after, "", orderID, false, "", "net 30", "1000 shirts", receiverID, 10000)
+}
+
+func createFactory(s *SmartContract, ctx contractapi.TransactionContextInterface, factoryID string) error {
+	return s.CreateFactory(ctx, "Org6MSP", "", factoryID, false, "Dhaka", "Factory One", "", true, before)
+}
+
+func TestNewChaincode(t *testing.T) {
+	if _, err := contractapi.NewChaincode(&SmartContract{}); err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+}
+
+func TestCreateOrder(t *testing.T) {
+	tests := []struct {
+		name         string
+		orderID      string
+		createdAt    time.Time
+		deliveryDate time.Time
+		isFlagged    bool
+		flagReason   string
+		wantErr      string
+	}{
+		{"valid", "order_2", before, after, false, "", ""},
+		{"flagged with reason", "order_2", before, after, true, "late payment", ""},
+		{"wrong prefix", "plan_2", before, after, false, "", "must start with 'order_'"},
+		{"already exists", "order_1", before, after, false, "", "already exists"},
+		{"flagged without reason", "order_2", before, after, true, "", "flagReason must be provided"},
+		{"reason without flag", "order_2", before, after, false, "late payment", "flagReason must be empty"},
+		{"created in the future", "order_2", after, after.AddDate(0, 1, 0), false, "", "is not after"},
+		{"delivery in the past", "order_2", before, before.AddDate(0, 0, 1), false, "", "is not after"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &SmartContract{}
+			ctx := newTestContext(t)
+			submit(t, ctx, "Org1MSP", func() error { return createOrder(s, ctx, "order_1", "Org6MSP") })
+
+			err := ctx.Submit("Org1MSP", func() error {
+				return s.CreateOrder(ctx, test.createdAt, test.deliveryDate, test.flagReason, test.orderID, test.isFlagged, "", "net 30", "1000 shirts", "Org6MSP", 10000)
+			})
+			checkErr(t, err, test.wantErr)
+			if test.wantErr != "" {
+				return
+			}
+
+			order := getOrder(t, ctx, test.orderID)
+			if order.CreatorID != "Org1MSP" || order.Status != "issued" || order.IsAccepted || order.IsFlagged != test.isFlagged || order.DocType != "order_" || order.SchemaVersion != schemaVersion {
+				t.Errorf("unexpected order %+v", order)
+			}
+			event := checkLastEvent(t, ctx, events.AssetCreated)
+			if data := event.Data.(*events.AssetCreatedData); data.AssetID != test.orderID || data.AssetType != "order_" {
+				t.Errorf("unexpected event data %+v", data)
+			}
+		})
+	}
+}
+
+func TestCreatePlan(t *testing.T) {
+	tests := []struct {
+		name       string
+		planID     string
+		orderID    string
+		factoryIDs []string
+		createdAt  time.Time
+		isFlagged  bool
+		flagReason string
+		wantErr    string
+	}{
+		{"valid", "plan_1", "order_1", []string{"factory_1"}, before, false, "", ""},
+		{"without factories", "plan_1", "order_1", []string{}, before, false, "", ""},
+		{"wrong prefix", "order_2", "order_1", []string{"factory_1"}, before, false, "", "must start with 'plan_'"},
+		{"already exists", "plan_0", "order_1", []string{"factory_1"}, before, false, "", "already exists"},
+		{"missing order", "plan_1", "order_9", []string{"factory_1"}, before, false, "", "order_9 does not exist"},
+		{"missing factory", "plan_1", "order_1", []string{"factory_1", "factory_9"}, before, false, "", "factory_9 does not exist"},
+		{"flagged without reason", "plan_1", "order_1", []string{"factory_1"}, before, true, "N/A", "flagReason must be provided"},
+		{"created in the future", "plan_1", "order_1", []string{"factory_1"}, after, false, "", "is not after"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &SmartContract{}
+			ctx := newTestContext(t)
+			submit(t, ctx, "Org1MSP", func() error { return createOrder(s, ctx, "order_1", "Org6MSP") })
+			submit(t, ctx, "Org6MSP", func() error { return createFactory(s, ctx, "factory_1") })
+			submit(t, ctx, "Org6MSP", func() error {
+				return s.CreatePlan(ctx, before, []string{"factory_1"}, "", "plan_0", false, "", "order_1", "cut and sew")
+			})
+
+			err := ctx.Submit("Org6MSP", func() error {
+				return s.CreatePlan(ctx, test.createdAt, test.factoryIDs, test.flagReason, test.planID, test.isFlagged, "", test.orderID, "cut and sew")
+			})
+			checkErr(t, err, test.wantErr)
+			if test.wantErr != "" {
+				return
+			}
+
+			plan, err := s.GetAsset(ctx, test.planID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan["CreatorID"] != "Org6MSP" || plan["Status"] != "issued" || plan["OrderID"] != test.orderID {
+				t.Errorf("unexpected plan %v", plan)
+			}
+			checkLastEvent(t, ctx, events.AssetCreated)
+		})
+	}
+}
+
+func TestCreateFactory(t *testing.T) {
+	tests := []struct {
+		name       string
+		factoryID  string
+		startDate  time.Time
+		isFlagged  bool
+		flagReason string
+		wantErr    string
+	}{
+		{"valid", "factory_2", before, false, "", ""},
+		{"flagged with reason", "factory_2", before, true, "failed inspection", ""},
+		{"wrong prefix", "order_2", before, false, "", "must start with 'factory_'"},
+		{"already exists", "factory_1", before, false, "", "already exists"},
+		{"flagged without reason", "factory_2", before, true, "", "flagReason must be provided"},
+		{"started in the future", "factory_2", after, false, "", "is not after"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &SmartContract{}
+			ctx := newTestContext(t)
+			submit(t, ctx, "Org6MSP", func() error { return createFactory(s, ctx, "factory_1") })
+
+			err := ctx.Submit("Org6MSP", func() error {
+				return s.CreateFactory(ctx, "Org6MSP", test.flagReason, test.factoryID, test.isFlagged, "Dhaka", "Factory Two", "", false, test.startDate)
+			})
+			checkErr(t, err, test.wantErr)
+			if test.wantErr != "" {
+				return
+			}
+
+			factory, err := s.GetAsset(ctx, test.factoryID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if factory["Status"] != "pending" || factory["IsFlagged"] != test.isFlagged {
+				t.Errorf("unexpected factory %v", factory)
+			}
+			checkLastEvent(t, ctx, events.AssetCreated)
+		})
+	}
+}
+
+// newApprovedPlanLedger returns a ledger with an order for Org6MSP and a plan whose factory, retailer and auditor approvals are complete
+func newApprovedPlanLedger(t *testing.T, s *SmartContract) *chaincodetest.TransactionContext {
+	t.Helper()
+	ctx := newTestContext(t)
+	submit(t, ctx, "Org1MSP", func() error { return createOrder(s, ctx, "order_1", "Org6MSP") })
+	submit(t, ctx, "Org6MSP", func() error { return createFactory(s, ctx, "factory_1") })
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreatePlan(ctx, before, []string{"factory_1"}, "", "plan_1", false, "", "order_1", "cut and sew")
+	})
+	for _, mspID := range []string{"Org1MSP", "Org3MSP"} {
+		submit(t, ctx, mspID, func() error { return s.SetFactoryApproval(ctx, "factory_1", true) })
+		submit(t, ctx, mspID, func() error { return s.SetPlanApproval(ctx, "plan_1", true) })
+	}
+	submit(t, ctx, "Org1MSP", func() error { return s.SetFactoryStatus(ctx, "factory_1") })
+	submit(t, ctx, "Org1MSP", func() error { return s.SetPlanStatus(ctx, "plan_1") })
+	return ctx
+}
+
+func TestAccessControl(t *testing.T) {
+	tests := []struct {
+		name    string
+		mspID   string
+		invoke  func(s *SmartContract, ctx contractapi.TransactionContextInterface) error
+		wantErr string
+	}{
+		{"order receiver accepts order", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetOrderAcceptance(ctx, "order_1", "plan_1", true)
+		}, ""},
+		{"retailer accepts order for receiver", "Org1MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetOrderAcceptance(ctx, "order_1", "plan_1", true)
+		}, "not invoked by an allowed organization"},
+		{"retailer sets factory approval", "Org1MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetFactoryApproval(ctx, "factory_1", true)
+		}, ""},
+		{"auditor sets factory approval", "Org3MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetFactoryApproval(ctx, "factory_1", true)
+		}, ""},
+		{"supplier sets factory approval", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetFactoryApproval(ctx, "factory_1", true)
+		}, "not invoked by an allowed organization"},
+		{"retailer sets plan approval", "Org1MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetPlanApproval(ctx, "plan_1", true)
+		}, ""},
+		{"agent sets plan approval", "Org2MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetPlanApproval(ctx, "plan_1", true)
+		}, "not invoked by an allowed organization"},
+		{"supplier sets plan approval", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetPlanApproval(ctx, "plan_1", true)
+		}, "not invoked by an allowed organization"},
+		{"supplier sets order status", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetOrderStatus(ctx, "order_1", "accepted")
+		}, "not invoked by an allowed organization"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &SmartContract{}
+			ctx := newApprovedPlanLedger(t, s)
+			err := ctx.Submit(test.mspID, func() error { return test.invoke(s, ctx) })
+			checkErr(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestSetOrderStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		mspID     string
+		accept    bool
+		newStatus string
+		wantErr   string
+	}{
+		{"retailer accepts", "Org1MSP", true, "accepted", ""},
+		{"agent cancels", "Org2MSP", true, "cancelled", ""},
+		{"unknown status", "Org1MSP", true, "shipped", "must be 'accepted', 'cancelled', or 'rejected'"},
+		{"order not accepted by receiver", "Org1MSP", false, "accepted", "condition 1 failed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &SmartContract{}
+			ctx := newApprovedPlanLedger(t, s)
+			submit(t, ctx, "Org6MSP", func() error { return s.SetOrderAcceptance(ctx, "order_1", "plan_1", test.accept) })
+
+			err := ctx.Submit(test.mspID, func() error { return s.SetOrderStatus(ctx, "order_1", test.newStatus) })
+			checkErr(t, err, test.wantErr)
+			if test.wantErr != "" {
+				return
+			}
+
+			if order := getOrder(t, ctx, "order_1"); order.Status != test.newStatus {
+				t.Errorf("expected status %s, got %s", test.newStatus, order.Status)
+			}
+			event := checkLastEvent(t, ctx, events.OrderStatusChanged)
+			if data := event.Data.(*events.OrderStatusChangedData); data.PreviousStatus != "issued" || data.Status != test.newStatus {
+				t.Errorf("unexpected event data %+v", data)
+			}
+		})
+	}
+}
+
+func TestApprovalWorkflow(t *testing.T) {
+	s := &SmartContract{}
+	ctx := newTestContext(t)
+	submit(t, ctx, "Org6MSP", func() error { return createFactory(s, ctx, "factory_1") })
+
+	// The factory cannot be approved before both the retailer and the auditor approve it
+	submit(t, ctx, "Org1MSP", func() error { return s.SetFactoryApproval(ctx, "factory_1", true) })
+	err := ctx.Submit("Org1MSP", func() error { return s.SetFactoryStatus(ctx, "factory_1") })
+	checkErr(t, err, "condition 1 failed")
+
+	submit(t, ctx, "Org3MSP", func() error { return s.SetFactoryApproval(ctx, "factory_1", true) })
+	submit(t, ctx, "Org1MSP", func() error { return s.SetFactoryStatus(ctx, "factory_1") })
+	factory, err := s.GetAsset(ctx, "factory_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if factory["Status"] != "approved" {
+		t.Fatalf("expected the factory to be approved, got %v", factory["Status"])
+	}
+
+	// A flagged plan is never approved
+	submit(t, ctx, "Org1MSP", func() error { return createOrder(s, ctx, "order_1", "Org6MSP") })
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreatePlan(ctx, before, []string{"factory_1"}, "", "plan_1", false, "", "order_1", "cut and sew")
+	})
+	for _, mspID := range []string{"Org1MSP", "Org3MSP"} {
+		submit(t, ctx, mspID, func() error { return s.SetPlanApproval(ctx, "plan_1", true) })
+	}
+	submit(t, ctx, "Org3MSP", func() error { return s.SetFlag(ctx, "plan_1", true, "missing audit report") })
+	err = ctx.Submit("Org1MSP", func() error { return s.SetPlanStatus(ctx, "plan_1") })
+	checkErr(t, err, "condition 4 failed")
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestSPEC_IsReadyforApproval(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []bool
+		wantErr    string
+	}{
+		{"all met", []bool{true, true, true}, ""},
+		{"none given", nil, ""},
+		{"first failed", []bool{false, true}, "condition 1 failed"},
+		{"third failed", []bool{true, true, false}, "condition 3 failed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_IsReadyforApproval(test.conditions...), test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/spec"
+)
+
+// specRegistry records the specifications enforced by each function of the contract, in the order they are checked. It must list the same specifications as the doc comment of each function
+var specRegistry = spec.NewRegistry().
+	Register("AssetExists").
+	Register("BackfillIndexes", "SPEC_IsInvokedByAllowedRole").
+	Register("CreateFactory", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsValidFlag", "SPEC_Chronology").
+	Register("CreateOrder", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsValidFlag", "SPEC_Chronology").
+	Register("CreatePlan", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_AssetExists", "SPEC_IsValidFlag", "SPEC_Chronology").
+	Register("GetAllAssets").
+	Register("GetAllAssetsCount").
+	Register("GetAllAssetsOfType").
+	Register("GetAllAssetsOfTypeCount").
+	Register("GetAllAssetsOfTypeWithPagination", "SPEC_IsValidPageSize").
+	Register("GetAllAssetsWithPagination", "SPEC_IsValidPageSize").
+	Register("GetAllFactories").
+	Register("GetAllFactoriesWithPagination").
+	Register("GetAllOrders").
+	Register("GetAllOrdersWithPagination").
+	Register("GetAllPlans").
+	Register("GetAllPlansWithPagination").
+	Register("GetAsset").
+	Register("GetAssetHistory").
+	Register("GetAssetsPendingMigration").
+	Register("GetFunctionSpecifications").
+	Register("GetRolePolicy").
+	Register("MigrateAssets", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidMigration").
+	Register("QueryAssets", "SPEC_IsValidSelector", "SPEC_IsValidPageSize").
+	Register("SetFactoryApproval", "SPEC_IsInvokedByAllowedRole").
+	Register("SetFactoryStatus", "SPEC_IsReadyforApproval").
+	Register("SetFlag", "SPEC_IsValidFlag").
+	Register("SetNotes").
+	Register("SetOrderAcceptance", "SPEC_IsInvokedByAllowedOrg", "SPEC_AssetExists", "SPEC_Chronology").
+	Register("SetOrderStatus", "SPEC_IsInvokedByAllowedRole", "SPEC_IsReadyforApproval").
+	Register("SetPlanApproval", "SPEC_IsInvokedByAllowedRole").
+	Register("SetPlanStatus", "SPEC_IsReadyforApproval").
+	Register("SetRolePolicy", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidRolePolicy")
+
+// GetFunctionSpecifications returns the specifications enforced by each function of the contract, sorted by function name
+func (s *SmartContract) GetFunctionSpecifications(ctx contractapi.TransactionContextInterface) ([]*spec.FunctionSpecification, error) {
+	return specRegistry.Functions(), nil
+}
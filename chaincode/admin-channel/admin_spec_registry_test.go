@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+// TestSpecRegistry ensures that the registry, the doc comments and the code agree on the specifications of every contract function
+func TestSpecRegistry(t *testing.T) {
+	functions, err := chaincodetest.ContractFunctions(".", "SmartContract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered, err := (&SmartContract{}).GetFunctionSpecifications(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range registered {
+		function, ok := functions[entry.Function]
+		if !ok {
+			t.Errorf("%s is registered but is not a function of the contract", entry.Function)
+			continue
+		}
+		if fmt.Sprint(entry.Specifications) != fmt.Sprint(function.Called) {
+			t.Errorf("%s: registered %v, but calls %v", entry.Function, entry.Specifications, function.Called)
+		}
+		if fmt.Sprint(function.Documented) != fmt.Sprint(function.Called) {
+			t.Errorf("%s: documented %v, but calls %v", entry.Function, function.Documented, function.Called)
+		}
+		delete(functions, entry.Function)
+	}
+	for name := range functions {
+		t.Errorf("%s is not registered", name)
+	}
+}
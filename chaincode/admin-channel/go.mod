@@ -2,7 +2,10 @@ module github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/adm
 
 go 1.22.2
 
-require github.com/hyperledger/fabric-contract-api-go v1.2.2
+require (
+	github.com/hyperledger/fabric-contract-api-go v1.2.2
+	github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared v0.0.0-00010101000000-000000000000
+)
 
 require (
 	github.com/go-openapi/jsonpointer v0.20.0 // indirect
@@ -31,3 +34,5 @@ require (
 	google.golang.org/protobuf v1.31.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared => ../shared
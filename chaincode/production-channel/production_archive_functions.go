@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+)
+
+// tombstoneIndex is the composite key object type of the tombstones written by ArchiveAsset. The archived record itself is kept unchanged, so that its history and trace stay intact
+const tombstoneIndex = "archived~asset"
+
+// Tombstone marks an asset as archived, e.g., a cotton bale created with the wrong weight
+type Tombstone struct {
+	ArchivedAt time.Time `json:"ArchivedAt"`
+	ArchivedBy string    `json:"ArchivedBy"` // MSP ID of the organization that archived the asset
+	AssetID    string    `json:"AssetID"`
+	Reason     string    `json:"Reason"`
+}
+
+// ArchiveAsset marks an asset as archived with a tombstone. Archived assets cannot be placed in lots and are left out of the GetAll* queries unless requested. An asset that any lot, yarn, fabric, cut part, garment, carton, container or bill of lading still references cannot be archived, and archiving a lot releases its content. Contains the following specifications: 1) SPEC_AssetExists, 2) SPEC_IsInvokedByAllowedOrg, 3) SPEC_IsNotArchived, 4) SPEC_IsUnreferenced
+func (s *SmartContract) ArchiveAsset(ctx contractapi.TransactionContextInterface, id string, reason string) error {
+	// Ensure the asset exists
+	if err := SPEC_AssetExists(ctx, id); err != nil {
+		return err
+	}
+	asset, err := getAssetMap(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by the organization that created the asset
+	creatorID, _ := asset["CreatorID"].(string)
+	if err := SPEC_IsInvokedByAllowedOrg(ctx, creatorID); err != nil {
+		return err
+	}
+	// Ensure that the asset was not archived already
+	if err := SPEC_IsNotArchived(ctx, id); err != nil {
+		return err
+	}
+	// Ensure that no other asset references the asset
+	if err := SPEC_IsUnreferenced(ctx, id); err != nil {
+		return err
+	}
+	if len(reason) == 0 {
+		return fmt.Errorf("a reason must be provided to archive asset %s", id)
+	}
+
+	// Retrieve the invoking organization's MSP ID
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	tombstoneJSON, err := json.Marshal(Tombstone{ArchivedAt: txTimestamp, ArchivedBy: clientMSPID, AssetID: id, Reason: reason})
+	if err != nil {
+		return err
+	}
+	tombstoneKey, err := ctx.GetStub().CreateCompositeKey(tombstoneIndex, []string{id})
+	if err != nil {
+		return fmt.Errorf("failed to create tombstone key for asset %s: %v", id, err)
+	}
+	if err := ctx.GetStub().PutState(tombstoneKey, tombstoneJSON); err != nil {
+		return fmt.Errorf("failed to save tombstone of asset %s: %v", id, err)
+	}
+	// Release the content of an archived lot, so that it can be placed in another lot
+	if strings.HasPrefix(id, "lot_") {
+		if err := deleteLotMembership(ctx, id, GetUpstreamLinks(asset)); err != nil {
+			return err
+		}
+	}
+	// Emit the AssetArchived event
+	return events.Emit(ctx, events.AssetArchived, events.AssetArchivedData{AssetID: id, Reason: reason})
+}
+
+// GetArchivedAssets returns the tombstone of every archived asset, sorted by asset ID
+func (s *SmartContract) GetArchivedAssets(ctx contractapi.TransactionContextInterface) ([]*Tombstone, error) {
+	return getTombstones(ctx)
+}
+
+// getTombstones reads every tombstone, sorted by asset ID
+func getTombstones(ctx contractapi.TransactionContextInterface) ([]*Tombstone, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(tombstoneIndex, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tombstones: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	tombstones := []*Tombstone{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through tombstones: %v", err)
+		}
+		var tombstone Tombstone
+		if err := json.Unmarshal(queryResponse.Value, &tombstone); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tombstone: %v", err)
+		}
+		tombstones = append(tombstones, &tombstone)
+	}
+	return tombstones, nil
+}
+
+// getTombstone returns the tombstone of an asset, or nil if the asset is not archived
+func getTombstone(ctx contractapi.TransactionContextInterface, id string) (*Tombstone, error) {
+	tombstoneKey, err := ctx.GetStub().CreateCompositeKey(tombstoneIndex, []string{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tombstone key for asset %s: %v", id, err)
+	}
+	tombstoneJSON, err := ctx.GetStub().GetState(tombstoneKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tombstone of asset %s: %v", id, err)
+	}
+	if tombstoneJSON == nil {
+		return nil, nil
+	}
+	var tombstone Tombstone
+	if err := json.Unmarshal(tombstoneJSON, &tombstone); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tombstone of asset %s: %v", id, err)
+	}
+	return &tombstone, nil
+}
+
+// getArchivedIDs returns the IDs of every archived asset, read with a single query so that GetAll* queries do not read a tombstone per asset
+func getArchivedIDs(ctx contractapi.TransactionContextInterface) (map[string]bool, error) {
+	tombstones, err := getTombstones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	archivedIDs := map[string]bool{}
+	for _, tombstone := range tombstones {
+		archivedIDs[tombstone.AssetID] = true
+	}
+	return archivedIDs, nil
+}
+
+// deleteLotMembership deletes the lot membership key of each asset in content that is stored in the given lot
+func deleteLotMembership(ctx contractapi.TransactionContextInterface, lotID string, content []string) error {
+	for _, assetID := range content {
+		memberKey, err := ctx.GetStub().CreateCompositeKey(lotMembershipIndex, []string{assetID})
+		if err != nil {
+			return fmt.Errorf("failed to create lot membership key for asset %s: %v", assetID, err)
+		}
+		memberLotID, err := ctx.GetStub().GetState(memberKey)
+		if err != nil {
+			return fmt.Errorf("failed to read lot membership of asset %s: %v", assetID, err)
+		}
+		if string(memberLotID) != lotID {
+			continue
+		}
+		if err := ctx.GetStub().DelState(memberKey); err != nil {
+			return fmt.Errorf("failed to delete lot membership of asset %s: %v", assetID, err)
+		}
+	}
+	return nil
+}
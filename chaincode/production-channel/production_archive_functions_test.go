@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+)
+
+// allAssetIDs returns the IDs of the assets returned by GetAllAssets, or by GetAllAssetsIncludingArchived if includeArchived is true
+func allAssetIDs(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext, includeArchived bool) []string {
+	t.Helper()
+	getAllAssets := s.GetAllAssets
+	if includeArchived {
+		getAllAssets = s.GetAllAssetsIncludingArchived
+	}
+	assets, err := getAllAssets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, asset := range assets {
+		ids = append(ids, asset["ID"].(string))
+	}
+	return ids
+}
+
+func TestArchiveAsset(t *testing.T) {
+	archive := func(id string, reason string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.ArchiveAsset(ctx, id, reason)
+		}
+	}
+	run(t, []testCase{
+		{"unused bale", "Org4MSP", archive("cottonbale_3", "wrong weight"), ""},
+		{"not the creator", "Org1MSP", archive("cottonbale_3", "wrong weight"), "not invoked by an allowed organization"},
+		{"bale in a lot", "Org4MSP", archive("cottonbale_1", "wrong weight"), "asset cottonbale_1 is still referenced by [lot_1]"},
+		{"lot drawn from", "Org4MSP", archive("lot_1", "wrong weight"), "asset lot_1 is still referenced by [cottonyarn_1]"},
+		{"loaded carton", "Org6MSP", archive("carton_1", "wrong weight"), "asset carton_1 is still referenced by [container_1]"},
+		{"no reason", "Org4MSP", archive("cottonbale_3", ""), "a reason must be provided to archive asset cottonbale_3"},
+		{"missing asset", "Org4MSP", archive("cottonbale_9", "wrong weight"), "the asset cottonbale_9 does not exist"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		event, err := events.Decode(ctx.Stub().LastEvent().EventName, ctx.Stub().LastEvent().Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, ok := event.Data.(*events.AssetArchivedData); !ok || data.AssetID != "cottonbale_3" || data.Reason != "wrong weight" {
+			t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+		}
+		tombstones, err := s.GetArchivedAssets(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tombstones) != 1 || tombstones[0].AssetID != "cottonbale_3" || tombstones[0].ArchivedBy != "Org4MSP" || tombstones[0].ArchivedAt.IsZero() {
+			t.Errorf("unexpected tombstones %+v", tombstones)
+		}
+
+		// The archived bale is left out of the queries unless requested, but its record is kept
+		if ids := allAssetIDs(t, s, ctx, false); contains(ids, "cottonbale_3") || !contains(ids, "cottonbale_2") {
+			t.Errorf("expected cottonbale_3 to be left out, got %v", ids)
+		}
+		if ids := allAssetIDs(t, s, ctx, true); !contains(ids, "cottonbale_3") {
+			t.Errorf("expected cottonbale_3 to be included on request, got %v", ids)
+		}
+		all, err := s.GetAllAssetsCountIncludingArchived(ctx)
+		checkErr(t, err, "")
+		live, err := s.GetAllAssetsCount(ctx)
+		checkErr(t, err, "")
+		if all-live != 1 {
+			t.Errorf("expected one archived asset to be left out of the count, got %d of %d", live, all)
+		}
+		allBales, err := s.GetAllAssetsOfTypeCountIncludingArchived(ctx, "cottonbale_")
+		checkErr(t, err, "")
+		liveBales, err := s.GetAllCottonBales(ctx)
+		checkErr(t, err, "")
+		if allBales-len(liveBales) != 1 {
+			t.Errorf("expected one archived bale to be left out of GetAllCottonBales, got %d of %d", len(liveBales), allBales)
+		}
+		bales, err := s.GetAllCottonBalesIncludingArchived(ctx)
+		checkErr(t, err, "")
+		if len(bales) != allBales {
+			t.Errorf("expected GetAllCottonBalesIncludingArchived to return all %d bales, got %d", allBales, len(bales))
+		}
+		if _, err := getAssetMap(ctx, "cottonbale_3"); err != nil {
+			t.Errorf("expected the archived record to be kept, got %v", err)
+		}
+
+		// The archived bale can neither be archived again nor placed in a lot
+		err = ctx.Submit("Org4MSP", func() error { return s.ArchiveAsset(ctx, "cottonbale_3", "wrong weight") })
+		checkErr(t, err, "asset cottonbale_3 was archived by Org4MSP: wrong weight")
+		err = ctx.Submit("Org4MSP", func() error {
+			return s.CreateLot(ctx, before, "cottonbale_", []string{"cottonbale_3"}, "Org4MSP", "", "lot_9", false, "", "Texas", "Org4MSP", 500)
+		})
+		checkErr(t, err, "asset cottonbale_3 was archived by Org4MSP: wrong weight")
+	})
+}
+
+func TestArchiveLot(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonbale_", []string{"cottonbale_3"}, "Org4MSP", "", "lot_9", false, "", "Texas", "Org4MSP", 500)
+	})
+
+	// Archiving the mistaken lot releases its bale, which can then be placed in another lot or archived
+	submit(t, ctx, "Org4MSP", func() error { return s.ArchiveAsset(ctx, "lot_9", "created with the wrong destination") })
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonbale_", []string{"cottonbale_3"}, "Org5MSP", "", "lot_10", false, "", "Texas", "Org4MSP", 500)
+	})
+	err := ctx.Submit("Org4MSP", func() error { return s.ArchiveAsset(ctx, "cottonbale_3", "wrong weight") })
+	checkErr(t, err, "asset cottonbale_3 is still referenced by [lot_10]")
+
+	lots, err := s.GetAllLots(ctx)
+	checkErr(t, err, "")
+	for _, lot := range lots {
+		if lot.ID == "lot_9" {
+			t.Errorf("expected the archived lot_9 to be left out, got %+v", lot)
+		}
+	}
+}
@@ -4,39 +4,70 @@ import "time"
 
 // Asset: CottonBale
 type CottonBale struct {
-	Approval     bool      `json:"Approval"`
-	AssemblyDate time.Time `json:"AssemblyDate"`
-	CreatorID    string    `json:"CreatorID"` // programmatically updated
-	FlagReason   string    `json:"FlagReason"`
-	ID           string    `json:"ID"`
-	IsFlagged    bool      `json:"IsFlagged"`
-	Notes        string    `json:"Notes"`
-	Origin       string    `json:"Origin"`
-	QualityGrade string    `json:"QualityGrade"`
-	TotalWeight  float32   `json:"TotalWeight"` // inputted by the user
-	UpdatedAt    time.Time `json:"UpdatedAt"`   // programmatically updated
+	Approval      bool      `json:"Approval"`
+	AssemblyDate  time.Time `json:"AssemblyDate"`
+	CreatorID     string    `json:"CreatorID"` // programmatically updated
+	DocType       string    `json:"DocType"`   // ID prefix of the asset programmatically updated
+	FlagReason    string    `json:"FlagReason"`
+	ID            string    `json:"ID"`
+	IsFlagged     bool      `json:"IsFlagged"`
+	Notes         string    `json:"Notes"`
+	Origin        string    `json:"Origin"`
+	QualityGrade  string    `json:"QualityGrade"`
+	SchemaVersion int       `json:"SchemaVersion"` // version of the schema the asset was written under programmatically updated
+	TotalWeight   float32   `json:"TotalWeight"`   // inputted by the user
+	UpdatedAt     time.Time `json:"UpdatedAt"`     // programmatically updated
+	WeightUnit    string    `json:"WeightUnit"`    // unit of every weight of the asset, "lb" programmatically updated
 }
 
 // Asset: Lot
 type Lot struct {
-	AllAssetsApproved bool      `json:"AllAssetsApproved"` // programmatically updated
-	AssemblyDate      time.Time `json:"AssemblyDate"`
-	AssetIDPrefix     string    `json:"AssetIDPrefix"`
-	Content           []string  `json:"Content"`       // IDs of asset with AssetIDPrefix
-	ContentWeight     float32   `json:"ContentWeight"` // sum of the content weights programmatically updated
-	CreatorID         string    `json:"CreatorID"`     // programmatically updated
-	Destination       string    `json:"Destination"`
-	FlagReason        string    `json:"FlagReason"`
-	ID                string    `json:"ID"`
-	IsFlagged         bool      `json:"IsFlagged"`
-	Notes             string    `json:"Notes"`
-	Origin            string    `json:"Origin"`
-	Owner             string    `json:"Owner"`
-	PreviousOwner     string    `json:"PreviousOwner"`
-	Quantity          int       `json:"Quantity"`         // programmatically updated
-	TotalWeight       float32   `json:"TotalWeight"`      // inputted by the user
-	UpdatedAt         time.Time `json:"UpdatedAt"`        // programmatically updated
-	WeightDifference  float32   `json:"WeightDifference"` // percentage difference between the total and content weight programmatically updated
+	AllAssetsApproved bool              `json:"AllAssetsApproved"` // programmatically updated
+	AssemblyDate      time.Time         `json:"AssemblyDate"`
+	AssetIDPrefix     string            `json:"AssetIDPrefix"`
+	ConsumedWeight    float32           `json:"ConsumedWeight"`         // sum of the weights drawn by Consumptions programmatically updated
+	ConsumptionState  string            `json:"ConsumptionState"`       // "available" until the whole TotalWeight is drawn, then "consumed" programmatically updated
+	Consumptions      []*LotConsumption `json:"Consumptions,omitempty"` // draws of the assets made from the lot programmatically updated
+	Content           []string          `json:"Content"`                // IDs of asset with AssetIDPrefix
+	ContentWeight     float32           `json:"ContentWeight"`          // sum of the content weights programmatically updated
+	CreatorID         string            `json:"CreatorID"`              // programmatically updated
+	Custodian         string            `json:"Custodian"`              // MSP ID of the organization physically holding the lot, i.e., its sender while it is in transit programmatically updated
+	Destination       string            `json:"Destination"`            // must match the registered site of the organization the lot is dispatched to
+	DispatchedAt      time.Time         `json:"DispatchedAt"`           // set by DispatchLot
+	DocType           string            `json:"DocType"`                // ID prefix of the asset programmatically updated
+	FlagReason        string            `json:"FlagReason"`
+	ID                string            `json:"ID"`
+	IsFlagged         bool              `json:"IsFlagged"`
+	Location          string            `json:"Location"` // site where the lot was last received, its Origin until then programmatically updated
+	Notes             string            `json:"Notes"`
+	Origin            string            `json:"Origin"`
+	Owner             string            `json:"Owner"`
+	PredecessorIDs    []string          `json:"PredecessorIDs,omitempty"` // IDs of the lots this lot was split or merged from
+	PreviousOwner     string            `json:"PreviousOwner"`
+	Quantity          int               `json:"Quantity"`               // programmatically updated
+	ReceivedAt        time.Time         `json:"ReceivedAt"`             // set by ReceiveLot
+	ReceiverID        string            `json:"ReceiverID"`             // MSP ID of the organization the lot was last dispatched to
+	SchemaVersion     int               `json:"SchemaVersion"`          // version of the schema the asset was written under programmatically updated
+	SuccessorIDs      []string          `json:"SuccessorIDs,omitempty"` // IDs of the lots this lot was split or merged into, set when the lot is retired
+	TotalWeight       float32           `json:"TotalWeight"`            // inputted by the user
+	TransitState      string            `json:"TransitState"`           // "on_site", or "in_transit" from DispatchLot until ReceiveLot programmatically updated
+	UpdatedAt         time.Time         `json:"UpdatedAt"`              // programmatically updated
+	WeightDifference  float32           `json:"WeightDifference"`       // percentage difference between the total and content weight programmatically updated
+	WeightUnit        string            `json:"WeightUnit"`             // unit of every weight of the asset, "lb" programmatically updated
+}
+
+// LotConsumption is the weight of a lot drawn by an asset made from it, e.g., a cotton yarn spun from a cotton bale lot
+type LotConsumption struct {
+	AssetID       string  `json:"AssetID"`
+	IsDefaultDraw bool    `json:"IsDefaultDraw"` // the asset drew its own weight because the weight actually drawn was not reported
+	Weight        float32 `json:"Weight"`
+}
+
+// LotPortion is one of the lots a lot is split into by SplitLot
+type LotPortion struct {
+	Content     []string `json:"Content"` // IDs of assets of the split lot
+	ID          string   `json:"ID"`
+	TotalWeight float32  `json:"TotalWeight"`
 }
 
 // Asset: CottonYarn
@@ -46,14 +77,17 @@ type CottonYarn struct {
 	Content          []string  `json:"Content"`       // IDs of CottonBale Lots
 	ContentWeight    float32   `json:"ContentWeight"` // sum of the content weights programmatically updated
 	CreatorID        string    `json:"CreatorID"`     // programmatically updated
+	DocType          string    `json:"DocType"`       // ID prefix of the asset programmatically updated
 	FlagReason       string    `json:"FlagReason"`
 	ID               string    `json:"ID"`
 	IsFlagged        bool      `json:"IsFlagged"`
 	Notes            string    `json:"Notes"`
 	Origin           string    `json:"Origin"`
+	SchemaVersion    int       `json:"SchemaVersion"`    // version of the schema the asset was written under programmatically updated
 	TotalWeight      float32   `json:"TotalWeight"`      // inputted by the user
 	UpdatedAt        time.Time `json:"UpdatedAt"`        // programmatically updated
 	WeightDifference float32   `json:"WeightDifference"` // percentage difference between the total and content weight programmatically updated
+	WeightUnit       string    `json:"WeightUnit"`       // unit of every weight of the asset, "lb" programmatically updated
 	YarnCount        int       `json:"YarnCount"`
 }
 
@@ -64,16 +98,21 @@ type UnfinishedFabric struct {
 	Content          []string  `json:"Content"`       // IDs of CottonYarn Lots
 	ContentWeight    float32   `json:"ContentWeight"` // sum of the content weights programmatically updated
 	CreatorID        string    `json:"CreatorID"`     // programmatically updated
+	DocType          string    `json:"DocType"`       // ID prefix of the asset programmatically updated
 	FlagReason       string    `json:"FlagReason"`
 	ID               string    `json:"ID"`
 	IsFlagged        bool      `json:"IsFlagged"`
 	Length           float32   `json:"Length"`
+	LengthUnit       string    `json:"LengthUnit"` // unit of Length, "yd" programmatically updated
 	Notes            string    `json:"Notes"`
 	Origin           string    `json:"Origin"`
+	SchemaVersion    int       `json:"SchemaVersion"`    // version of the schema the asset was written under programmatically updated
 	TotalWeight      float32   `json:"TotalWeight"`      // inputted by the user
 	UpdatedAt        time.Time `json:"UpdatedAt"`        // programmatically updated
 	WeightDifference float32   `json:"WeightDifference"` // percentage difference between the total and content weight programmatically updated
+	WeightUnit       string    `json:"WeightUnit"`       // unit of every weight of the asset, "lb" programmatically updated
 	Width            float32   `json:"Width"`
+	WidthUnit        string    `json:"WidthUnit"` // unit of Width, "in" programmatically updated
 }
 
 // Asset: FinishedFabric
@@ -83,16 +122,21 @@ type FinishedFabric struct {
 	Content          []string  `json:"Content"`       // IDs of UnfinishedFabric Lots
 	ContentWeight    float32   `json:"ContentWeight"` // sum of the content weights programmatically updated
 	CreatorID        string    `json:"CreatorID"`     // programmatically updated
+	DocType          string    `json:"DocType"`       // ID prefix of the asset programmatically updated
 	FlagReason       string    `json:"FlagReason"`
 	ID               string    `json:"ID"`
 	IsFlagged        bool      `json:"IsFlagged"`
 	Length           float32   `json:"Length"`
+	LengthUnit       string    `json:"LengthUnit"` // unit of Length, "yd" programmatically updated
 	Notes            string    `json:"Notes"`
 	Origin           string    `json:"Origin"`
+	SchemaVersion    int       `json:"SchemaVersion"`    // version of the schema the asset was written under programmatically updated
 	TotalWeight      float32   `json:"TotalWeight"`      // inputted by the user
 	UpdatedAt        time.Time `json:"UpdatedAt"`        // programmatically updated
 	WeightDifference float32   `json:"WeightDifference"` // percentage difference between the total and content weight programmatically updated
+	WeightUnit       string    `json:"WeightUnit"`       // unit of every weight of the asset, "lb" programmatically updated
 	Width            float32   `json:"Width"`
+	WidthUnit        string    `json:"WidthUnit"` // unit of Width, "in" programmatically updated
 }
 
 // Asset: CutPart
@@ -102,29 +146,35 @@ type CutPart struct {
 	Content          []string  `json:"Content"`       // IDs of FinishedFabric Lots
 	ContentWeight    float32   `json:"ContentWeight"` // sum of the content weights programmatically updated
 	CreatorID        string    `json:"CreatorID"`     // programmatically updated
+	DocType          string    `json:"DocType"`       // ID prefix of the asset programmatically updated
 	FlagReason       string    `json:"FlagReason"`
 	ID               string    `json:"ID"`
 	IsFlagged        bool      `json:"IsFlagged"`
 	Notes            string    `json:"Notes"`
 	Origin           string    `json:"Origin"`
 	PatternPiece     string    `json:"PatternPiece"`
+	SchemaVersion    int       `json:"SchemaVersion"`    // version of the schema the asset was written under programmatically updated
 	TotalWeight      float32   `json:"TotalWeight"`      // inputted by the user
 	UpdatedAt        time.Time `json:"UpdatedAt"`        // programmatically updated
 	WeightDifference float32   `json:"WeightDifference"` // percentage difference between the total and content weight programmatically updated
+	WeightUnit       string    `json:"WeightUnit"`       // unit of every weight of the asset, "lb" programmatically updated
 }
 
 // Asset: Button
 type Button struct {
-	Approval     bool      `json:"Approval"`
-	AssemblyDate time.Time `json:"AssemblyDate"`
-	CreatorID    string    `json:"CreatorID"` // programmatically updated
-	FlagReason   string    `json:"FlagReason"`
-	ID           string    `json:"ID"`
-	IsFlagged    bool      `json:"IsFlagged"`
-	Notes        string    `json:"Notes"`
-	Origin       string    `json:"Origin"`
-	TotalWeight  float32   `json:"TotalWeight"`
-	UpdatedAt    time.Time `json:"UpdatedAt"` // programmatically updated
+	Approval      bool      `json:"Approval"`
+	AssemblyDate  time.Time `json:"AssemblyDate"`
+	CreatorID     string    `json:"CreatorID"` // programmatically updated
+	DocType       string    `json:"DocType"`   // ID prefix of the asset programmatically updated
+	FlagReason    string    `json:"FlagReason"`
+	ID            string    `json:"ID"`
+	IsFlagged     bool      `json:"IsFlagged"`
+	Notes         string    `json:"Notes"`
+	Origin        string    `json:"Origin"`
+	SchemaVersion int       `json:"SchemaVersion"` // version of the schema the asset was written under programmatically updated
+	TotalWeight   float32   `json:"TotalWeight"`
+	UpdatedAt     time.Time `json:"UpdatedAt"`  // programmatically updated
+	WeightUnit    string    `json:"WeightUnit"` // unit of every weight of the asset, "lb" programmatically updated
 }
 
 // Asset: AssembledGarment
@@ -135,14 +185,17 @@ type AssembledGarment struct {
 	ContentWeight    float32   `json:"ContentWeight"` // sum of the content weights programmatically updated
 	CreatorID        string    `json:"CreatorID"`     // programmatically updated
 	CutParts         []string  `json:"CutParts"`      // IDs of CutParts
+	DocType          string    `json:"DocType"`       // ID prefix of the asset programmatically updated
 	FlagReason       string    `json:"FlagReason"`
 	ID               string    `json:"ID"`
 	IsFlagged        bool      `json:"IsFlagged"`
 	Notes            string    `json:"Notes"`
 	Origin           string    `json:"Origin"`
+	SchemaVersion    int       `json:"SchemaVersion"`    // version of the schema the asset was written under programmatically updated
 	TotalWeight      float32   `json:"TotalWeight"`      // inputted by the user
 	UpdatedAt        time.Time `json:"UpdatedAt"`        // programmatically updated
 	WeightDifference float32   `json:"WeightDifference"` // percentage difference between the total and content weight programmatically updated
+	WeightUnit       string    `json:"WeightUnit"`       // unit of every weight of the asset, "lb" programmatically updated
 }
 
 // Asset: Carton
@@ -153,53 +206,90 @@ type Carton struct {
 	ContentWeight     float32   `json:"ContentWeight"` // sum of the content weights programmatically updated
 	CreatorID         string    `json:"CreatorID"`     // programmatically updated
 	CustomerID        string    `json:"CustomerID"`
+	DocType           string    `json:"DocType"` // ID prefix of the asset programmatically updated
 	FlagReason        string    `json:"FlagReason"`
 	ID                string    `json:"ID"`
 	IsFlagged         bool      `json:"IsFlagged"`
 	Notes             string    `json:"Notes"`
+	OrderID           string    `json:"OrderID"` // ID of the order on the admin channel the carton fulfils
 	Origin            string    `json:"Origin"`
 	Owner             string    `json:"Owner"`
 	PreviousOwner     string    `json:"PreviousOwner"`
 	Quantity          int       `json:"Quantity"`
+	SchemaVersion     int       `json:"SchemaVersion"`    // version of the schema the asset was written under programmatically updated
 	TotalWeight       float32   `json:"TotalWeight"`      // inputted by the user
 	UpdatedAt         time.Time `json:"UpdatedAt"`        // programmatically updated
 	WeightDifference  float32   `json:"WeightDifference"` // percentage difference between the total and content weight programmatically updated
+	WeightUnit        string    `json:"WeightUnit"`       // unit of every weight of the asset, "lb" programmatically updated
 }
 
 // Asset: Container
 type Container struct {
+	ArrivedAt        time.Time `json:"ArrivedAt"` // set by ArriveContainer
 	AssetType        string    `json:"AssetType"`
 	Content          []string  `json:"Content"`       // IDs of Cartons
 	ContentWeight    float32   `json:"ContentWeight"` // sum of the content weights programmatically updated
 	CreatorID        string    `json:"CreatorID"`     // programmatically updated
+	DeliveredAt      time.Time `json:"DeliveredAt"`   // set by DeliverContainer
+	DepartedAt       time.Time `json:"DepartedAt"`    // set by DepartContainer
 	DestinationPort  string    `json:"DestinationPort"`
+	DocType          string    `json:"DocType"` // ID prefix of the asset programmatically updated
 	FlagReason       string    `json:"FlagReason"`
 	ID               string    `json:"ID"`
 	IsFlagged        bool      `json:"IsFlagged"`
 	LoadedAt         time.Time `json:"LoadedAt"`
 	OriginPort       string    `json:"OriginPort"`
-	TotalWeight      float32   `json:"TotalWeight"` // inputted by the user
-	UpdatedAt        time.Time `json:"UpdatedAt"`   // programmatically updated
+	Owner            string    `json:"Owner"` // programmatically updated
+	PreviousOwner    string    `json:"PreviousOwner"`
+	SchemaVersion    int       `json:"SchemaVersion"` // version of the schema the asset was written under programmatically updated
+	Status           string    `json:"Status"`        // "loaded", "departed", "arrived" or "delivered" programmatically updated
+	TotalWeight      float32   `json:"TotalWeight"`   // inputted by the user
+	UpdatedAt        time.Time `json:"UpdatedAt"`     // programmatically updated
 	Vessel           string    `json:"Vessel"`
 	WeightDifference float32   `json:"WeightDifference"` // percentage difference between the total and content weight programmatically updated
+	WeightUnit       string    `json:"WeightUnit"`       // unit of every weight of the asset, "lb" programmatically updated
 }
 
 // Asset: BillOfLading
 type BillOfLading struct {
-	Consignee     string    `json:"Consignee"`
-	DeliveryPlace string    `json:"DeliveryPlace"`
-	DischargePort string    `json:"DischargePort"`
-	DocumentID    string    `json:"DocumentID"`
-	FlagReason    string    `json:"FlagReason"`
-	FreightTerms  string    `json:"FreightTerms"`
-	GrossWeight   float32   `json:"GrossWeight"`
-	ID            string    `json:"ID"`
-	IsFlagged     bool      `json:"IsFlagged"`
-	IssueDate     time.Time `json:"IssueDate"`
-	LoadingPort   string    `json:"LoadingPort"`
-	ReceiptPlace  string    `json:"ReceiptPlace"`
-	SealNumber    uint8     `json:"SealNumber"`
-	Shipper       string    `json:"Shipper"`
-	URL           string    `json:"URL"`
-	Vessel        string    `json:"Vessel"`
+	Consignee        string    `json:"Consignee"`
+	Containers       []string  `json:"Containers"`       // IDs of Containers
+	ContainersWeight float32   `json:"ContainersWeight"` // sum of the containers' total weights programmatically updated
+	CreatorID        string    `json:"CreatorID"`        // programmatically updated
+	DeliveryPlace    string    `json:"DeliveryPlace"`
+	DischargePort    string    `json:"DischargePort"`
+	DocType          string    `json:"DocType"` // ID prefix of the asset programmatically updated
+	DocumentID       string    `json:"DocumentID"`
+	FlagReason       string    `json:"FlagReason"`
+	FreightTerms     string    `json:"FreightTerms"`
+	GrossWeight      float32   `json:"GrossWeight"` // inputted by the user
+	ID               string    `json:"ID"`
+	IsFlagged        bool      `json:"IsFlagged"`
+	IssueDate        time.Time `json:"IssueDate"`
+	LoadingPort      string    `json:"LoadingPort"`
+	ReceiptPlace     string    `json:"ReceiptPlace"`
+	SchemaVersion    int       `json:"SchemaVersion"` // version of the schema the asset was written under programmatically updated
+	SealNumber       uint8     `json:"SealNumber"`
+	Shipper          string    `json:"Shipper"`
+	URL              string    `json:"URL"`
+	UpdatedAt        time.Time `json:"UpdatedAt"` // programmatically updated
+	Vessel           string    `json:"Vessel"`
+	WeightDifference float32   `json:"WeightDifference"` // percentage difference between the gross weight and the containers' weight programmatically updated
+	WeightUnit       string    `json:"WeightUnit"`       // unit of every weight of the asset, "lb" programmatically updated
+}
+
+// Asset: Recall
+type Recall struct {
+	AffectedAssets []string  `json:"AffectedAssets"` // IDs of the root and every asset derived from it programmatically updated
+	ClosedAt       time.Time `json:"ClosedAt"`
+	CreatorID      string    `json:"CreatorID"` // programmatically updated
+	DocType        string    `json:"DocType"`   // ID prefix of the asset programmatically updated
+	ID             string    `json:"ID"`
+	InitiatedAt    time.Time `json:"InitiatedAt"` // programmatically updated
+	Reason         string    `json:"Reason"`
+	Resolution     string    `json:"Resolution"`
+	RootID         string    `json:"RootID"`
+	SchemaVersion  int       `json:"SchemaVersion"` // version of the schema the asset was written under programmatically updated
+	Status         string    `json:"Status"`        // "open" or "closed"
+	UpdatedAt      time.Time `json:"UpdatedAt"`     // programmatically updated
 }
@@ -3,10 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"strings"
-	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/history"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
 )
 
 // AssetExists returns true when asset with given ID exists in world state
@@ -42,15 +44,24 @@ func (s *SmartContract) SetFlag(ctx contractapi.TransactionContextInterface, id
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
 	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	// Update the updatedAt field
-	asset["UpdatedAt"] = time.Now()
+	asset["UpdatedAt"] = txTimestamp
 	// Marshal the updated asset back to JSON
 	updatedAssetJSON, err := json.Marshal(asset)
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %v", err)
 	}
 	// Save the updated asset to the world state
-	return ctx.GetStub().PutState(id, updatedAssetJSON)
+	if err := ctx.GetStub().PutState(id, updatedAssetJSON); err != nil {
+		return err
+	}
+	// Emit the AssetFlagged event
+	return events.Emit(ctx, events.AssetFlagged, events.AssetFlaggedData{AssetID: id, FlagReason: flagReason, IsFlagged: isFlagged})
 }
 
 // SetNotes sets the notes field of an asset with a specific ID
@@ -71,15 +82,24 @@ func (s *SmartContract) SetNotes(ctx contractapi.TransactionContextInterface, id
 	}
 	// Update the notes field
 	asset["Notes"] = notes
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
 	// Update the updatedAt field
-	asset["UpdatedAt"] = time.Now()
+	asset["UpdatedAt"] = txTimestamp
 	// Marshal the updated asset back to JSON
 	updatedAssetJSON, err := json.Marshal(asset)
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %v", err)
 	}
 	// Save the updated asset to the world state
-	return ctx.GetStub().PutState(id, updatedAssetJSON)
+	if err := ctx.GetStub().PutState(id, updatedAssetJSON); err != nil {
+		return err
+	}
+	// Emit the AssetNotesUpdated event
+	return events.Emit(ctx, events.AssetNotesUpdated, events.AssetNotesUpdatedData{AssetID: id})
 }
 
 // GetAsset retrieves an asset with a specific ID from the world state
@@ -101,8 +121,28 @@ func (s *SmartContract) GetAsset(ctx contractapi.TransactionContextInterface, id
 	return asset, nil
 }
 
-// GetAllAssets retrieves all records from the world state
+// GetAssetHistory retrieves every committed version of an asset with a specific ID from the ledger's history, newest first
+func (s *SmartContract) GetAssetHistory(ctx contractapi.TransactionContextInterface, id string) ([]*history.AssetVersion, error) {
+	return history.GetAssetHistory(ctx, id)
+}
+
+// GetAllAssets retrieves all records from the world state, leaving out archived assets
 func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface) ([]map[string]interface{}, error) {
+	return getAllAssets(ctx, false)
+}
+
+// GetAllAssetsIncludingArchived retrieves all records from the world state, including archived assets
+func (s *SmartContract) GetAllAssetsIncludingArchived(ctx contractapi.TransactionContextInterface) ([]map[string]interface{}, error) {
+	return getAllAssets(ctx, true)
+}
+
+// getAllAssets retrieves all records from the world state, leaving out archived assets unless includeArchived is true
+func getAllAssets(ctx contractapi.TransactionContextInterface, includeArchived bool) ([]map[string]interface{}, error) {
+	// Retrieve the IDs of the archived assets
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
 	// Define a composite key prefix that includes the document type
 	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
 	if err != nil {
@@ -115,6 +155,10 @@ func (s *SmartContract) GetAllAssets(ctx contractapi.TransactionContextInterface
 		if err != nil {
 			return nil, err
 		}
+		// Leave out archived assets unless they are requested
+		if !includeArchived && archivedIDs[queryResponse.Key] {
+			continue
+		}
 		var record map[string]interface{}
 		err = json.Unmarshal(queryResponse.Value, &record)
 		if err != nil {
@@ -135,8 +179,23 @@ func GetPercentageDifference(original, new float32) float32 {
 	return percentageDifference
 }
 
-// GetAllAssetsCount retrieves the amount of assets in the world state
+// GetAllAssetsCount retrieves the amount of assets in the world state, leaving out archived assets
 func (s *SmartContract) GetAllAssetsCount(ctx contractapi.TransactionContextInterface) (int, error) {
+	return getAllAssetsCount(ctx, false)
+}
+
+// GetAllAssetsCountIncludingArchived retrieves the amount of assets in the world state, including archived assets
+func (s *SmartContract) GetAllAssetsCountIncludingArchived(ctx contractapi.TransactionContextInterface) (int, error) {
+	return getAllAssetsCount(ctx, true)
+}
+
+// getAllAssetsCount retrieves the amount of assets in the world state, leaving out archived assets unless includeArchived is true
+func getAllAssetsCount(ctx contractapi.TransactionContextInterface, includeArchived bool) (int, error) {
+	// Retrieve the IDs of the archived assets
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
 	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
 	if err != nil {
 		return 0, err
@@ -146,23 +205,42 @@ func (s *SmartContract) GetAllAssetsCount(ctx contractapi.TransactionContextInte
 	count := 0
 
 	for resultsIterator.HasNext() {
-		_, err := resultsIterator.Next()
+		queryResponse, err := resultsIterator.Next()
 		if err != nil {
 			return 0, err
 		}
+		// Leave out archived assets unless they are requested
+		if !includeArchived && archivedIDs[queryResponse.Key] {
+			continue
+		}
 		count++
 	}
 
 	return count, nil
 }
 
-// GetAllAssetsOfType is a helper function not meant for direct client invocation as it retrieves all records from the world state that contain the specified substring in their keys but returns the records as an interface
+// GetAllAssetsOfType is a helper function not meant for direct client invocation as it retrieves all records of the asset type with the specified ID prefix, reading only the partition of the type index that holds them and leaving out archived assets, but returns the records as an interface
 func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInterface, assetIDPrefix string) (interface{}, error) {
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	return getAllAssetsOfType(ctx, assetIDPrefix, false)
+}
+
+// GetAllAssetsOfTypeIncludingArchived is a helper function not meant for direct client invocation as it retrieves all records of the asset type with the specified ID prefix, including archived assets, but returns the records as an interface
+func (s *SmartContract) GetAllAssetsOfTypeIncludingArchived(ctx contractapi.TransactionContextInterface, assetIDPrefix string) (interface{}, error) {
+	return getAllAssetsOfType(ctx, assetIDPrefix, true)
+}
+
+// getAllAssetsOfType retrieves all records of the asset type with the specified ID prefix from the type index, leaving out archived assets unless includeArchived is true
+func getAllAssetsOfType(ctx contractapi.TransactionContextInterface, assetIDPrefix string, includeArchived bool) (interface{}, error) {
+	// Retrieve the IDs of the archived assets
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Retrieve the IDs of the assets of the type
+	ids, err := index.IDsOfType(ctx, assetIDPrefix)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
 
 	var cottonBales []*CottonBale
 	var lots []*Lot
@@ -174,23 +252,23 @@ func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInt
 	var assembledGarments []*AssembledGarment
 	var cartons []*Carton
 	var containers []*Container
+	var billsOfLading []*BillOfLading
 
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-
-		// Check if the key contains the specified substring
-		if !strings.Contains(queryResponse.Key, assetIDPrefix) {
+	for _, id := range ids {
+		// Leave out archived assets unless they are requested
+		if !includeArchived && archivedIDs[id] {
 			continue
 		}
+		assetJSON, err := ctx.GetStub().GetState(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset %s: %v", id, err)
+		}
 
 		switch assetIDPrefix {
 
-		case "cottonBale_":
+		case "cottonbale_":
 			var cottonBale CottonBale
-			err = json.Unmarshal(queryResponse.Value, &cottonBale)
+			err = json.Unmarshal(assetJSON, &cottonBale)
 			if err != nil {
 				return nil, err
 			}
@@ -198,39 +276,39 @@ func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInt
 
 		case "lot_":
 			var lot Lot
-			err = json.Unmarshal(queryResponse.Value, &lot)
+			err = json.Unmarshal(assetJSON, &lot)
 			if err != nil {
 				return nil, err
 			}
 			lots = append(lots, &lot)
 
-		case "cottonYarn_":
+		case "cottonyarn_":
 			var cottonYarn CottonYarn
-			err = json.Unmarshal(queryResponse.Value, &cottonYarn)
+			err = json.Unmarshal(assetJSON, &cottonYarn)
 			if err != nil {
 				return nil, err
 			}
 			cottonYarns = append(cottonYarns, &cottonYarn)
 
-		case "unfinishedFabric_":
+		case "unfinishedfabric_":
 			var unfinishedFabric UnfinishedFabric
-			err = json.Unmarshal(queryResponse.Value, &unfinishedFabric)
+			err = json.Unmarshal(assetJSON, &unfinishedFabric)
 			if err != nil {
 				return nil, err
 			}
 			unfinishedFabrics = append(unfinishedFabrics, &unfinishedFabric)
 
-		case "finishedFabric_":
+		case "finishedfabric_":
 			var finishedFabric FinishedFabric
-			err = json.Unmarshal(queryResponse.Value, &finishedFabric)
+			err = json.Unmarshal(assetJSON, &finishedFabric)
 			if err != nil {
 				return nil, err
 			}
 			finishedFabrics = append(finishedFabrics, &finishedFabric)
 
-		case "cutPart_":
+		case "cutpart_":
 			var cutPart CutPart
-			err = json.Unmarshal(queryResponse.Value, &cutPart)
+			err = json.Unmarshal(assetJSON, &cutPart)
 			if err != nil {
 				return nil, err
 			}
@@ -238,15 +316,15 @@ func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInt
 
 		case "button_":
 			var button Button
-			err = json.Unmarshal(queryResponse.Value, &button)
+			err = json.Unmarshal(assetJSON, &button)
 			if err != nil {
 				return nil, err
 			}
 			buttons = append(buttons, &button)
 
-		case "assembledGarment_":
+		case "assembledgarment_":
 			var assembledGarment AssembledGarment
-			err = json.Unmarshal(queryResponse.Value, &assembledGarment)
+			err = json.Unmarshal(assetJSON, &assembledGarment)
 			if err != nil {
 				return nil, err
 			}
@@ -254,7 +332,7 @@ func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInt
 
 		case "carton_":
 			var carton Carton
-			err = json.Unmarshal(queryResponse.Value, &carton)
+			err = json.Unmarshal(assetJSON, &carton)
 			if err != nil {
 				return nil, err
 			}
@@ -262,12 +340,20 @@ func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInt
 
 		case "container_":
 			var container Container
-			err = json.Unmarshal(queryResponse.Value, &container)
+			err = json.Unmarshal(assetJSON, &container)
 			if err != nil {
 				return nil, err
 			}
 			containers = append(containers, &container)
 
+		case "billoflading_":
+			var billOfLading BillOfLading
+			err = json.Unmarshal(assetJSON, &billOfLading)
+			if err != nil {
+				return nil, err
+			}
+			billsOfLading = append(billsOfLading, &billOfLading)
+
 		// Add additional cases for other record types
 		default:
 			return nil, fmt.Errorf("invalid record type: %s", assetIDPrefix)
@@ -295,128 +381,250 @@ func (s *SmartContract) GetAllAssetsOfType(ctx contractapi.TransactionContextInt
 		return cartons, nil
 	case "container_":
 		return containers, nil
+	case "billoflading_":
+		return billsOfLading, nil
 	default:
 		return nil, fmt.Errorf("invalid record type: %s", assetIDPrefix)
 	}
 }
 
-// GetAllCottonBales retrieves all cotton bales from the world state
+// GetAllCottonBales retrieves all cotton bales from the world state, leaving out archived ones
 func (s *SmartContract) GetAllCottonBales(ctx contractapi.TransactionContextInterface) ([]*CottonBale, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "cottonbale_")
+	records, err := getAllAssetsOfType(ctx, "cottonbale_", false)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*CottonBale), nil
+}
+
+// GetAllCottonBalesIncludingArchived retrieves all cotton bales from the world state, including archived ones
+func (s *SmartContract) GetAllCottonBalesIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*CottonBale, error) {
+	records, err := getAllAssetsOfType(ctx, "cottonbale_", true)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*CottonBale), nil
 }
 
-// GetAllLots retrieves all lots from the world state
+// GetAllLots retrieves all lots from the world state, leaving out archived ones
 func (s *SmartContract) GetAllLots(ctx contractapi.TransactionContextInterface) ([]*Lot, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "lot_")
+	records, err := getAllAssetsOfType(ctx, "lot_", false)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*Lot), nil
 }
 
-// GetAllCottonYarns retrieves all cotton yarns from the world state
+// GetAllLotsIncludingArchived retrieves all lots from the world state, including archived ones
+func (s *SmartContract) GetAllLotsIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*Lot, error) {
+	records, err := getAllAssetsOfType(ctx, "lot_", true)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*Lot), nil
+}
+
+// GetAllCottonYarns retrieves all cotton yarns from the world state, leaving out archived ones
 func (s *SmartContract) GetAllCottonYarns(ctx contractapi.TransactionContextInterface) ([]*CottonYarn, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "cottonyarn_")
+	records, err := getAllAssetsOfType(ctx, "cottonyarn_", false)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*CottonYarn), nil
+}
+
+// GetAllCottonYarnsIncludingArchived retrieves all cotton yarns from the world state, including archived ones
+func (s *SmartContract) GetAllCottonYarnsIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*CottonYarn, error) {
+	records, err := getAllAssetsOfType(ctx, "cottonyarn_", true)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*CottonYarn), nil
 }
 
-// GetAllUnfinishedFabrics retrieves all unfinished fabrics from the world state
+// GetAllUnfinishedFabrics retrieves all unfinished fabrics from the world state, leaving out archived ones
 func (s *SmartContract) GetAllUnfinishedFabrics(ctx contractapi.TransactionContextInterface) ([]*UnfinishedFabric, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "unfinishedfabric_")
+	records, err := getAllAssetsOfType(ctx, "unfinishedfabric_", false)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*UnfinishedFabric), nil
 }
 
-// GetAllFinishedFabrics retrieves all finished fabrics from the world state
+// GetAllUnfinishedFabricsIncludingArchived retrieves all unfinished fabrics from the world state, including archived ones
+func (s *SmartContract) GetAllUnfinishedFabricsIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*UnfinishedFabric, error) {
+	records, err := getAllAssetsOfType(ctx, "unfinishedfabric_", true)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*UnfinishedFabric), nil
+}
+
+// GetAllFinishedFabrics retrieves all finished fabrics from the world state, leaving out archived ones
 func (s *SmartContract) GetAllFinishedFabrics(ctx contractapi.TransactionContextInterface) ([]*FinishedFabric, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "finishedfabric_")
+	records, err := getAllAssetsOfType(ctx, "finishedfabric_", false)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*FinishedFabric), nil
+}
+
+// GetAllFinishedFabricsIncludingArchived retrieves all finished fabrics from the world state, including archived ones
+func (s *SmartContract) GetAllFinishedFabricsIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*FinishedFabric, error) {
+	records, err := getAllAssetsOfType(ctx, "finishedfabric_", true)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*FinishedFabric), nil
 }
 
-// GetAllCutParts retrieves all cut parts from the world state
+// GetAllCutParts retrieves all cut parts from the world state, leaving out archived ones
 func (s *SmartContract) GetAllCutParts(ctx contractapi.TransactionContextInterface) ([]*CutPart, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "cutpart_")
+	records, err := getAllAssetsOfType(ctx, "cutpart_", false)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*CutPart), nil
+}
+
+// GetAllCutPartsIncludingArchived retrieves all cut parts from the world state, including archived ones
+func (s *SmartContract) GetAllCutPartsIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*CutPart, error) {
+	records, err := getAllAssetsOfType(ctx, "cutpart_", true)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*CutPart), nil
 }
 
-// GetAllButtons retrieves all buttons from the world state
+// GetAllButtons retrieves all buttons from the world state, leaving out archived ones
 func (s *SmartContract) GetAllButtons(ctx contractapi.TransactionContextInterface) ([]*Button, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "button_")
+	records, err := getAllAssetsOfType(ctx, "button_", false)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*Button), nil
+}
+
+// GetAllButtonsIncludingArchived retrieves all buttons from the world state, including archived ones
+func (s *SmartContract) GetAllButtonsIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*Button, error) {
+	records, err := getAllAssetsOfType(ctx, "button_", true)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*Button), nil
 }
 
-// GetAllAssembledGarments retrieves all assembled garments from the world state
+// GetAllAssembledGarments retrieves all assembled garments from the world state, leaving out archived ones
 func (s *SmartContract) GetAllAssembledGarments(ctx contractapi.TransactionContextInterface) ([]*AssembledGarment, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "assembledgarment_")
+	records, err := getAllAssetsOfType(ctx, "assembledgarment_", false)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*AssembledGarment), nil
+}
+
+// GetAllAssembledGarmentsIncludingArchived retrieves all assembled garments from the world state, including archived ones
+func (s *SmartContract) GetAllAssembledGarmentsIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*AssembledGarment, error) {
+	records, err := getAllAssetsOfType(ctx, "assembledgarment_", true)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*AssembledGarment), nil
 }
 
-// GetAllCartons retrieves all cartons from the world state
+// GetAllCartons retrieves all cartons from the world state, leaving out archived ones
 func (s *SmartContract) GetAllCartons(ctx contractapi.TransactionContextInterface) ([]*Carton, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "carton_")
+	records, err := getAllAssetsOfType(ctx, "carton_", false)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*Carton), nil
+}
+
+// GetAllCartonsIncludingArchived retrieves all cartons from the world state, including archived ones
+func (s *SmartContract) GetAllCartonsIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*Carton, error) {
+	records, err := getAllAssetsOfType(ctx, "carton_", true)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*Carton), nil
 }
 
-// GetAllContainers retrieves all containers from the world state
+// GetAllContainers retrieves all containers from the world state, leaving out archived ones
 func (s *SmartContract) GetAllContainers(ctx contractapi.TransactionContextInterface) ([]*Container, error) {
-	records, err := s.GetAllAssetsOfType(ctx, "container_")
+	records, err := getAllAssetsOfType(ctx, "container_", false)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*Container), nil
+}
+
+// GetAllContainersIncludingArchived retrieves all containers from the world state, including archived ones
+func (s *SmartContract) GetAllContainersIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*Container, error) {
+	records, err := getAllAssetsOfType(ctx, "container_", true)
 	if err != nil {
 		return nil, err
 	}
 	return records.([]*Container), nil
 }
 
-// GetAllAssetsOfTypeCount retrieves the count of records from the world state that contain the specified substring in their keys
+// GetAllBillsOfLading retrieves all bills of lading from the world state, leaving out archived ones
+func (s *SmartContract) GetAllBillsOfLading(ctx contractapi.TransactionContextInterface) ([]*BillOfLading, error) {
+	records, err := getAllAssetsOfType(ctx, "billoflading_", false)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*BillOfLading), nil
+}
+
+// GetAllBillsOfLadingIncludingArchived retrieves all bills of lading from the world state, including archived ones
+func (s *SmartContract) GetAllBillsOfLadingIncludingArchived(ctx contractapi.TransactionContextInterface) ([]*BillOfLading, error) {
+	records, err := getAllAssetsOfType(ctx, "billoflading_", true)
+	if err != nil {
+		return nil, err
+	}
+	return records.([]*BillOfLading), nil
+}
+
+// GetAllAssetsOfTypeCount retrieves the count of records of the asset type with the specified ID prefix from the type index, leaving out archived assets
 func (s *SmartContract) GetAllAssetsOfTypeCount(ctx contractapi.TransactionContextInterface, assetIDPrefix string) (int, error) {
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	return getAllAssetsOfTypeCount(ctx, assetIDPrefix, false)
+}
+
+// GetAllAssetsOfTypeCountIncludingArchived retrieves the count of records of the asset type with the specified ID prefix from the type index, including archived assets
+func (s *SmartContract) GetAllAssetsOfTypeCountIncludingArchived(ctx contractapi.TransactionContextInterface, assetIDPrefix string) (int, error) {
+	return getAllAssetsOfTypeCount(ctx, assetIDPrefix, true)
+}
+
+// getAllAssetsOfTypeCount retrieves the count of records of the asset type with the specified ID prefix from the type index, leaving out archived assets unless includeArchived is true
+func getAllAssetsOfTypeCount(ctx contractapi.TransactionContextInterface, assetIDPrefix string, includeArchived bool) (int, error) {
+	// Retrieve the IDs of the archived assets
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// Retrieve the IDs of the assets of the type
+	ids, err := index.IDsOfType(ctx, assetIDPrefix)
 	if err != nil {
 		return 0, err
 	}
-	defer resultsIterator.Close()
 
 	count := 0
 
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return 0, err
-		}
-
-		// Check if the key contains the specified substring
-		if strings.Contains(queryResponse.Key, assetIDPrefix) {
-			count++
+	for _, id := range ids {
+		// Leave out archived assets unless they are requested
+		if !includeArchived && archivedIDs[id] {
+			continue
 		}
+		count++
 	}
 
 	return count, nil
 }
 
-// GetContentWeight retrieves the TotalWeight for each asset ID in content and sums them up
+// GetContentWeight retrieves the TotalWeight for each asset ID in content and sums them up in the ledger's weight unit. It refuses to sum weights in different units
 func GetContentWeight(ctx contractapi.TransactionContextInterface, content []string) (float32, error) {
+	var contentUnit string
 	var contentWeight float32
 
 	for _, assetID := range content {
@@ -442,39 +650,52 @@ func GetContentWeight(ctx contractapi.TransactionContextInterface, content []str
 			return 0, fmt.Errorf("asset %s does not have a valid TotalWeight attribute", assetID)
 		}
 
+		// Extract the unit of the TotalWeight, which assets created before units were recorded lack
+		weightUnit, _ := assetMap["WeightUnit"].(string)
+		if weightUnit == "" {
+			weightUnit = ledgerUnits.Weight
+		}
+		// Ensure that every weight of the sum is in the same unit
+		if contentUnit == "" {
+			contentUnit = weightUnit
+		} else if weightUnit != contentUnit {
+			return 0, fmt.Errorf("cannot sum the weight of asset %s in %s with weights in %s", assetID, weightUnit, contentUnit)
+		}
+
 		// Add the asset's TotalWeight to the total contentWeight
 		contentWeight += float32(totalWeight)
 	}
 
-	return contentWeight, nil
+	// An empty content weighs nothing
+	if contentUnit == "" {
+		return 0, nil
+	}
+	// Convert the sum to the ledger's weight unit
+	sum, err := Quantity{Unit: contentUnit, Value: contentWeight}.In(ledgerUnits.Weight)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert the content weight: %v", err)
+	}
+	return sum.Value, nil
 }
 
-// GetLotsWithPrefixCount returns the total number of assets with IDs prefixed by "lot_" further filtered by AssetIDPrefix
+// GetLotsWithPrefixCount returns the total number of lots further filtered by AssetIDPrefix
 func GetLotsWithPrefixCount(ctx contractapi.TransactionContextInterface, assetIDPrefix string) (int, error) {
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	// Retrieve the IDs of the lots
+	lotIDs, err := index.IDsOfType(ctx, "lot_")
 	if err != nil {
-		return 0, fmt.Errorf("failed to get state by range: %v", err)
+		return 0, err
 	}
-	defer resultsIterator.Close()
 
 	count := 0
 
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
+	for _, lotID := range lotIDs {
+		lot, err := getLot(ctx, lotID)
 		if err != nil {
-			return 0, fmt.Errorf("failed to iterate through results: %v", err)
+			return 0, err
 		}
 
-		if strings.HasPrefix(queryResponse.Key, "lot_") {
-			var lot Lot
-			err = json.Unmarshal(queryResponse.Value, &lot)
-			if err != nil {
-				return 0, fmt.Errorf("failed to unmarshal lot: %v", err)
-			}
-
-			if lot.AssetIDPrefix == assetIDPrefix {
-				count++
-			}
+		if lot.AssetIDPrefix == assetIDPrefix {
+			count++
 		}
 	}
 
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/batch"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+)
+
+// The *BatchItem types are the elements of the JSON arrays the Create*Batch functions take. Each holds the arguments of the matching Create* function, and the ID of the asset to issue
+
+// ButtonBatchItem is a button issued by CreateButtonsBatch
+type ButtonBatchItem struct {
+	Approval     bool      `json:"Approval"`
+	AssemblyDate time.Time `json:"AssemblyDate"`
+	FlagReason   string    `json:"FlagReason" metadata:",optional"`
+	ID           string    `json:"ID"`
+	IsFlagged    bool      `json:"IsFlagged"`
+	Notes        string    `json:"Notes" metadata:",optional"`
+	Origin       string    `json:"Origin"`
+	TotalWeight  float32   `json:"TotalWeight"`
+}
+
+// CutPartBatchItem is a cut part issued by CreateCutPartsBatch
+type CutPartBatchItem struct {
+	Approval     bool      `json:"Approval"`
+	AssemblyDate time.Time `json:"AssemblyDate"`
+	Content      []string  `json:"Content"`                           // IDs of finished fabric lots
+	DrawnWeights []float32 `json:"DrawnWeights" metadata:",optional"` // weight drawn from each content lot, which defaults to TotalWeight
+	FlagReason   string    `json:"FlagReason" metadata:",optional"`
+	ID           string    `json:"ID"`
+	IsFlagged    bool      `json:"IsFlagged"`
+	Notes        string    `json:"Notes" metadata:",optional"`
+	Origin       string    `json:"Origin"`
+	PatternPiece string    `json:"PatternPiece"`
+	TotalWeight  float32   `json:"TotalWeight"`
+}
+
+// AssembledGarmentBatchItem is an assembled garment issued by CreateAssembledGarmentsBatch
+type AssembledGarmentBatchItem struct {
+	Approval     bool      `json:"Approval"`
+	AssemblyDate time.Time `json:"AssemblyDate"`
+	Buttons      []string  `json:"Buttons"`  // IDs of Buttons
+	CutParts     []string  `json:"CutParts"` // IDs of CutParts
+	FlagReason   string    `json:"FlagReason" metadata:",optional"`
+	ID           string    `json:"ID"`
+	IsFlagged    bool      `json:"IsFlagged"`
+	Notes        string    `json:"Notes" metadata:",optional"`
+	Origin       string    `json:"Origin"`
+	TotalWeight  float32   `json:"TotalWeight"`
+}
+
+// CartonBatchItem is a carton issued by CreateCartonsBatch
+type CartonBatchItem struct {
+	AllAssetsApproved bool      `json:"AllAssetsApproved"`
+	AssemblyDate      time.Time `json:"AssemblyDate"`
+	Content           []string  `json:"Content"` // IDs of AssembledGarments
+	CustomerID        string    `json:"CustomerID"`
+	FlagReason        string    `json:"FlagReason" metadata:",optional"`
+	ID                string    `json:"ID"`
+	IsFlagged         bool      `json:"IsFlagged"`
+	Notes             string    `json:"Notes" metadata:",optional"`
+	OrderID           string    `json:"OrderID"`
+	Origin            string    `json:"Origin"`
+	Owner             string    `json:"Owner"`
+	TotalWeight       float32   `json:"TotalWeight"`
+}
+
+// CreateButtonsBatch issues every button of the batch in a single transaction, applying the specifications of CreateButton to each. The batch is atomic: unless every button passes, none is issued and the error reports each button that failed. Contains the following specifications: 1) SPEC_IsValidBatchSize
+func (s *SmartContract) CreateButtonsBatch(ctx contractapi.TransactionContextInterface, buttons []ButtonBatchItem) error {
+	// Ensure that the batch size is within bounds
+	if err := SPEC_IsValidBatchSize(len(buttons)); err != nil {
+		return err
+	}
+	ids := []string{}
+	for _, button := range buttons {
+		ids = append(ids, button.ID)
+	}
+	return runBatch(ctx, "button_", ids, func(itemCtx contractapi.TransactionContextInterface, i int) error {
+		button := buttons[i]
+		return s.CreateButton(itemCtx, button.Approval, button.AssemblyDate, button.FlagReason, button.ID, button.IsFlagged, button.Notes, button.Origin, button.TotalWeight)
+	})
+}
+
+// CreateCutPartsBatch issues every cut part of the batch in a single transaction, applying the specifications of CreateCutPartWithDraws to each. Cut parts drawing from the same lot draw from the weight the cut parts before them left. The batch is atomic: unless every cut part passes, none is issued and the error reports each cut part that failed. Contains the following specifications: 1) SPEC_IsValidBatchSize
+func (s *SmartContract) CreateCutPartsBatch(ctx contractapi.TransactionContextInterface, cutParts []CutPartBatchItem) error {
+	// Ensure that the batch size is within bounds
+	if err := SPEC_IsValidBatchSize(len(cutParts)); err != nil {
+		return err
+	}
+	ids := []string{}
+	for _, cutPart := range cutParts {
+		ids = append(ids, cutPart.ID)
+	}
+	return runBatch(ctx, "cutpart_", ids, func(itemCtx contractapi.TransactionContextInterface, i int) error {
+		cutPart := cutParts[i]
+		return s.CreateCutPartWithDraws(itemCtx, cutPart.Approval, cutPart.AssemblyDate, cutPart.Content, cutPart.DrawnWeights, cutPart.FlagReason, cutPart.ID, cutPart.IsFlagged, cutPart.Notes, cutPart.Origin, cutPart.PatternPiece, cutPart.TotalWeight)
+	})
+}
+
+// CreateAssembledGarmentsBatch issues every assembled garment of the batch in a single transaction, applying the specifications of CreateAssembledGarment to each. The batch is atomic: unless every assembled garment passes, none is issued and the error reports each assembled garment that failed. Contains the following specifications: 1) SPEC_IsValidBatchSize
+func (s *SmartContract) CreateAssembledGarmentsBatch(ctx contractapi.TransactionContextInterface, assembledGarments []AssembledGarmentBatchItem) error {
+	// Ensure that the batch size is within bounds
+	if err := SPEC_IsValidBatchSize(len(assembledGarments)); err != nil {
+		return err
+	}
+	ids := []string{}
+	for _, assembledGarment := range assembledGarments {
+		ids = append(ids, assembledGarment.ID)
+	}
+	return runBatch(ctx, "assembledgarment_", ids, func(itemCtx contractapi.TransactionContextInterface, i int) error {
+		assembledGarment := assembledGarments[i]
+		return s.CreateAssembledGarment(itemCtx, assembledGarment.Approval, assembledGarment.AssemblyDate, assembledGarment.Buttons, assembledGarment.CutParts, assembledGarment.FlagReason, assembledGarment.ID, assembledGarment.IsFlagged, assembledGarment.Notes, assembledGarment.Origin, assembledGarment.TotalWeight)
+	})
+}
+
+// CreateCartonsBatch issues every carton of the batch in a single transaction, applying the specifications of CreateCarton to each. The batch is atomic: unless every carton passes, none is issued and the error reports each carton that failed. Contains the following specifications: 1) SPEC_IsValidBatchSize
+func (s *SmartContract) CreateCartonsBatch(ctx contractapi.TransactionContextInterface, cartons []CartonBatchItem) error {
+	// Ensure that the batch size is within bounds
+	if err := SPEC_IsValidBatchSize(len(cartons)); err != nil {
+		return err
+	}
+	ids := []string{}
+	for _, carton := range cartons {
+		ids = append(ids, carton.ID)
+	}
+	return runBatch(ctx, "carton_", ids, func(itemCtx contractapi.TransactionContextInterface, i int) error {
+		carton := cartons[i]
+		return s.CreateCarton(itemCtx, carton.AllAssetsApproved, carton.AssemblyDate, carton.Content, carton.CustomerID, carton.FlagReason, carton.ID, carton.IsFlagged, carton.Notes, carton.OrderID, carton.Origin, carton.Owner, carton.TotalWeight)
+	})
+}
+
+// runBatch issues the assets of a batch of the given type, each reading the writes of the ones before it, and emits a single AssetsCreated event in place of the AssetCreated event of each asset
+func runBatch(ctx contractapi.TransactionContextInterface, assetType string, ids []string, create func(itemCtx contractapi.TransactionContextInterface, i int) error) error {
+	if err := batch.Run(ctx, ids, create); err != nil {
+		return err
+	}
+	// Emit the AssetsCreated event
+	return events.Emit(ctx, events.AssetsCreated, events.AssetsCreatedData{AssetIDs: ids, AssetType: assetType})
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+)
+
+func TestCreateButtonsBatch(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	button := func(id string, isFlagged bool, flagReason string) ButtonBatchItem {
+		return ButtonBatchItem{Approval: true, AssemblyDate: before, FlagReason: flagReason, ID: id, IsFlagged: isFlagged, Origin: "Dhaka", TotalWeight: 0.01}
+	}
+
+	// Every failed button is reported, and none of the batch is issued
+	err := ctx.Submit("Org6MSP", func() error {
+		return s.CreateButtonsBatch(ctx, []ButtonBatchItem{button("button_4", false, ""), button("button_1", false, ""), button("button_4", false, ""), button("button_5", true, "")})
+	})
+	checkErr(t, err, "3 of 4 items failed, so none was applied")
+	for _, want := range []string{"item 1 (button_1): the asset button_1 already exists", "item 2 (button_4): the asset button_4 already exists", "item 3 (button_5): flagReason must be provided"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to report %q, got %v", want, err)
+		}
+	}
+	if asset, _ := getAssetMap(ctx, "button_4"); asset != nil {
+		t.Errorf("expected button_4 not to be issued, got %v", asset)
+	}
+
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateButtonsBatch(ctx, []ButtonBatchItem{button("button_2", false, ""), button("button_3", true, "cracked")})
+	})
+	checkBatchCreated(t, ctx, "button_", "button_2", "button_3")
+	if ids, err := index.IDsOfType(ctx, "button_"); err != nil || fmt.Sprint(ids) != "[button_1 button_2 button_3]" {
+		t.Errorf("expected the buttons to be indexed, got %v, error %v", ids, err)
+	}
+
+	err = ctx.Submit("Org6MSP", func() error { return s.CreateButtonsBatch(ctx, []ButtonBatchItem{}) })
+	checkErr(t, err, "the batch must hold between 1 and 500 items, got 0")
+	err = ctx.Submit("Org4MSP", func() error { return s.CreateButtonsBatch(ctx, []ButtonBatchItem{button("button_9", false, "")}) })
+	checkErr(t, err, "item 0 (button_9): the function is not invoked by an allowed organization")
+}
+
+func TestCreateCutPartsBatch(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	cutPart := func(id string, drawnWeight float32) CutPartBatchItem {
+		return CutPartBatchItem{Approval: true, AssemblyDate: before, Content: []string{"lot_4"}, DrawnWeights: []float32{drawnWeight}, ID: id, Origin: "Dhaka", PatternPiece: "sleeve", TotalWeight: 0.2}
+	}
+
+	// Each cut part draws from the weight the cut parts before it left in the lot
+	err := ctx.Submit("Org6MSP", func() error {
+		return s.CreateCutPartsBatch(ctx, []CutPartBatchItem{cutPart("cutpart_2", 500), cutPart("cutpart_3", 400)})
+	})
+	checkErr(t, err, "1 of 2 items failed, so none was applied: item 1 (cutpart_3)")
+
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCutPartsBatch(ctx, []CutPartBatchItem{cutPart("cutpart_2", 500), cutPart("cutpart_3", 200)})
+	})
+	checkBatchCreated(t, ctx, "cutpart_", "cutpart_2", "cutpart_3")
+	lot, err := getLot(ctx, "lot_4")
+	checkErr(t, err, "")
+	if len(lot.Consumptions) != 3 || lot.ConsumedWeight < 700 {
+		t.Errorf("expected the draws of both cut parts to be recorded, got %+v", lot.Consumptions)
+	}
+}
+
+func TestCreateCartonsBatch(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateButtonsBatch(ctx, []ButtonBatchItem{{Approval: true, AssemblyDate: before, ID: "button_2", Origin: "Dhaka", TotalWeight: 0.01}})
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCutPartsBatch(ctx, []CutPartBatchItem{{Approval: true, AssemblyDate: before, Content: []string{"lot_4"}, ID: "cutpart_2", Origin: "Dhaka", PatternPiece: "front panel", TotalWeight: 0.3}})
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateAssembledGarmentsBatch(ctx, []AssembledGarmentBatchItem{
+			{Approval: true, AssemblyDate: before, Buttons: []string{"button_2"}, CutParts: []string{"cutpart_2"}, ID: "assembledgarment_2", Origin: "Dhaka", TotalWeight: 0.31},
+		})
+	})
+	checkBatchCreated(t, ctx, "assembledgarment_", "assembledgarment_2")
+
+	carton := func(id string, content ...string) CartonBatchItem {
+		return CartonBatchItem{AllAssetsApproved: true, AssemblyDate: before, Content: content, CustomerID: "Org1MSP", ID: id, OrderID: "order_1", Origin: "Dhaka", Owner: "Org6MSP", TotalWeight: 0.5}
+	}
+	err := ctx.Submit("Org6MSP", func() error {
+		return s.CreateCartonsBatch(ctx, []CartonBatchItem{carton("carton_2", "assembledgarment_2"), carton("carton_3", "assembledgarment_9")})
+	})
+	checkErr(t, err, "item 1 (carton_3): asset assembledgarment_9 does not exist")
+
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCartonsBatch(ctx, []CartonBatchItem{carton("carton_2", "assembledgarment_2")})
+	})
+	checkBatchCreated(t, ctx, "carton_", "carton_2")
+	if parentIDs, err := GetParentIDs(ctx, "assembledgarment_2"); err != nil || fmt.Sprint(parentIDs) != "[carton_2]" {
+		t.Errorf("expected a link from assembledgarment_2 to carton_2, got %v, error %v", parentIDs, err)
+	}
+	if ids, err := index.IDsOfOwner(ctx, "Org6MSP", "carton_"); err != nil || fmt.Sprint(ids) != "[carton_1 carton_2]" {
+		t.Errorf("expected the carton to be indexed under its owner, got %v, error %v", ids, err)
+	}
+}
+
+// checkBatchCreated fails the test unless every asset was saved and the batch was announced by a single AssetsCreated event
+func checkBatchCreated(t *testing.T, ctx *chaincodetest.TransactionContext, assetType string, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		if asset, err := getAssetMap(ctx, id); err != nil || asset["DocType"] != assetType {
+			t.Errorf("expected %s to be saved, got %v, error %v", id, asset, err)
+		}
+	}
+	last := ctx.Stub().LastEvent()
+	if last == nil {
+		t.Fatal("expected an AssetsCreated event, got none")
+	}
+	event, err := events.Decode(last.EventName, last.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := event.Data.(*events.AssetsCreatedData); !ok || data.AssetType != assetType || fmt.Sprint(data.AssetIDs) != fmt.Sprint(ids) {
+		t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// BackfillIndexes records the type key of every asset, and the owner key of every lot, carton and container. It is meant to be invoked once on ledgers created before the type and owner indexes existed, as the GetAll* queries only read the assets recorded in the type index. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole
+func (s *SmartContract) BackfillIndexes(ctx contractapi.TransactionContextInterface) (*index.Result, error) {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Ensure that the function is invoked by an organization allowed to backfill the indexes
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Backfill, policy.AssetType); err != nil {
+		return nil, err
+	}
+
+	return index.Backfill(ctx)
+}
+
+// GetAssetsByOwner retrieves the lots, cartons and containers owned by an organization, reading only its partition of the owner index and leaving out archived assets. An empty assetIDPrefix returns the assets of every type
+func (s *SmartContract) GetAssetsByOwner(ctx contractapi.TransactionContextInterface, owner string, assetIDPrefix string) ([]map[string]interface{}, error) {
+	return getAssetsByOwner(ctx, owner, assetIDPrefix, false)
+}
+
+// GetAssetsByOwnerIncludingArchived retrieves the lots, cartons and containers owned by an organization, including archived ones. An empty assetIDPrefix returns the assets of every type
+func (s *SmartContract) GetAssetsByOwnerIncludingArchived(ctx contractapi.TransactionContextInterface, owner string, assetIDPrefix string) ([]map[string]interface{}, error) {
+	return getAssetsByOwner(ctx, owner, assetIDPrefix, true)
+}
+
+// getAssetsByOwner retrieves the assets owned by an organization, reading only its partition of the owner index and leaving out archived assets unless includeArchived is true
+func getAssetsByOwner(ctx contractapi.TransactionContextInterface, owner string, assetIDPrefix string, includeArchived bool) ([]map[string]interface{}, error) {
+	// Retrieve the IDs of the archived assets
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Retrieve the IDs of the assets of the owner
+	ids, err := index.IDsOfOwner(ctx, owner, assetIDPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := []map[string]interface{}{}
+	for _, id := range ids {
+		// Leave out archived assets unless they are requested
+		if !includeArchived && archivedIDs[id] {
+			continue
+		}
+		asset, err := getAssetMap(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
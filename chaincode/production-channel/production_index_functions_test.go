@@ -0,0 +1,129 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+// assetIDs returns the IDs of the given assets
+func assetIDs(assets []map[string]interface{}) []string {
+	ids := []string{}
+	for _, asset := range assets {
+		ids = append(ids, asset["ID"].(string))
+	}
+	return ids
+}
+
+func TestGetAllAssetsOfTypeReadsItsPartition(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	// The ID of this bale contains "lot_", which used to be enough to be listed among the lots
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "", "cottonbale_lot_6", false, "", "Texas", "A", 500)
+	})
+
+	cottonBales, err := s.GetAllCottonBales(ctx)
+	checkErr(t, err, "")
+	if len(cottonBales) != 6 {
+		t.Errorf("expected 6 cotton bales, got %d", len(cottonBales))
+	}
+	lots, err := s.GetAllLots(ctx)
+	checkErr(t, err, "")
+	for _, lot := range lots {
+		if lot.ID == "cottonbale_lot_6" {
+			t.Errorf("expected cottonbale_lot_6 to be left out of the lots")
+		}
+	}
+	if len(lots) != 4 {
+		t.Errorf("expected 4 lots, got %d", len(lots))
+	}
+	count, err := s.GetAllAssetsOfTypeCount(ctx, "lot_")
+	checkErr(t, err, "")
+	if count != 4 {
+		t.Errorf("expected 4 lots to be counted, got %d", count)
+	}
+	count, err = GetLotsWithPrefixCount(ctx, "cottonbale_")
+	checkErr(t, err, "")
+	if count != 1 {
+		t.Errorf("expected 1 lot of cotton bales, got %d", count)
+	}
+}
+
+func TestGetAssetsByOwner(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	ownedBy := func(owner string, assetIDPrefix string) []string {
+		t.Helper()
+		assets, err := s.GetAssetsByOwner(ctx, owner, assetIDPrefix)
+		checkErr(t, err, "")
+		return assetIDs(assets)
+	}
+
+	if ids := ownedBy("Org4MSP", "lot_"); !contains(ids, "lot_1") || !contains(ids, "lot_2") || len(ids) != 2 {
+		t.Errorf("expected Org4MSP to own lot_1 and lot_2, got %v", ids)
+	}
+	if ids := ownedBy("Org6MSP", ""); !contains(ids, "carton_1") || !contains(ids, "container_1") || len(ids) != 2 {
+		t.Errorf("expected Org6MSP to own carton_1 and container_1, got %v", ids)
+	}
+
+	// A transferred lot moves to the partition of its new owner
+	submit(t, ctx, "Org4MSP", func() error { return s.UpdateLotOwner(ctx, "lot_1", "Org1MSP") })
+	if ids := ownedBy("Org4MSP", "lot_"); contains(ids, "lot_1") {
+		t.Errorf("expected lot_1 to leave the partition of Org4MSP, got %v", ids)
+	}
+	if ids := ownedBy("Org1MSP", "lot_"); !contains(ids, "lot_1") {
+		t.Errorf("expected lot_1 to enter the partition of Org1MSP, got %v", ids)
+	}
+
+	// An archived lot is left out unless requested
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonbale_", []string{"cottonbale_3"}, "Texas", "", "lot_9", false, "", "Texas", "Org4MSP", 480)
+	})
+	submit(t, ctx, "Org4MSP", func() error { return s.ArchiveAsset(ctx, "lot_9", "wrong weight") })
+	if ids := ownedBy("Org4MSP", "lot_"); contains(ids, "lot_9") {
+		t.Errorf("expected the archived lot_9 to be left out, got %v", ids)
+	}
+	assets, err := s.GetAssetsByOwnerIncludingArchived(ctx, "Org4MSP", "lot_")
+	checkErr(t, err, "")
+	if ids := assetIDs(assets); !contains(ids, "lot_2") || !contains(ids, "lot_9") || len(ids) != 2 {
+		t.Errorf("expected lot_2 and the archived lot_9, got %v", ids)
+	}
+}
+
+func TestBackfillIndexes(t *testing.T) {
+	s := &SmartContract{}
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	// Records written before the indexes existed
+	ctx.Stub().Seed("cottonbale_1", []byte(`{"ID":"cottonbale_1","TotalWeight":480}`))
+	ctx.Stub().Seed("lot_1", []byte(`{"AssetIDPrefix":"cottonbale_","Content":["cottonbale_1"],"ID":"lot_1","Owner":"Org4MSP"}`))
+
+	cottonBales, err := s.GetAllCottonBales(ctx)
+	checkErr(t, err, "")
+	if len(cottonBales) != 0 {
+		t.Errorf("expected unindexed cotton bales to be left out, got %d", len(cottonBales))
+	}
+
+	err = ctx.Submit("Org4MSP", func() error {
+		_, err := s.BackfillIndexes(ctx)
+		return err
+	})
+	checkErr(t, err, "not invoked by an allowed organization")
+	err = ctx.Submit("Org1MSP", func() error {
+		result, err := s.BackfillIndexes(ctx)
+		if err == nil && (result.TypeKeys != 2 || result.OwnerKeys != 1) {
+			t.Errorf("unexpected result %+v", result)
+		}
+		return err
+	})
+	checkErr(t, err, "")
+
+	cottonBales, err = s.GetAllCottonBales(ctx)
+	checkErr(t, err, "")
+	if len(cottonBales) != 1 || cottonBales[0].ID != "cottonbale_1" {
+		t.Errorf("expected cottonbale_1 once backfilled, got %+v", cottonBales)
+	}
+	assets, err := s.GetAssetsByOwner(ctx, "Org4MSP", "lot_")
+	checkErr(t, err, "")
+	if ids := assetIDs(assets); len(ids) != 1 || ids[0] != "lot_1" {
+		t.Errorf("expected lot_1 to be owned by Org4MSP once backfilled, got %v", ids)
+	}
+}
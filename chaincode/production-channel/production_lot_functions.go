@@ -0,0 +1,593 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// massBalanceTolerance is the largest percentage by which the total weight of the lots a lot is split or merged into may differ from the total weight of the lots it is split or merged from
+const massBalanceTolerance float32 = 1
+
+// GetDrawnWeights returns the weight to draw from each content lot of an asset weighing totalWeight, and the content weight of the asset. Explicit drawnWeights record the actual input, e.g., including spinning losses, and make up the content weight. An empty drawnWeights draws totalWeight from the content lots in order, each up to the weight still available in it, and the content weight is the total weight of the content lots
+func GetDrawnWeights(ctx contractapi.TransactionContextInterface, content []string, drawnWeights []float32, totalWeight float32) ([]float32, float32, error) {
+	if len(drawnWeights) > 0 {
+		if len(drawnWeights) != len(content) {
+			return nil, 0, fmt.Errorf("expected one drawn weight per content lot, got %d drawn weights for %d lots", len(drawnWeights), len(content))
+		}
+		var contentWeight float32
+		for _, draw := range drawnWeights {
+			contentWeight += draw
+		}
+		return drawnWeights, contentWeight, nil
+	}
+	draws := []float32{}
+	remainingWeight := totalWeight
+	for _, lotID := range content {
+		lot, err := getLot(ctx, lotID)
+		if err != nil {
+			return nil, 0, err
+		}
+		var draw float32
+		if availableWeight := lot.TotalWeight - lot.ConsumedWeight; availableWeight > 0 && remainingWeight > 0 {
+			draw = remainingWeight
+			if availableWeight < draw {
+				draw = availableWeight
+			}
+		}
+		draws = append(draws, draw)
+		remainingWeight -= draw
+	}
+	if remainingWeight > 0 {
+		return nil, 0, fmt.Errorf("the content lots %v hold only %v of the %v drawn", content, totalWeight-remainingWeight, totalWeight)
+	}
+	contentWeight, err := GetContentWeight(ctx, content)
+	if err != nil {
+		return nil, 0, err
+	}
+	return draws, contentWeight, nil
+}
+
+// ConsumeLots records the weight drawn from each content lot by the asset made from them, and marks the lots left without available weight as consumed. Lots nothing is drawn from are left unchanged. isDefaultDraw tells that the draws are the weight of the asset itself rather than reported drawn weights
+func ConsumeLots(ctx contractapi.TransactionContextInterface, assetID string, content []string, draws []float32, isDefaultDraw bool, txTimestamp time.Time) error {
+	for i, lotID := range content {
+		if draws[i] == 0 {
+			continue
+		}
+		lot, err := getLot(ctx, lotID)
+		if err != nil {
+			return err
+		}
+		lot.Consumptions = append(lot.Consumptions, &LotConsumption{AssetID: assetID, IsDefaultDraw: isDefaultDraw, Weight: draws[i]})
+		lot.ConsumedWeight += draws[i]
+		lot.ConsumptionState = "available"
+		if lot.ConsumedWeight >= lot.TotalWeight {
+			lot.ConsumptionState = "consumed"
+		}
+		lot.UpdatedAt = txTimestamp
+
+		// Convert lot to JSON
+		lotJSON, err := json.Marshal(lot)
+		if err != nil {
+			return err
+		}
+		// Save the lot to the world state
+		if err := ctx.GetStub().PutState(lotID, lotJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// consumerIDs returns the IDs of the assets that drew weight from the lot
+func consumerIDs(lot *Lot) []string {
+	ids := []string{}
+	for _, consumption := range lot.Consumptions {
+		ids = append(ids, consumption.AssetID)
+	}
+	return ids
+}
+
+// SplitLot retires a lot and divides its content between two or more new lots, e.g., to split a yarn lot between two weaving lines. The new lots inherit the attributes of the split lot, including its custodian and location. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsInvokedByAllowedOrg, 5) SPEC_IsNotRetired, 6) SPEC_IsOnSite, 7) SPEC_IsNotFlagged, 8) SPEC_IsAvailable, 9) SPEC_NoDuplicateAssetInThisLot, 10) SPEC_IsNewAsset, 11) SPEC_ContentPreserved, 12) SPEC_NoDuplicateAssetInState, 13) SPEC_WeightWithinTolerance
+func (s *SmartContract) SplitLot(ctx contractapi.TransactionContextInterface, lotID string, portions []LotPortion) error {
+	// Ensure the id begins with "lot_"
+	if err := SPEC_IDPrefix(lotID, "lot_"); err != nil {
+		return err
+	}
+	// Ensure the lot exists
+	if err := SPEC_AssetExists(ctx, lotID); err != nil {
+		return err
+	}
+	lot, err := getLot(ctx, lotID)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create lots of the asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.CreateLot, lot.AssetIDPrefix); err != nil {
+		return err
+	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	for i := range portions {
+		portions[i].TotalWeight = reportingUnits.ConvertWeight(portions[i].TotalWeight)
+	}
+	// Ensure that the lot is split by its owner
+	if err := SPEC_IsInvokedByAllowedOrg(ctx, lot.Owner); err != nil {
+		return err
+	}
+	// Ensure that the lot was not already split or merged
+	if err := SPEC_IsNotRetired(ctx, lotID); err != nil {
+		return err
+	}
+	// Ensure that the lot is not in transit
+	if err := SPEC_IsOnSite(ctx, lotID); err != nil {
+		return err
+	}
+	// Ensure that the lot is not flagged
+	if err := SPEC_IsNotFlagged(ctx, lotID); err != nil {
+		return err
+	}
+	// Ensure that nothing was drawn from the lot
+	if err := SPEC_IsAvailable(ctx, lotID, lot.TotalWeight); err != nil {
+		return err
+	}
+
+	if len(portions) < 2 {
+		return fmt.Errorf("lot %s must be split into at least two lots", lotID)
+	}
+	successorIDs := []string{}
+	portionContents := [][]string{}
+	var portionsWeight float32
+	for _, portion := range portions {
+		successorIDs = append(successorIDs, portion.ID)
+		portionContents = append(portionContents, portion.Content)
+		portionsWeight += portion.TotalWeight
+	}
+	// Ensure that each new lot is listed once
+	if err := SPEC_NoDuplicateAssetInThisLot(successorIDs); err != nil {
+		return err
+	}
+	for _, portion := range portions {
+		// Ensure the id begins with "lot_"
+		if err := SPEC_IDPrefix(portion.ID, "lot_"); err != nil {
+			return err
+		}
+		// Ensure the new lot does not already exist
+		if err := SPEC_IsNewAsset(ctx, portion.ID); err != nil {
+			return err
+		}
+		// Ensure that the new lot has a weight, so that a portion cannot offset another in the mass balance
+		if portion.TotalWeight <= 0 {
+			return fmt.Errorf("the weight of new lot %s must be positive, got %v", portion.ID, portion.TotalWeight)
+		}
+	}
+	// Ensure that every asset of the lot ends up in exactly one new lot
+	if err := SPEC_ContentPreserved(lot.Content, portionContents); err != nil {
+		return err
+	}
+	// Ensure that no asset of a new lot is stored in a lot other than the split lot
+	for _, portion := range portions {
+		if err := SPEC_NoDuplicateAssetInState(ctx, portion.ID, portion.Content, lotID); err != nil {
+			return err
+		}
+	}
+	// Ensure that the new lots weigh as much as the split lot
+	if err := SPEC_WeightWithinTolerance(lot.TotalWeight, portionsWeight, massBalanceTolerance); err != nil {
+		return err
+	}
+
+	// Retrieve the invoking organization's MSP ID
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, portion := range portions {
+		// Calculate the content weight by summing asset weights in content
+		contentWeight, err := GetContentWeight(ctx, portion.Content)
+		if err != nil {
+			return err
+		}
+		successor := Lot{
+			AllAssetsApproved: lot.AllAssetsApproved,
+			AssemblyDate:      lot.AssemblyDate,
+			AssetIDPrefix:     lot.AssetIDPrefix,
+			ConsumptionState:  "available",
+			Content:           portion.Content,
+			ContentWeight:     contentWeight,
+			CreatorID:         clientMSPID,
+			Custodian:         lot.Custodian,
+			Destination:       lot.Destination,
+			DocType:           "lot_",
+			ID:                portion.ID,
+			Location:          lot.Location,
+			Notes:             lot.Notes,
+			Origin:            lot.Origin,
+			Owner:             lot.Owner,
+			PredecessorIDs:    []string{lotID},
+			PreviousOwner:     lot.PreviousOwner,
+			Quantity:          len(portion.Content),
+			SchemaVersion:     schemaVersion,
+			TotalWeight:       portion.TotalWeight,
+			TransitState:      "on_site",
+			UpdatedAt:         txTimestamp,
+			WeightDifference:  GetPercentageDifference(contentWeight, portion.TotalWeight),
+			WeightUnit:        ledgerUnits.Weight,
+		}
+		if err := putSuccessorLot(ctx, &successor); err != nil {
+			return err
+		}
+	}
+
+	// Retire the split lot
+	if err := retireLot(ctx, lot, successorIDs, txTimestamp); err != nil {
+		return err
+	}
+	// Emit the LotSplit event
+	return events.Emit(ctx, events.LotSplit, events.LotSplitData{LotID: lotID, SuccessorIDs: successorIDs})
+}
+
+// MergeLots retires two or more lots of the same asset type and owner and combines their content into a new lot. The new lot inherits the origin, destination, custodian and location of the first merged lot. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_NoDuplicateAssetInThisLot, 4) SPEC_LotConsistency, 5) SPEC_IsInvokedByAllowedRole, 6) SPEC_IsInvokedByAllowedOrg, 7) SPEC_IsNotRetired, 8) SPEC_IsOnSite, 9) SPEC_IsNotFlagged, 10) SPEC_CheckLotAssetType, 11) SPEC_IsAvailable, 12) SPEC_NoDuplicateAssetInState, 13) SPEC_WeightWithinTolerance
+func (s *SmartContract) MergeLots(ctx contractapi.TransactionContextInterface, lotID string, notes string, predecessorIDs []string, totalWeight float32) error {
+	// Ensure the id begins with "lot_"
+	if err := SPEC_IDPrefix(lotID, "lot_"); err != nil {
+		return err
+	}
+	// Ensure the lot does not already exist
+	if err := SPEC_IsNewAsset(ctx, lotID); err != nil {
+		return err
+	}
+	if len(predecessorIDs) < 2 {
+		return fmt.Errorf("at least two lots must be merged into lot %s", lotID)
+	}
+	// Ensure that each merged lot is listed once
+	if err := SPEC_NoDuplicateAssetInThisLot(predecessorIDs); err != nil {
+		return err
+	}
+	// Ensure that each merged lot exists
+	if err := SPEC_LotConsistency(ctx, predecessorIDs, "lot_"); err != nil {
+		return err
+	}
+	first, err := getLot(ctx, predecessorIDs[0])
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create lots of the asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.CreateLot, first.AssetIDPrefix); err != nil {
+		return err
+	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
+
+	predecessors := []*Lot{}
+	content := []string{}
+	allAssetsApproved := true
+	assemblyDate := first.AssemblyDate
+	var predecessorsWeight float32
+	for _, predecessorID := range predecessorIDs {
+		predecessor, err := getLot(ctx, predecessorID)
+		if err != nil {
+			return err
+		}
+		// Ensure that each merged lot is merged by its owner
+		if err := SPEC_IsInvokedByAllowedOrg(ctx, predecessor.Owner); err != nil {
+			return err
+		}
+		// Ensure that each merged lot was not already split or merged
+		if err := SPEC_IsNotRetired(ctx, predecessorID); err != nil {
+			return err
+		}
+		// Ensure that each merged lot is not in transit
+		if err := SPEC_IsOnSite(ctx, predecessorID); err != nil {
+			return err
+		}
+		// Ensure that each merged lot is not flagged
+		if err := SPEC_IsNotFlagged(ctx, predecessorID); err != nil {
+			return err
+		}
+		// Ensure that each merged lot holds assets of the same type as the first one
+		if err := SPEC_CheckLotAssetType(ctx, predecessorID, first.AssetIDPrefix); err != nil {
+			return err
+		}
+		// Ensure that nothing was drawn from each merged lot
+		if err := SPEC_IsAvailable(ctx, predecessorID, predecessor.TotalWeight); err != nil {
+			return err
+		}
+		predecessors = append(predecessors, predecessor)
+		content = append(content, predecessor.Content...)
+		allAssetsApproved = allAssetsApproved && predecessor.AllAssetsApproved
+		if predecessor.AssemblyDate.After(assemblyDate) {
+			assemblyDate = predecessor.AssemblyDate
+		}
+		predecessorsWeight += predecessor.TotalWeight
+	}
+	// Ensure that no asset of the new lot is stored in a lot other than the merged lots
+	if err := SPEC_NoDuplicateAssetInState(ctx, lotID, content, predecessorIDs...); err != nil {
+		return err
+	}
+	// Ensure that the new lot weighs as much as the merged lots
+	if err := SPEC_WeightWithinTolerance(predecessorsWeight, totalWeight, massBalanceTolerance); err != nil {
+		return err
+	}
+
+	// Calculate the content weight by summing asset weights in content
+	contentWeight, err := GetContentWeight(ctx, content)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the invoking organization's MSP ID
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	successor := Lot{
+		AllAssetsApproved: allAssetsApproved,
+		AssemblyDate:      assemblyDate,
+		AssetIDPrefix:     first.AssetIDPrefix,
+		ConsumptionState:  "available",
+		Content:           content,
+		ContentWeight:     contentWeight,
+		CreatorID:         clientMSPID,
+		Custodian:         first.Custodian,
+		Destination:       first.Destination,
+		DocType:           "lot_",
+		ID:                lotID,
+		Location:          first.Location,
+		Notes:             notes,
+		Origin:            first.Origin,
+		Owner:             first.Owner,
+		PredecessorIDs:    predecessorIDs,
+		PreviousOwner:     first.PreviousOwner,
+		Quantity:          len(content),
+		SchemaVersion:     schemaVersion,
+		TotalWeight:       totalWeight,
+		TransitState:      "on_site",
+		UpdatedAt:         txTimestamp,
+		WeightDifference:  GetPercentageDifference(contentWeight, totalWeight),
+		WeightUnit:        ledgerUnits.Weight,
+	}
+	if err := putSuccessorLot(ctx, &successor); err != nil {
+		return err
+	}
+
+	// Retire the merged lots
+	for _, predecessor := range predecessors {
+		if err := retireLot(ctx, predecessor, []string{lotID}, txTimestamp); err != nil {
+			return err
+		}
+	}
+	// Emit the LotsMerged event
+	return events.Emit(ctx, events.LotsMerged, events.LotsMergedData{LotID: lotID, PredecessorIDs: predecessorIDs})
+}
+
+// DispatchLot records that the organization physically holding a lot handed it over for transport to receiverID, e.g., a spinning mill shipping a yarn lot to a textile mill. The lot stays in transit, and cannot be used, split or merged, until the receiver confirms its arrival with ReceiveLot. Ownership is transferred separately with UpdateLotOwner. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsNotRetired, 5) SPEC_IsOnSite, 6) SPEC_IsAllowedToOwn, 7) SPEC_DestinationMatchesSite, 8) SPEC_Chronology
+func (s *SmartContract) DispatchLot(ctx contractapi.TransactionContextInterface, dispatchedAt time.Time, lotID string, receiverID string) error {
+	// Ensure the id begins with "lot_"
+	if err := SPEC_IDPrefix(lotID, "lot_"); err != nil {
+		return err
+	}
+	// Ensure the lot exists
+	if err := SPEC_AssetExists(ctx, lotID); err != nil {
+		return err
+	}
+	lot, err := getLot(ctx, lotID)
+	if err != nil {
+		return err
+	}
+	custodian := getCustodian(lot)
+	// Ensure that the lot is dispatched by the organization holding it
+	if err := SPEC_IsInvokedByAllowedOrg(ctx, custodian); err != nil {
+		return err
+	}
+	// Ensure that the lot was not split or merged into other lots
+	if err := SPEC_IsNotRetired(ctx, lotID); err != nil {
+		return err
+	}
+	// Ensure that the lot is not already in transit
+	if err := SPEC_IsOnSite(ctx, lotID); err != nil {
+		return err
+	}
+	if receiverID == custodian {
+		return fmt.Errorf("lot %s is already held by %s", lotID, receiverID)
+	}
+
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the receiver is allowed to own lots of the underlying asset type
+	if err := SPEC_IsAllowedToOwn(ctx, receiverID, rolePolicy.MSPIDsAllowedTo(policy.OwnLot, lot.AssetIDPrefix)...); err != nil {
+		return err
+	}
+	// Ensure that the lot is sent to the receiver's registered site
+	if err := SPEC_DestinationMatchesSite(rolePolicy, lot, receiverID); err != nil {
+		return err
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the lot is dispatched after it was assembled or last received, and not in the future
+	arrivedAt := lot.AssemblyDate
+	if lot.ReceivedAt.After(arrivedAt) {
+		arrivedAt = lot.ReceivedAt
+	}
+	if err := SPEC_Chronology(arrivedAt, dispatchedAt, txTimestamp); err != nil {
+		return err
+	}
+
+	if lot.Location == "" {
+		lot.Location = lot.Origin
+	}
+	lot.Custodian = custodian
+	lot.DispatchedAt = dispatchedAt
+	lot.ReceiverID = receiverID
+	lot.TransitState = "in_transit"
+	if err := putLot(ctx, lot, txTimestamp); err != nil {
+		return err
+	}
+	// Emit the LotDispatched event
+	return events.Emit(ctx, events.LotDispatched, events.LotDispatchedData{Destination: lot.Destination, LotID: lotID, ReceiverID: receiverID, SenderID: custodian})
+}
+
+// ReceiveLot records that the organization a lot was dispatched to has received it at its destination, which becomes the location of the lot. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists, 3) SPEC_IsInTransit, 4) SPEC_IsInvokedByAllowedOrg, 5) SPEC_Chronology
+func (s *SmartContract) ReceiveLot(ctx contractapi.TransactionContextInterface, lotID string, receivedAt time.Time) error {
+	// Ensure the id begins with "lot_"
+	if err := SPEC_IDPrefix(lotID, "lot_"); err != nil {
+		return err
+	}
+	// Ensure the lot exists
+	if err := SPEC_AssetExists(ctx, lotID); err != nil {
+		return err
+	}
+	lot, err := getLot(ctx, lotID)
+	if err != nil {
+		return err
+	}
+	// Ensure that the lot was dispatched and not received yet
+	if err := SPEC_IsInTransit(lot); err != nil {
+		return err
+	}
+	// Ensure that the lot is received by the organization it was dispatched to
+	if err := SPEC_IsInvokedByAllowedOrg(ctx, lot.ReceiverID); err != nil {
+		return err
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the lot is received after it was dispatched and not in the future
+	if err := SPEC_Chronology(lot.DispatchedAt, receivedAt, txTimestamp); err != nil {
+		return err
+	}
+
+	lot.Custodian = lot.ReceiverID
+	lot.Location = lot.Destination
+	lot.ReceivedAt = receivedAt
+	lot.TransitState = "on_site"
+	if err := putLot(ctx, lot, txTimestamp); err != nil {
+		return err
+	}
+	// Emit the LotReceived event
+	return events.Emit(ctx, events.LotReceived, events.LotReceivedData{Location: lot.Location, LotID: lotID, ReceiverID: lot.ReceiverID})
+}
+
+// putLot saves an updated lot to the world state
+func putLot(ctx contractapi.TransactionContextInterface, lot *Lot, txTimestamp time.Time) error {
+	lot.UpdatedAt = txTimestamp
+
+	// Convert lot to JSON
+	lotJSON, err := json.Marshal(lot)
+	if err != nil {
+		return err
+	}
+	// Save the lot to the world state
+	return ctx.GetStub().PutState(lot.ID, lotJSON)
+}
+
+// putSuccessorLot saves a lot created by SplitLot or MergeLots, links its content and its predecessors to it, and records it as the lot of each asset in its content
+func putSuccessorLot(ctx contractapi.TransactionContextInterface, lot *Lot) error {
+	// Convert lot to JSON
+	lotJSON, err := json.Marshal(lot)
+	if err != nil {
+		return err
+	}
+	// Save the lot to the world state
+	if err := ctx.GetStub().PutState(lot.ID, lotJSON); err != nil {
+		return err
+	}
+	// Index the lot by type and owner
+	if err := index.PutType(ctx, lot.ID); err != nil {
+		return err
+	}
+	if err := index.PutOwner(ctx, lot.Owner, lot.ID); err != nil {
+		return err
+	}
+	// Link each asset in the content list and each predecessor to this lot
+	if err := PutParentLinks(ctx, lot.ID, append(append([]string{}, lot.Content...), lot.PredecessorIDs...)); err != nil {
+		return err
+	}
+	// Record this lot as the lot of each asset in the content list
+	return PutLotMembership(ctx, lot.ID, lot.Content)
+}
+
+// retireLot records the successors of a split or merged lot
+func retireLot(ctx contractapi.TransactionContextInterface, lot *Lot, successorIDs []string, txTimestamp time.Time) error {
+	lot.SuccessorIDs = successorIDs
+	lot.UpdatedAt = txTimestamp
+
+	// Convert lot to JSON
+	lotJSON, err := json.Marshal(lot)
+	if err != nil {
+		return err
+	}
+	// Save the lot to the world state
+	return ctx.GetStub().PutState(lot.ID, lotJSON)
+}
+
+// getCustodian returns the organization physically holding the lot. Lots created before custody was tracked are held by their owner
+func getCustodian(lot *Lot) string {
+	if lot.Custodian == "" {
+		return lot.Owner
+	}
+	return lot.Custodian
+}
+
+// getLot retrieves a lot from the world state
+func getLot(ctx contractapi.TransactionContextInterface, lotID string) (*Lot, error) {
+	lotJSON, err := ctx.GetStub().GetState(lotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lot %s: %v", lotID, err)
+	}
+	if lotJSON == nil {
+		return nil, fmt.Errorf("lot %s does not exist", lotID)
+	}
+	var lot Lot
+	err = json.Unmarshal(lotJSON, &lot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal lot %s: %v", lotID, err)
+	}
+	return &lot, nil
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+)
+
+// newSplittableLedger adds lot_5, an unused lot of cottonbale_3 and cottonbale_6 owned by Org4MSP, to the supply chain ledger
+func newSplittableLedger(t *testing.T) (*SmartContract, *chaincodetest.TransactionContext) {
+	t.Helper()
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "", "cottonbale_6", false, "", "Texas", "B", 300)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonbale_", []string{"cottonbale_3", "cottonbale_6"}, "Org5MSP", "", "lot_5", false, "", "Texas", "Org4MSP", 800)
+	})
+	return s, ctx
+}
+
+// checkLot fails the test unless the lot holds the content and is linked to its predecessors and successors
+func checkLot(t *testing.T, ctx *chaincodetest.TransactionContext, id string, quantity int, contentWeight float32, predecessorIDs []string, successorIDs []string) {
+	t.Helper()
+	lot, err := getLot(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lot.Quantity != quantity || len(lot.Content) != quantity || lot.ContentWeight != contentWeight {
+		t.Errorf("unexpected content of lot %s: %+v", id, lot)
+	}
+	if len(lot.PredecessorIDs) != len(predecessorIDs) || len(lot.SuccessorIDs) != len(successorIDs) {
+		t.Errorf("unexpected predecessors or successors of lot %s: %v, %v", id, lot.PredecessorIDs, lot.SuccessorIDs)
+	}
+	for _, predecessorID := range predecessorIDs {
+		if !contains(lot.PredecessorIDs, predecessorID) {
+			t.Errorf("expected %s to be a predecessor of lot %s, got %v", predecessorID, id, lot.PredecessorIDs)
+		}
+	}
+	for _, successorID := range successorIDs {
+		if !contains(lot.SuccessorIDs, successorID) {
+			t.Errorf("expected %s to be a successor of lot %s, got %v", successorID, id, lot.SuccessorIDs)
+		}
+	}
+}
+
+func TestSplitLot(t *testing.T) {
+	split := func(lotID string, portions ...LotPortion) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SplitLot(ctx, lotID, portions)
+		}
+	}
+	portion := func(id string, totalWeight float32, content ...string) LotPortion {
+		return LotPortion{Content: content, ID: id, TotalWeight: totalWeight}
+	}
+	runOn(t, newSplittableLedger, []testCase{
+		{"valid", "Org4MSP", split("lot_5", portion("lot_6", 500, "cottonbale_3"), portion("lot_7", 300, "cottonbale_6")), ""},
+		{"wrong prefix", "Org4MSP", split("cottonbale_3", portion("lot_6", 500, "cottonbale_3"), portion("lot_7", 300, "cottonbale_6")), "must start with 'lot_'"},
+		{"missing lot", "Org4MSP", split("lot_9", portion("lot_6", 500, "cottonbale_3"), portion("lot_7", 300, "cottonbale_6")), "the asset lot_9 does not exist"},
+		{"not allowed for the asset type", "Org5MSP", split("lot_5", portion("lot_6", 500, "cottonbale_3"), portion("lot_7", 300, "cottonbale_6")), "not invoked by an allowed organization"},
+		{"not the owner", "Org2MSP", split("lot_5", portion("lot_6", 500, "cottonbale_3"), portion("lot_7", 300, "cottonbale_6")), "Allowed organizations: [Org4MSP]"},
+		{"single portion", "Org4MSP", split("lot_5", portion("lot_6", 800, "cottonbale_3", "cottonbale_6")), "must be split into at least two lots"},
+		{"duplicate portion", "Org4MSP", split("lot_5", portion("lot_6", 500, "cottonbale_3"), portion("lot_6", 300, "cottonbale_6")), "duplicate asset ID found: lot_6"},
+		{"portion with the wrong prefix", "Org4MSP", split("lot_5", portion("lot_6", 500, "cottonbale_3"), portion("cottonyarn_7", 300, "cottonbale_6")), "must start with 'lot_'"},
+		{"existing portion", "Org4MSP", split("lot_5", portion("lot_6", 500, "cottonbale_3"), portion("lot_1", 300, "cottonbale_6")), "the asset lot_1 already exists"},
+		{"asset left behind", "Org4MSP", split("lot_5", portion("lot_6", 500, "cottonbale_3"), portion("lot_7", 300)), "content list cannot be empty"},
+		{"asset of another lot", "Org4MSP", split("lot_5", portion("lot_6", 500, "cottonbale_3"), portion("lot_7", 300, "cottonbale_6", "cottonbale_1")), "asset cottonbale_1 is not in the content"},
+		{"negative portion", "Org4MSP", split("lot_5", portion("lot_6", 1600, "cottonbale_3"), portion("lot_7", -800, "cottonbale_6")), "the weight of new lot lot_7 must be positive, got -800"},
+		{"empty portion", "Org4MSP", split("lot_5", portion("lot_6", 800, "cottonbale_3"), portion("lot_7", 0, "cottonbale_6")), "the weight of new lot lot_7 must be positive, got 0"},
+		{"weight lost", "Org4MSP", split("lot_5", portion("lot_6", 500, "cottonbale_3"), portion("lot_7", 200, "cottonbale_6")), "more than the tolerance of 1%"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkLot(t, ctx, "lot_5", 2, 800, nil, []string{"lot_6", "lot_7"})
+		checkLot(t, ctx, "lot_6", 1, 500, []string{"lot_5"}, nil)
+		checkLot(t, ctx, "lot_7", 1, 300, []string{"lot_5"}, nil)
+
+		// The lot membership key now points to the new lot
+		checkErr(t, SPEC_NoDuplicateAssetInState(ctx, "lot_9", []string{"cottonbale_6"}), "already stored in lot lot_7")
+
+		last := ctx.Stub().LastEvent()
+		event, err := events.Decode(last.EventName, last.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, ok := event.Data.(*events.LotSplitData); !ok || data.LotID != "lot_5" || len(data.SuccessorIDs) != 2 {
+			t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+		}
+	})
+}
+
+func TestMergeLots(t *testing.T) {
+	newSplitLedger := func(t *testing.T) (*SmartContract, *chaincodetest.TransactionContext) {
+		s, ctx := newSplittableLedger(t)
+		submit(t, ctx, "Org4MSP", func() error {
+			return s.SplitLot(ctx, "lot_5", []LotPortion{{Content: []string{"cottonbale_3"}, ID: "lot_6", TotalWeight: 500}, {Content: []string{"cottonbale_6"}, ID: "lot_7", TotalWeight: 300}})
+		})
+		return s, ctx
+	}
+	merge := func(lotID string, totalWeight float32, predecessorIDs ...string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.MergeLots(ctx, lotID, "recombined for a single spinning run", predecessorIDs, totalWeight)
+		}
+	}
+	runOn(t, newSplitLedger, []testCase{
+		{"valid", "Org4MSP", merge("lot_8", 800, "lot_6", "lot_7"), ""},
+		{"wrong prefix", "Org4MSP", merge("cottonbale_8", 800, "lot_6", "lot_7"), "must start with 'lot_'"},
+		{"already exists", "Org4MSP", merge("lot_1", 800, "lot_6", "lot_7"), "the asset lot_1 already exists"},
+		{"single lot", "Org4MSP", merge("lot_8", 500, "lot_6"), "at least two lots must be merged"},
+		{"duplicate lot", "Org4MSP", merge("lot_8", 1000, "lot_6", "lot_6"), "duplicate asset ID found: lot_6"},
+		{"missing lot", "Org4MSP", merge("lot_8", 800, "lot_6", "lot_9"), "lot_9 does not exist"},
+		{"not allowed for the asset type", "Org6MSP", merge("lot_8", 800, "lot_6", "lot_7"), "not invoked by an allowed organization"},
+		{"not the owner", "Org1MSP", merge("lot_8", 800, "lot_6", "lot_7"), "Allowed organizations: [Org4MSP]"},
+		{"retired lot", "Org4MSP", merge("lot_8", 1600, "lot_6", "lot_5"), "lot lot_5 is retired and was succeeded by [lot_6 lot_7]"},
+		{"lots of different types", "Org4MSP", merge("lot_8", 1400, "lot_6", "lot_2"), "asset cottonyarn_1 in lot lot_2 does not have the correct prefix cottonbale_"},
+		{"weight gained", "Org4MSP", merge("lot_8", 900, "lot_6", "lot_7"), "more than the tolerance of 1%"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkLot(t, ctx, "lot_6", 1, 500, []string{"lot_5"}, []string{"lot_8"})
+		checkLot(t, ctx, "lot_7", 1, 300, []string{"lot_5"}, []string{"lot_8"})
+		checkLot(t, ctx, "lot_8", 2, 800, []string{"lot_6", "lot_7"}, nil)
+
+		last := ctx.Stub().LastEvent()
+		event, err := events.Decode(last.EventName, last.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, ok := event.Data.(*events.LotsMergedData); !ok || data.LotID != "lot_8" || len(data.PredecessorIDs) != 2 {
+			t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+		}
+	})
+}
+
+func TestLotLineage(t *testing.T) {
+	s, ctx := newSplittableLedger(t)
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.SplitLot(ctx, "lot_5", []LotPortion{{Content: []string{"cottonbale_3"}, ID: "lot_6", TotalWeight: 500}, {Content: []string{"cottonbale_6"}, ID: "lot_7", TotalWeight: 300}})
+	})
+
+	// A retired lot can no longer be used, transferred or split
+	err := ctx.Submit("Org4MSP", func() error {
+		return s.CreateCottonYarn(ctx, true, before, []string{"lot_5"}, "", "cottonyarn_9", false, "", "Texas", 700, 30)
+	})
+	checkErr(t, err, "lot lot_5 is retired")
+	err = ctx.Submit("Org4MSP", func() error {
+		return s.UpdateLotOwner(ctx, "lot_5", "Org2MSP")
+	})
+	checkErr(t, err, "lot lot_5 is retired")
+	err = ctx.Submit("Org4MSP", func() error {
+		return s.SplitLot(ctx, "lot_5", []LotPortion{{Content: []string{"cottonbale_3"}, ID: "lot_8", TotalWeight: 500}, {Content: []string{"cottonbale_6"}, ID: "lot_9", TotalWeight: 300}})
+	})
+	checkErr(t, err, "lot lot_5 is retired")
+
+	// A flagged lot cannot be split
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.SetFlag(ctx, "lot_7", true, "moisture damage")
+	})
+	err = ctx.Submit("Org4MSP", func() error {
+		return s.MergeLots(ctx, "lot_8", "", []string{"lot_6", "lot_7"}, 800)
+	})
+	checkErr(t, err, "the asset lot_7 is flagged")
+
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonYarn(ctx, true, before, []string{"lot_6"}, "", "cottonyarn_9", false, "", "Texas", 450, 30)
+	})
+
+	// The yarn is made from the bale of its lot only
+	upstream, err := WalkUpstream(ctx, "cottonyarn_9")
+	checkErr(t, err, "")
+	if !contains(traceIDs(upstream), "cottonbale_3") || contains(traceIDs(upstream), "cottonbale_6") {
+		t.Errorf("unexpected upstream trace %v", traceIDs(upstream))
+	}
+	// The genealogy of the bale runs through the split lot to the yarn
+	downstream, err := s.TraceDownstream(ctx, "cottonbale_3")
+	checkErr(t, err, "")
+	for _, id := range []string{"lot_5", "lot_6", "cottonyarn_9"} {
+		if !contains(traceIDs(downstream), id) {
+			t.Errorf("expected the downstream trace to reach %s, got %v", id, traceIDs(downstream))
+		}
+	}
+	downstream, err = s.TraceDownstream(ctx, "lot_5")
+	checkErr(t, err, "")
+	if !contains(traceIDs(downstream), "cottonyarn_9") || contains(traceIDs(downstream), "cottonbale_3") {
+		t.Errorf("unexpected downstream trace of the split lot %v", traceIDs(downstream))
+	}
+}
+
+func TestBackfillLotMembership(t *testing.T) {
+	s, ctx := newSplittableLedger(t)
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.SplitLot(ctx, "lot_5", []LotPortion{{Content: []string{"cottonbale_3"}, ID: "lot_6", TotalWeight: 500}, {Content: []string{"cottonbale_6"}, ID: "lot_7", TotalWeight: 300}})
+	})
+	submit(t, ctx, "Org4MSP", func() error { return s.ArchiveAsset(ctx, "lot_7", "split off by mistake") })
+	// Drop every lot membership key, as on a ledger created before they existed
+	for _, key := range ctx.Stub().Keys() {
+		if objectType, _, err := ctx.Stub().SplitCompositeKey(key); err == nil && objectType == lotMembershipIndex {
+			ctx.Stub().Seed(key, nil)
+		}
+	}
+
+	err := ctx.Submit("Org2MSP", func() error {
+		_, err := s.BackfillLotMembership(ctx, "", 0)
+		return err
+	})
+	checkErr(t, err, "page size must be between")
+
+	// The backfill is invoked page by page, each page resuming where the previous one stopped
+	var lotIDs []string
+	startKey := ""
+	for pages := 1; ; pages++ {
+		var result *LotMembershipBackfill
+		submit(t, ctx, "Org2MSP", func() (err error) {
+			result, err = s.BackfillLotMembership(ctx, startKey, 2)
+			return err
+		})
+		lotIDs = append(lotIDs, result.LotIDs...)
+		if !result.HasMore {
+			break
+		}
+		if pages > 5 || result.NextStartKey <= startKey {
+			t.Fatalf("the backfill does not progress past %q", result.NextStartKey)
+		}
+		startKey = result.NextStartKey
+	}
+	if contains(lotIDs, "lot_5") || contains(lotIDs, "lot_7") || !contains(lotIDs, "lot_6") {
+		t.Errorf("expected the retired lot_5 and the archived lot_7 to be skipped, got %v", lotIDs)
+	}
+
+	// The bale of the split lot points to its successor, while the bale of the archived lot stays released
+	checkErr(t, SPEC_NoDuplicateAssetInState(ctx, "lot_9", []string{"cottonbale_3"}), "already stored in lot lot_6")
+	checkErr(t, SPEC_NoDuplicateAssetInState(ctx, "lot_9", []string{"cottonbale_6"}), "")
+}
+
+func TestLotConsumption(t *testing.T) {
+	s, ctx := newSplittableLedger(t)
+	spin := func(id string, drawnWeights []float32, totalWeight float32, content ...string) error {
+		return ctx.Submit("Org4MSP", func() error {
+			return s.CreateCottonYarnWithDraws(ctx, true, before, content, drawnWeights, "", id, false, "", "Texas", totalWeight, 30)
+		})
+	}
+	checkConsumption := func(lotID string, consumedWeight float32, consumptionState string, consumers ...string) {
+		t.Helper()
+		lot, err := getLot(ctx, lotID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lot.ConsumedWeight != consumedWeight || lot.ConsumptionState != consumptionState || fmt.Sprint(consumerIDs(lot)) != fmt.Sprint(consumers) {
+			t.Errorf("unexpected consumption of lot %s: %v, %s, %v", lotID, lot.ConsumedWeight, lot.ConsumptionState, consumerIDs(lot))
+		}
+	}
+	checkConsumption("lot_1", 900, "available", "cottonyarn_1")
+
+	// By default, the yarn draws its own weight from its lots in order
+	checkErr(t, spin("cottonyarn_a", nil, 150, "lot_1", "lot_5"), "")
+	checkConsumption("lot_1", 1000, "consumed", "cottonyarn_1", "cottonyarn_a")
+	checkConsumption("lot_5", 50, "available", "cottonyarn_a")
+
+	// Drawn weights record the actual input, e.g., to account for spinning losses
+	checkErr(t, spin("cottonyarn_b", []float32{250}, 225, "lot_5"), "")
+	checkConsumption("lot_5", 300, "available", "cottonyarn_a", "cottonyarn_b")
+	yarn, err := getAssetMap(ctx, "cottonyarn_b")
+	checkErr(t, err, "")
+	if yarn["ContentWeight"] != 250.0 || yarn["WeightDifference"] != -10.0 {
+		t.Errorf("unexpected yarn %v", yarn)
+	}
+
+	checkErr(t, spin("cottonyarn_c", []float32{600}, 540, "lot_5"), "cannot draw 600 from lot lot_5 because only 500 of its 800 is still available")
+	checkErr(t, spin("cottonyarn_c", []float32{-1}, 1, "lot_5"), "the weight drawn from lot lot_5 cannot be negative")
+	checkErr(t, spin("cottonyarn_c", []float32{1, 2}, 3, "lot_5"), "expected one drawn weight per content lot, got 2 drawn weights for 1 lots")
+
+	// By default, the yarn draws its own weight, which its lots must still hold
+	checkErr(t, spin("cottonyarn_c", nil, 600, "lot_5"), "the content lots [lot_5] hold only 500 of the 600 drawn")
+	checkErr(t, spin("cottonyarn_c", nil, 500, "lot_5"), "")
+	checkConsumption("lot_5", 800, "consumed", "cottonyarn_a", "cottonyarn_b", "cottonyarn_c")
+
+	// A lot cannot back more yarn once its whole weight was drawn
+	checkErr(t, spin("cottonyarn_d", nil, 1, "lot_5"), "the content lots [lot_5] hold only 0 of the 1 drawn")
+	checkErr(t, spin("cottonyarn_d", []float32{1}, 1, "lot_1"), "lot lot_1 was already consumed by [cottonyarn_1 cottonyarn_a]")
+
+	// A consumed lot is rejected even while another content lot holds the weight drawn, and so is a lot nothing is drawn from
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "", "cottonbale_7", false, "", "Texas", "B", 100)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonbale_", []string{"cottonbale_7"}, "Org5MSP", "", "lot_6", false, "", "Texas", "Org4MSP", 100)
+	})
+	checkErr(t, spin("cottonyarn_d", nil, 1, "lot_5", "lot_6"), "lot lot_5 was already consumed by [cottonyarn_a cottonyarn_b cottonyarn_c]")
+	checkErr(t, spin("cottonyarn_d", []float32{0, 1}, 1, "lot_5", "lot_6"), "lot lot_5 was already consumed by [cottonyarn_a cottonyarn_b cottonyarn_c]")
+	checkErr(t, spin("cottonyarn_d", []float32{0}, 1, "lot_6"), "nothing is drawn from lot lot_6")
+	checkConsumption("lot_6", 0, "available")
+
+	// A lot something was drawn from can no longer be split
+	err = ctx.Submit("Org4MSP", func() error {
+		return s.SplitLot(ctx, "lot_2", []LotPortion{{Content: []string{"cottonyarn_1"}, ID: "lot_8", TotalWeight: 900}, {Content: []string{"cottonyarn_1"}, ID: "lot_9", TotalWeight: 0}})
+	})
+	checkErr(t, err, "cannot draw 900 from lot lot_2 because only 50 of its 900 is still available")
+}
+
+// newTransitLedger adds lot_5, a lot of finishedfabric_2 held by Org5MSP in Vadodara and destined for the registered site of Org6MSP, to the supply chain ledger
+func newTransitLedger(t *testing.T) (*SmartContract, *chaincodetest.TransactionContext) {
+	t.Helper()
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateFinishedFabric(ctx, true, before, []string{"lot_3"}, "finishedfabric_2", "", false, 50, "", "Vadodara, Gujarat, India", 40, 1.5)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateLot(ctx, before, "finishedfabric_", []string{"finishedfabric_2"}, "Ashulia, Bangladesh", "", "lot_5", false, "", "Vadodara, Gujarat, India", "Org5MSP", 40)
+	})
+	return s, ctx
+}
+
+func TestDispatchLot(t *testing.T) {
+	dispatch := func(lotID string, receiverID string, dispatchedAt time.Time) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.DispatchLot(ctx, dispatchedAt, lotID, receiverID)
+		}
+	}
+	runOn(t, newTransitLedger, []testCase{
+		{"valid", "Org5MSP", dispatch("lot_5", "Org6MSP", issued), ""},
+		{"wrong prefix", "Org5MSP", dispatch("finishedfabric_2", "Org6MSP", issued), "must start with 'lot_'"},
+		{"missing lot", "Org5MSP", dispatch("lot_9", "Org6MSP", issued), "the asset lot_9 does not exist"},
+		{"not the custodian", "Org6MSP", dispatch("lot_5", "Org6MSP", issued), "Allowed organizations: [Org5MSP]"},
+		{"to the custodian", "Org5MSP", dispatch("lot_5", "Org5MSP", issued), "lot lot_5 is already held by Org5MSP"},
+		{"receiver not allowed to own", "Org5MSP", dispatch("lot_5", "Org4MSP", issued), "the proposed owner, Org4MSP, is not allowed to own this asset"},
+		{"receiver without a site", "Org5MSP", dispatch("lot_5", "Org2MSP", issued), "organization Org2MSP has no registered site"},
+		{"destination is not the receiver's site", "Org5MSP", dispatch("lot_5", "Org1MSP", issued), "destination Ashulia, Bangladesh of lot lot_5 does not match the registered site Los Angeles, California, USA of Org1MSP"},
+		{"dispatched before assembly", "Org5MSP", dispatch("lot_5", "Org6MSP", before), "is not after date"},
+		{"dispatched in the future", "Org5MSP", dispatch("lot_5", "Org6MSP", after), "is not after date"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		lot, err := getLot(ctx, "lot_5")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lot.TransitState != "in_transit" || lot.Custodian != "Org5MSP" || lot.ReceiverID != "Org6MSP" || lot.Location != "Vadodara, Gujarat, India" || !lot.DispatchedAt.Equal(issued) {
+			t.Errorf("expected lot_5 to be in transit from Org5MSP to Org6MSP, got %+v", lot)
+		}
+		last := ctx.Stub().LastEvent()
+		event, err := events.Decode(last.EventName, last.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, ok := event.Data.(*events.LotDispatchedData); !ok || data.LotID != "lot_5" || data.SenderID != "Org5MSP" || data.ReceiverID != "Org6MSP" || data.Destination != "Ashulia, Bangladesh" {
+			t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+		}
+
+		// A lot in transit cannot be dispatched again, consumed or split
+		err = ctx.Submit("Org5MSP", func() error { return s.DispatchLot(ctx, issued.Add(time.Hour), "lot_5", "Org6MSP") })
+		checkErr(t, err, "lot lot_5 is in transit to Ashulia, Bangladesh and cannot be used until Org6MSP receives it")
+		err = ctx.Submit("Org6MSP", func() error {
+			return s.CreateCutPart(ctx, true, before, []string{"lot_5"}, "", "cutpart_2", false, "", "Dhaka", "sleeve", 0.2)
+		})
+		checkErr(t, err, "lot lot_5 is in transit")
+		err = ctx.Submit("Org5MSP", func() error {
+			return s.SplitLot(ctx, "lot_5", []LotPortion{{Content: []string{"finishedfabric_2"}, ID: "lot_6", TotalWeight: 40}, {Content: []string{}, ID: "lot_7"}})
+		})
+		checkErr(t, err, "lot lot_5 is in transit")
+	})
+}
+
+func TestReceiveLot(t *testing.T) {
+	newDispatchedLedger := func(t *testing.T) (*SmartContract, *chaincodetest.TransactionContext) {
+		s, ctx := newTransitLedger(t)
+		submit(t, ctx, "Org5MSP", func() error { return s.DispatchLot(ctx, issued, "lot_5", "Org6MSP") })
+		return s, ctx
+	}
+	receive := func(lotID string, receivedAt time.Time) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.ReceiveLot(ctx, lotID, receivedAt)
+		}
+	}
+	arrived := issued.Add(72 * time.Hour)
+	runOn(t, newDispatchedLedger, []testCase{
+		{"valid", "Org6MSP", receive("lot_5", arrived), ""},
+		{"wrong prefix", "Org6MSP", receive("finishedfabric_2", arrived), "must start with 'lot_'"},
+		{"missing lot", "Org6MSP", receive("lot_9", arrived), "the asset lot_9 does not exist"},
+		{"not in transit", "Org6MSP", receive("lot_4", arrived), "lot lot_4 is not in transit"},
+		{"not the receiver", "Org5MSP", receive("lot_5", arrived), "Allowed organizations: [Org6MSP]"},
+		{"received before dispatch", "Org6MSP", receive("lot_5", before), "is not after date"},
+		{"received in the future", "Org6MSP", receive("lot_5", after), "is not after date"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		lot, err := getLot(ctx, "lot_5")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lot.TransitState != "on_site" || lot.Custodian != "Org6MSP" || lot.Location != "Ashulia, Bangladesh" || !lot.ReceivedAt.Equal(arrived) {
+			t.Errorf("expected lot_5 to be held by Org6MSP in Ashulia, got %+v", lot)
+		}
+		// Receipt moves the lot but not its ownership
+		if lot.Owner != "Org5MSP" {
+			t.Errorf("expected lot_5 to still be owned by Org5MSP, got %s", lot.Owner)
+		}
+		last := ctx.Stub().LastEvent()
+		event, err := events.Decode(last.EventName, last.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, ok := event.Data.(*events.LotReceivedData); !ok || data.LotID != "lot_5" || data.ReceiverID != "Org6MSP" || data.Location != "Ashulia, Bangladesh" {
+			t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+		}
+
+		// The lot can be used again once received
+		err = ctx.Submit("Org6MSP", func() error {
+			return s.CreateCutPart(ctx, true, before, []string{"lot_5"}, "", "cutpart_2", false, "", "Dhaka", "sleeve", 0.2)
+		})
+		checkErr(t, err, "")
+	})
+}
+
+func TestLotCustody(t *testing.T) {
+	s, ctx := newTransitLedger(t)
+	cut := func() error {
+		return ctx.Submit("Org6MSP", func() error {
+			return s.CreateCutPart(ctx, true, before, []string{"lot_5"}, "", "cutpart_2", false, "", "Dhaka", "sleeve", 0.2)
+		})
+	}
+
+	// A lot can only be used by the organization holding it, not by its destination before it arrives
+	checkErr(t, cut(), "lot lot_5 is held by Org5MSP and cannot be used by Org6MSP")
+	submit(t, ctx, "Org5MSP", func() error { return s.DispatchLot(ctx, issued, "lot_5", "Org6MSP") })
+	submit(t, ctx, "Org6MSP", func() error { return s.ReceiveLot(ctx, "lot_5", issued.Add(time.Hour)) })
+	checkErr(t, cut(), "")
+}
+
+func TestUpdateLotOwnerCustody(t *testing.T) {
+	s, ctx := newTransitLedger(t)
+	custody := func(custodian string, location string, transitState string) {
+		t.Helper()
+		lot, err := getLot(ctx, "lot_5")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lot.Custodian != custodian || lot.Location != location || lot.TransitState != transitState {
+			t.Errorf("expected lot_5 to be %s with %s at %s, got %s with %s at %s", transitState, custodian, location, lot.TransitState, lot.Custodian, lot.Location)
+		}
+	}
+	before, err := getLot(ctx, "lot_5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Selling a lot does not move it, it stays with its custodian until it is dispatched and received
+	submit(t, ctx, "Org6MSP", func() error { return s.UpdateLotOwner(ctx, "lot_5", "Org6MSP") })
+	custody(before.Custodian, before.Location, before.TransitState)
+	submit(t, ctx, "Org5MSP", func() error { return s.DispatchLot(ctx, issued, "lot_5", "Org6MSP") })
+	custody("Org5MSP", before.Location, "in_transit")
+	submit(t, ctx, "Org6MSP", func() error { return s.ReceiveLot(ctx, "lot_5", issued.Add(time.Hour)) })
+	custody("Org6MSP", "Ashulia, Bangladesh", "on_site")
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// yieldTolerance is the largest difference between the actual and the expected yield of a stage, lot or asset before it is reported as outside tolerance
+const yieldTolerance float32 = 0.05
+
+// productionStage is a step of the supply chain that turns assets of one or more input types into assets of the output type
+type productionStage struct {
+	InputPrefixes []string // ID prefixes of the assets consumed by the stage
+	Name          string   // name under which the yield policy holds the expected yield of the stage
+	OutputPrefix  string   // ID prefix of the assets produced by the stage
+}
+
+// productionStages are the stages of the supply chain in production order
+var productionStages = []*productionStage{
+	{InputPrefixes: []string{"cottonbale_"}, Name: "bale-to-yarn", OutputPrefix: "cottonyarn_"},
+	{InputPrefixes: []string{"cottonyarn_"}, Name: "yarn-to-greige", OutputPrefix: "unfinishedfabric_"},
+	{InputPrefixes: []string{"unfinishedfabric_"}, Name: "greige-to-finished", OutputPrefix: "finishedfabric_"},
+	{InputPrefixes: []string{"finishedfabric_"}, Name: "finished-to-cutparts", OutputPrefix: "cutpart_"},
+	{InputPrefixes: []string{"cutpart_", "button_"}, Name: "cutparts-and-buttons-to-garment", OutputPrefix: "assembledgarment_"},
+}
+
+// MassBalanceReport compares the weight going into and coming out of each production stage with the expected yield of the stage
+type MassBalanceReport struct {
+	Lots           []*LotMassBalance   `json:"Lots"`           // one entry per lot weight was drawn from, sorted by lot ID
+	OutOfTolerance []string            `json:"OutOfTolerance"` // names of the stages and IDs of the lots whose actual yield is outside tolerance
+	RootID         string              `json:"RootID"`         // shipment or garment the report is limited to, empty for the whole channel
+	Stages         []*StageMassBalance `json:"Stages"`         // in production order
+	YieldTolerance float32             `json:"YieldTolerance"`
+}
+
+// StageMassBalance is the mass balance of a production stage, summed over every asset the stage produced
+type StageMassBalance struct {
+	ActualYield             float32  `json:"ActualYield"` // OutputWeight divided by InputWeight
+	ExpectedYield           float32  `json:"ExpectedYield"`
+	InputWeight             float32  `json:"InputWeight"` // sum of the input weights of the outputs
+	IsWithinTolerance       bool     `json:"IsWithinTolerance"`
+	Name                    string   `json:"Name"`
+	OutputCount             int      `json:"OutputCount"`
+	OutputWeight            float32  `json:"OutputWeight"`            // sum of the TotalWeight of the outputs
+	OutputsOutsideTolerance []string `json:"OutputsOutsideTolerance"` // IDs of the outputs whose own yield is outside tolerance
+}
+
+// LotMassBalance is the mass balance of the input weight a lot supplied. Each output is credited with the share of its TotalWeight made from the lot
+type LotMassBalance struct {
+	ActualYield       float32 `json:"ActualYield"`
+	ExpectedYield     float32 `json:"ExpectedYield"`
+	InputWeight       float32 `json:"InputWeight"` // input weight the lot supplied to the outputs
+	IsWithinTolerance bool    `json:"IsWithinTolerance"`
+	LotID             string  `json:"LotID"`
+	OutputWeight      float32 `json:"OutputWeight"`
+	Stage             string  `json:"Stage"`
+}
+
+// massBalanceAsset holds the fields of an asset needed to compute mass balances
+type massBalanceAsset struct {
+	AssetIDPrefix    string            `json:"AssetIDPrefix"`
+	Consumptions     []*LotConsumption `json:"Consumptions"`
+	ContentWeight    float32           `json:"ContentWeight"`
+	ID               string            `json:"ID"`
+	TotalWeight      float32           `json:"TotalWeight"`
+	WeightDifference float32           `json:"WeightDifference"`
+}
+
+// GetMassBalanceReport sums the input and output weights of every production stage and lot and compares their yields with the expected yields of the yield policy in force. The input weight of an asset is the weight it drew from its content lots. The weight of a lot not claimed by reported drawn weights is shared among the assets that drew their own weight from it, in proportion to that weight, and an asset made from other assets than lots takes its ContentWeight as input. An empty rootID reports on the whole channel. Orders are kept on the admin channel, so the report of an order is limited to the upstream trace of the garment, carton, container or bill of lading that ships it. Contains the following specifications: 1) SPEC_IDPrefixOneOf, 2) SPEC_AssetExists
+func (s *SmartContract) GetMassBalanceReport(ctx contractapi.TransactionContextInterface, rootID string) (*MassBalanceReport, error) {
+	var assets []*massBalanceAsset
+	var err error
+	if rootID == "" {
+		assets, err = getAllMassBalanceAssets(ctx)
+	} else {
+		// Ensure the rootID belongs to an assembled garment, carton, container or bill of lading
+		if err := SPEC_IDPrefixOneOf(rootID, "assembledgarment_", "carton_", "container_", "billoflading_"); err != nil {
+			return nil, err
+		}
+		// Ensure the asset exists
+		if err := SPEC_AssetExists(ctx, rootID); err != nil {
+			return nil, err
+		}
+		assets, err = getTracedMassBalanceAssets(ctx, rootID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	yieldPolicy, err := ReadYieldPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Attribute the input weight each lot supplied to each asset made from it
+	lotInputs := map[string][]*LotConsumption{}
+	inputWeights := map[string]float32{}
+	for _, asset := range assets {
+		if !strings.HasPrefix(asset.ID, "lot_") {
+			continue
+		}
+		lotInputs[asset.ID] = getLotInputs(asset)
+		for _, input := range lotInputs[asset.ID] {
+			inputWeights[input.AssetID] += input.Weight
+		}
+	}
+
+	report := &MassBalanceReport{
+		Lots:           []*LotMassBalance{},
+		OutOfTolerance: []string{},
+		RootID:         rootID,
+		Stages:         []*StageMassBalance{},
+		YieldTolerance: yieldTolerance,
+	}
+	outputs := map[string]*massBalanceAsset{}
+	for _, stage := range productionStages {
+		expectedYield := yieldPolicy.ExpectedYields[stage.Name]
+		stageBalance := &StageMassBalance{
+			ExpectedYield:           expectedYield,
+			Name:                    stage.Name,
+			OutputsOutsideTolerance: []string{},
+		}
+		for _, asset := range assets {
+			if !strings.HasPrefix(asset.ID, stage.OutputPrefix) {
+				continue
+			}
+			outputs[asset.ID] = asset
+			// An asset that drew nothing from lots, e.g., a garment, takes its content weight as input
+			inputWeight, ok := inputWeights[asset.ID]
+			if !ok {
+				inputWeight = asset.ContentWeight
+				inputWeights[asset.ID] = inputWeight
+			}
+			stageBalance.InputWeight += inputWeight
+			stageBalance.OutputCount++
+			stageBalance.OutputWeight += asset.TotalWeight
+			if !isWithinYieldTolerance(getYield(inputWeight, asset.TotalWeight), expectedYield) {
+				stageBalance.OutputsOutsideTolerance = append(stageBalance.OutputsOutsideTolerance, asset.ID)
+			}
+		}
+		stageBalance.ActualYield = getYield(stageBalance.InputWeight, stageBalance.OutputWeight)
+		// A stage that produced nothing has nothing outside tolerance
+		stageBalance.IsWithinTolerance = stageBalance.OutputCount == 0 || isWithinYieldTolerance(stageBalance.ActualYield, expectedYield)
+		if !stageBalance.IsWithinTolerance {
+			report.OutOfTolerance = append(report.OutOfTolerance, stage.Name)
+		}
+		report.Stages = append(report.Stages, stageBalance)
+	}
+
+	for _, asset := range assets {
+		if !strings.HasPrefix(asset.ID, "lot_") {
+			continue
+		}
+		stage := getStageConsuming(asset.AssetIDPrefix)
+		if stage == nil {
+			continue
+		}
+		expectedYield := yieldPolicy.ExpectedYields[stage.Name]
+		lotBalance := &LotMassBalance{ExpectedYield: expectedYield, LotID: asset.ID, Stage: stage.Name}
+		for _, input := range lotInputs[asset.ID] {
+			// Weight drawn by outputs outside the trace of the root is left out
+			output, ok := outputs[input.AssetID]
+			if !ok || inputWeights[output.ID] == 0 {
+				continue
+			}
+			lotBalance.InputWeight += input.Weight
+			lotBalance.OutputWeight += output.TotalWeight * input.Weight / inputWeights[output.ID]
+		}
+		if lotBalance.InputWeight == 0 {
+			continue
+		}
+		lotBalance.ActualYield = getYield(lotBalance.InputWeight, lotBalance.OutputWeight)
+		lotBalance.IsWithinTolerance = isWithinYieldTolerance(lotBalance.ActualYield, expectedYield)
+		if !lotBalance.IsWithinTolerance {
+			report.OutOfTolerance = append(report.OutOfTolerance, asset.ID)
+		}
+		report.Lots = append(report.Lots, lotBalance)
+	}
+	sort.Slice(report.Lots, func(i, j int) bool { return report.Lots[i].LotID < report.Lots[j].LotID })
+
+	return report, nil
+}
+
+// getLotInputs returns the input weight the lot supplied to each asset made from it, in the order the assets drew from it. A reported drawn weight is the input itself, while the weight of the lot not claimed by reported drawn weights is shared among the assets that drew their own weight, which leaves their losses out
+func getLotInputs(lot *massBalanceAsset) []*LotConsumption {
+	var reportedWeight, defaultWeight float32
+	for _, consumption := range lot.Consumptions {
+		if consumption.IsDefaultDraw {
+			defaultWeight += consumption.Weight
+		} else {
+			reportedWeight += consumption.Weight
+		}
+	}
+	inputs := []*LotConsumption{}
+	for _, consumption := range lot.Consumptions {
+		input := &LotConsumption{AssetID: consumption.AssetID, Weight: consumption.Weight}
+		if consumption.IsDefaultDraw {
+			input.Weight = consumption.Weight * (lot.TotalWeight - reportedWeight) / defaultWeight
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs
+}
+
+// getAllMassBalanceAssets reads every asset of the world state that was not archived
+func getAllMassBalanceAssets(ctx contractapi.TransactionContextInterface) ([]*massBalanceAsset, error) {
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+	assets := []*massBalanceAsset{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if archivedIDs[queryResponse.Key] {
+			continue
+		}
+		var asset massBalanceAsset
+		if err := json.Unmarshal(queryResponse.Value, &asset); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal asset %s: %v", queryResponse.Key, err)
+		}
+		asset.ID = queryResponse.Key
+		assets = append(assets, &asset)
+	}
+	return assets, nil
+}
+
+// getTracedMassBalanceAssets reads every asset of the upstream trace of rootID
+func getTracedMassBalanceAssets(ctx contractapi.TransactionContextInterface, rootID string) ([]*massBalanceAsset, error) {
+	trace, err := WalkUpstream(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	assets := []*massBalanceAsset{}
+	for _, node := range trace.Nodes {
+		assetJSON, err := json.Marshal(node.Asset)
+		if err != nil {
+			return nil, err
+		}
+		var asset massBalanceAsset
+		if err := json.Unmarshal(assetJSON, &asset); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal asset %s: %v", node.ID, err)
+		}
+		asset.ID = node.ID
+		assets = append(assets, &asset)
+	}
+	return assets, nil
+}
+
+// getStage returns the production stage with the given name, or nil if there is none
+func getStage(name string) *productionStage {
+	for _, stage := range productionStages {
+		if stage.Name == name {
+			return stage
+		}
+	}
+	return nil
+}
+
+// getStageConsuming returns the stage that consumes assets with the given ID prefix, or nil if no stage does
+func getStageConsuming(assetIDPrefix string) *productionStage {
+	for _, stage := range productionStages {
+		for _, inputPrefix := range stage.InputPrefixes {
+			if inputPrefix == assetIDPrefix {
+				return stage
+			}
+		}
+	}
+	return nil
+}
+
+// getYield returns the output weight per unit of input weight, or 0 if there is no input
+func getYield(inputWeight, outputWeight float32) float32 {
+	if inputWeight == 0 {
+		return 0
+	}
+	return outputWeight / inputWeight
+}
+
+// isWithinYieldTolerance reports whether the actual yield is within yieldTolerance of the expected yield
+func isWithinYieldTolerance(actualYield, expectedYield float32) bool {
+	difference := actualYield - expectedYield
+	return difference >= -yieldTolerance && difference <= yieldTolerance
+}
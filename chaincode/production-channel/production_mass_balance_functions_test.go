@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+// newYieldLedger returns a ledger whose stages draw realistic weights from their lots, plus a second cut part, cutpart_2, that wastes half of the fabric drawn for it
+func newYieldLedger(t *testing.T) (*SmartContract, *chaincodetest.TransactionContext) {
+	t.Helper()
+	s := &SmartContract{}
+	ctx := chaincodetest.NewTransactionContext("production", "Org4MSP")
+	deployAdminChaincode(ctx)
+	dispatched := before.Add(time.Hour)
+	received := dispatched.Add(time.Hour)
+
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "", "cottonbale_1", false, "", "Texas", "A", 480)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "", "cottonbale_2", false, "", "Texas", "A", 520)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonbale_", []string{"cottonbale_1", "cottonbale_2"}, "Org4MSP", "", "lot_1", false, "", "Texas", "Org4MSP", 1000)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonYarnWithDraws(ctx, true, before, []string{"lot_1"}, []float32{1000}, "", "cottonyarn_1", false, "", "Texas", 850, 30)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonyarn_", []string{"cottonyarn_1"}, "Vadodara, Gujarat, India", "", "lot_2", false, "", "Texas", "Org4MSP", 850)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.DispatchLot(ctx, dispatched, "lot_2", "Org5MSP")
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.ReceiveLot(ctx, "lot_2", received)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateUnfinishedFabricWithDraws(ctx, true, before, []string{"lot_2"}, []float32{850}, "", "unfinishedfabric_1", false, "", "Karachi", 1000, 765, 1.5)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateLot(ctx, before, "unfinishedfabric_", []string{"unfinishedfabric_1"}, "Org5MSP", "", "lot_3", false, "", "Karachi", "Org5MSP", 765)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateFinishedFabricWithDraws(ctx, true, before, []string{"lot_3"}, []float32{765}, "finishedfabric_1", "", false, 980, "", "Karachi", 720, 1.5)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateLot(ctx, before, "finishedfabric_", []string{"finishedfabric_1"}, "Ashulia, Bangladesh", "", "lot_4", false, "", "Karachi", "Org5MSP", 720)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.DispatchLot(ctx, dispatched, "lot_4", "Org6MSP")
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.ReceiveLot(ctx, "lot_4", received)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCutPartWithDraws(ctx, true, before, []string{"lot_4"}, []float32{0.36}, "", "cutpart_1", false, "", "Dhaka", "front panel", 0.3)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCutPartWithDraws(ctx, true, before, []string{"lot_4"}, []float32{0.6}, "", "cutpart_2", false, "", "Dhaka", "back panel", 0.3)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateButton(ctx, true, before, "", "button_1", false, "", "Dhaka", 0.01)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateAssembledGarment(ctx, true, before, []string{"button_1"}, []string{"cutpart_1"}, "", "assembledgarment_1", false, "", "Dhaka", 0.31)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCarton(ctx, true, before, []string{"assembledgarment_1"}, "Org1MSP", "", "carton_1", false, "", "order_1", "Dhaka", "Org6MSP", 0.5)
+	})
+	return s, ctx
+}
+
+func TestGetMassBalanceReport(t *testing.T) {
+	s, ctx := newYieldLedger(t)
+	getReport := func(rootID string) (*MassBalanceReport, error) {
+		var report *MassBalanceReport
+		err := ctx.Evaluate("Org3MSP", func() (err error) {
+			report, err = s.GetMassBalanceReport(ctx, rootID)
+			return err
+		})
+		return report, err
+	}
+	checkStage := func(t *testing.T, report *MassBalanceReport, name string, inputWeight, outputWeight float32, isWithinTolerance bool, outputsOutsideTolerance []string) {
+		t.Helper()
+		for _, stage := range report.Stages {
+			if stage.Name != name {
+				continue
+			}
+			if fmt.Sprintf("%.2f %.2f", stage.InputWeight, stage.OutputWeight) != fmt.Sprintf("%.2f %.2f", inputWeight, outputWeight) || stage.IsWithinTolerance != isWithinTolerance || fmt.Sprint(stage.OutputsOutsideTolerance) != fmt.Sprint(outputsOutsideTolerance) {
+				t.Errorf("unexpected stage %+v", stage)
+			}
+			return
+		}
+		t.Errorf("stage %s missing from the report", name)
+	}
+
+	t.Run("whole channel", func(t *testing.T) {
+		report, err := getReport("")
+		checkErr(t, err, "")
+		if len(report.Stages) != 5 {
+			t.Fatalf("expected 5 stages, got %d", len(report.Stages))
+		}
+		checkStage(t, report, "bale-to-yarn", 1000, 850, true, []string{})
+		checkStage(t, report, "yarn-to-greige", 850, 765, true, []string{})
+		checkStage(t, report, "greige-to-finished", 765, 720, true, []string{})
+		checkStage(t, report, "finished-to-cutparts", 0.96, 0.6, false, []string{"cutpart_2"})
+		checkStage(t, report, "cutparts-and-buttons-to-garment", 0.31, 0.31, true, []string{})
+		if fmt.Sprint(report.OutOfTolerance) != "[finished-to-cutparts lot_4]" {
+			t.Errorf("expected finished-to-cutparts and lot_4 to be out of tolerance, got %v", report.OutOfTolerance)
+		}
+		var lotIDs []string
+		for _, lot := range report.Lots {
+			lotIDs = append(lotIDs, lot.LotID)
+		}
+		if fmt.Sprint(lotIDs) != "[lot_1 lot_2 lot_3 lot_4]" {
+			t.Errorf("expected the four lots drawn from, got %v", lotIDs)
+		}
+		if lot := report.Lots[3]; lot.Stage != "finished-to-cutparts" || fmt.Sprintf("%.3f", lot.ActualYield) != "0.625" || lot.IsWithinTolerance {
+			t.Errorf("unexpected lot %+v", lot)
+		}
+	})
+
+	t.Run("order shipped in a carton", func(t *testing.T) {
+		report, err := getReport("carton_1")
+		checkErr(t, err, "")
+		checkStage(t, report, "finished-to-cutparts", 0.36, 0.3, true, []string{})
+		if len(report.OutOfTolerance) != 0 || report.RootID != "carton_1" {
+			t.Errorf("expected nothing out of tolerance for carton_1, got %v", report.OutOfTolerance)
+		}
+		if lot := report.Lots[3]; fmt.Sprintf("%.2f %.2f", lot.InputWeight, lot.OutputWeight) != "0.36 0.30" || !lot.IsWithinTolerance {
+			t.Errorf("expected only cutpart_1 to be credited to lot_4, got %+v", lot)
+		}
+	})
+
+	t.Run("invalid root", func(t *testing.T) {
+		_, err := getReport("lot_1")
+		checkErr(t, err, "must start with one of")
+		_, err = getReport("carton_9")
+		checkErr(t, err, "the asset carton_9 does not exist")
+	})
+}
+
+func TestGetMassBalanceReportOfInitScript(t *testing.T) {
+	s, ctx := newInitScriptLedger(t)
+	getReport := func() *MassBalanceReport {
+		t.Helper()
+		var report *MassBalanceReport
+		err := ctx.Evaluate("Org3MSP", func() (err error) {
+			report, err = s.GetMassBalanceReport(ctx, "")
+			return err
+		})
+		checkErr(t, err, "")
+		return report
+	}
+	checkStages := func(report *MassBalanceReport, want string) {
+		t.Helper()
+		var stages []string
+		for _, stage := range report.Stages {
+			stages = append(stages, fmt.Sprintf("%s %.2f>%.2f %.3f %v %d", stage.Name, stage.InputWeight, stage.OutputWeight, stage.ActualYield, stage.IsWithinTolerance, len(stage.OutputsOutsideTolerance)))
+		}
+		if got := strings.Join(stages, "\n"); got != want {
+			t.Errorf("expected stages\n%s\ngot\n%s", want, got)
+		}
+	}
+
+	// Every asset of the script draws its own weight, so each lot's weight is shared among the assets made from it. The
+	// script spins a quarter of its bale and cuts the fabric with little waste, which the planned yields flag
+	report := getReport()
+	checkStages(report, strings.Join([]string{
+		"bale-to-yarn 480.00>123.07 0.256 false 310",
+		"yarn-to-greige 123.07>117.18 0.952 false 4",
+		"greige-to-finished 117.18>111.32 0.950 true 0",
+		"finished-to-cutparts 111.32>109.20 0.981 false 1200",
+		"cutparts-and-buttons-to-garment 112.50>110.80 0.985 true 0",
+	}, "\n"))
+	if got := fmt.Sprint(report.OutOfTolerance); got != "[bale-to-yarn yarn-to-greige finished-to-cutparts lot_1 lot_2 lot_6 lot_7]" {
+		t.Errorf("unexpected stages and lots out of tolerance %s", got)
+	}
+
+	// Once the measured yields of the mills are configured, only the unspun cotton stands out
+	yieldPolicy := defaultYieldPolicy()
+	yieldPolicy.ExpectedYields["yarn-to-greige"] = 0.95
+	yieldPolicy.ExpectedYields["finished-to-cutparts"] = 0.98
+	yieldPolicyJSON, err := json.Marshal(yieldPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	submit(t, ctx, "Org2MSP", func() error { return s.SetYieldPolicy(ctx, string(yieldPolicyJSON)) })
+	report = getReport()
+	if got := fmt.Sprint(report.OutOfTolerance); got != "[bale-to-yarn lot_1]" {
+		t.Errorf("expected only bale-to-yarn and lot_1 out of tolerance, got %s", got)
+	}
+	if stage := report.Stages[3]; stage.ExpectedYield != 0.98 || !stage.IsWithinTolerance {
+		t.Errorf("expected the configured yield to be applied, got %+v", stage)
+	}
+}
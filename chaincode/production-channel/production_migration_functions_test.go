@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/schema"
+)
+
+func TestMigrateAssets(t *testing.T) {
+	migrate := func(fromVersion, toVersion, pageSize int) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			_, err := s.MigrateAssets(ctx, fromVersion, toVersion, "", pageSize)
+			return err
+		}
+	}
+	run(t, []testCase{
+		{"retailer", "Org1MSP", migrate(0, schemaVersion, 10), ""},
+		{"agent", "Org2MSP", migrate(0, schemaVersion, 10), ""},
+		{"raw material supplier", "Org4MSP", migrate(0, schemaVersion, 10), "not invoked by an allowed organization"},
+		{"unknown version", "Org1MSP", migrate(0, schemaVersion+1, 10), "is newer than the current schema version"},
+		{"empty page", "Org1MSP", migrate(0, schemaVersion, 0), "the page size must be positive"},
+	}, nil)
+
+	s, ctx := newSupplyChainLedger(t)
+	// cottonbale_0 and unfinishedfabric_0 were written before schema versions and units were recorded
+	ctx.Stub().Seed("cottonbale_0", []byte(`{"ID":"cottonbale_0","QualityGrade":"A","TotalWeight":480}`))
+	ctx.Stub().Seed("unfinishedfabric_0", []byte(`{"ID":"unfinishedfabric_0","Length":50,"TotalWeight":45,"Width":1.5}`))
+
+	var pending []*schema.PendingRecord
+	err := ctx.Evaluate("Org3MSP", func() (err error) {
+		pending, err = s.GetAssetsPendingMigration(ctx)
+		return err
+	})
+	checkErr(t, err, "")
+	if len(pending) != 2 || pending[0].ID != "cottonbale_0" || pending[1].DocType != "unfinishedfabric_" {
+		t.Fatalf("expected the two legacy assets to be pending, got %+v", pending)
+	}
+
+	submit(t, ctx, "Org1MSP", func() error { return migrate(0, schemaVersion, 10)(s, ctx) })
+	bale, err := getAssetMap(ctx, "cottonbale_0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fabric, err := getAssetMap(ctx, "unfinishedfabric_0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprint(bale["DocType"], bale["SchemaVersion"], bale["WeightUnit"], bale["QualityGrade"], fabric["DocType"], fabric["WeightUnit"], fabric["LengthUnit"], fabric["WidthUnit"])
+	if want := fmt.Sprint("cottonbale_", schemaVersion, "lb", "A", "unfinishedfabric_", "lb", "yd", "in"); got != want {
+		t.Errorf("expected the migrated assets to read %s, got %s", want, got)
+	}
+
+	// Assets created by the contract are written under the current schema version
+	yarn, err := getAssetMap(ctx, "cottonyarn_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if yarn["DocType"] != "cottonyarn_" || yarn["SchemaVersion"] != float64(schemaVersion) {
+		t.Errorf("unexpected schema of cottonyarn_1: %v %v", yarn["DocType"], yarn["SchemaVersion"])
+	}
+}
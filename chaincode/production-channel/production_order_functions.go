@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// adminChannel and adminChaincode locate the orders, which are kept on the admin channel. Only the peers that have joined the admin channel can read them, so the transactions that do must be endorsed by those peers
+const (
+	adminChannel   = "admin-channel"
+	adminChaincode = "admin"
+)
+
+// Order is the part of an order on the admin channel that the production channel relies on
+type Order struct {
+	CreatorID  string `json:"CreatorID"` // the retailer that placed the order
+	DocType    string `json:"DocType"`
+	ID         string `json:"ID"`
+	ReceiverID string `json:"ReceiverID"` // the buying agent the order was placed with
+}
+
+// ReadOrder retrieves an order from the admin channel with a read-only cross-channel query, whose result is not recorded in the read set of the transaction
+func ReadOrder(ctx contractapi.TransactionContextInterface, orderID string) (*Order, error) {
+	response := ctx.GetStub().InvokeChaincode(adminChaincode, [][]byte{[]byte("GetAsset"), []byte(orderID)}, adminChannel)
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to read order %s from %s: %s", orderID, adminChannel, response.Message)
+	}
+	var order Order
+	err := json.Unmarshal(response.Payload, &order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal order %s: %v", orderID, err)
+	}
+	if order.DocType != "order_" {
+		return nil, fmt.Errorf("asset %s on %s is not an order", orderID, adminChannel)
+	}
+	return &order, nil
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+)
+
+// The *Page types are single pages of the paginated GetAll* queries. Bookmark is passed to the next call to continue and is empty after the last page. Archived assets are left out after the page is read, so a page may hold fewer assets than the page size while more remain, and only an empty Bookmark marks the last page
+
+// AssetPage is a page of GetAllAssetsWithPagination
+type AssetPage struct {
+	Assets              []map[string]interface{} `json:"Assets"`
+	Bookmark            string                   `json:"Bookmark"`
+	FetchedRecordsCount int                      `json:"FetchedRecordsCount"`
+}
+
+// CottonBalePage is a page of GetAllCottonBalesWithPagination
+type CottonBalePage struct {
+	Bookmark            string        `json:"Bookmark"`
+	CottonBales         []*CottonBale `json:"CottonBales"`
+	FetchedRecordsCount int           `json:"FetchedRecordsCount"`
+}
+
+// LotPage is a page of GetAllLotsWithPagination
+type LotPage struct {
+	Bookmark            string `json:"Bookmark"`
+	FetchedRecordsCount int    `json:"FetchedRecordsCount"`
+	Lots                []*Lot `json:"Lots"`
+}
+
+// CottonYarnPage is a page of GetAllCottonYarnsWithPagination
+type CottonYarnPage struct {
+	Bookmark            string        `json:"Bookmark"`
+	CottonYarns         []*CottonYarn `json:"CottonYarns"`
+	FetchedRecordsCount int           `json:"FetchedRecordsCount"`
+}
+
+// UnfinishedFabricPage is a page of GetAllUnfinishedFabricsWithPagination
+type UnfinishedFabricPage struct {
+	Bookmark            string              `json:"Bookmark"`
+	FetchedRecordsCount int                 `json:"FetchedRecordsCount"`
+	UnfinishedFabrics   []*UnfinishedFabric `json:"UnfinishedFabrics"`
+}
+
+// FinishedFabricPage is a page of GetAllFinishedFabricsWithPagination
+type FinishedFabricPage struct {
+	Bookmark            string            `json:"Bookmark"`
+	FetchedRecordsCount int               `json:"FetchedRecordsCount"`
+	FinishedFabrics     []*FinishedFabric `json:"FinishedFabrics"`
+}
+
+// CutPartPage is a page of GetAllCutPartsWithPagination
+type CutPartPage struct {
+	Bookmark            string     `json:"Bookmark"`
+	CutParts            []*CutPart `json:"CutParts"`
+	FetchedRecordsCount int        `json:"FetchedRecordsCount"`
+}
+
+// ButtonPage is a page of GetAllButtonsWithPagination
+type ButtonPage struct {
+	Bookmark            string    `json:"Bookmark"`
+	Buttons             []*Button `json:"Buttons"`
+	FetchedRecordsCount int       `json:"FetchedRecordsCount"`
+}
+
+// AssembledGarmentPage is a page of GetAllAssembledGarmentsWithPagination
+type AssembledGarmentPage struct {
+	AssembledGarments   []*AssembledGarment `json:"AssembledGarments"`
+	Bookmark            string              `json:"Bookmark"`
+	FetchedRecordsCount int                 `json:"FetchedRecordsCount"`
+}
+
+// CartonPage is a page of GetAllCartonsWithPagination
+type CartonPage struct {
+	Bookmark            string    `json:"Bookmark"`
+	Cartons             []*Carton `json:"Cartons"`
+	FetchedRecordsCount int       `json:"FetchedRecordsCount"`
+}
+
+// ContainerPage is a page of GetAllContainersWithPagination
+type ContainerPage struct {
+	Bookmark            string       `json:"Bookmark"`
+	Containers          []*Container `json:"Containers"`
+	FetchedRecordsCount int          `json:"FetchedRecordsCount"`
+}
+
+// BillOfLadingPage is a page of GetAllBillsOfLadingWithPagination
+type BillOfLadingPage struct {
+	BillsOfLading       []*BillOfLading `json:"BillsOfLading"`
+	Bookmark            string          `json:"Bookmark"`
+	FetchedRecordsCount int             `json:"FetchedRecordsCount"`
+}
+
+// GetAllAssetsWithPagination retrieves a page of at most pageSize records from the world state, starting from the bookmark and leaving out archived assets. Like every paginated query, it can only be evaluated, not submitted. Contains the following specifications: 1) SPEC_IsValidPageSize
+func (s *SmartContract) GetAllAssetsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AssetPage, error) {
+	// Ensure that the page size is within bounds
+	if err := SPEC_IsValidPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	return getAllAssetsWithPagination(ctx, false, pageSize, bookmark)
+}
+
+// GetAllAssetsWithPaginationIncludingArchived retrieves a page of at most pageSize records from the world state, starting from the bookmark and including archived assets. Contains the following specifications: 1) SPEC_IsValidPageSize
+func (s *SmartContract) GetAllAssetsWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AssetPage, error) {
+	// Ensure that the page size is within bounds
+	if err := SPEC_IsValidPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	return getAllAssetsWithPagination(ctx, true, pageSize, bookmark)
+}
+
+// getAllAssetsWithPagination retrieves a page of at most pageSize records from the world state, starting from the bookmark and leaving out archived assets unless includeArchived is true
+func getAllAssetsWithPagination(ctx contractapi.TransactionContextInterface, includeArchived bool, pageSize int32, bookmark string) (*AssetPage, error) {
+	// Retrieve the IDs of the archived assets
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByRangeWithPagination("", "", pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state by range: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	page := &AssetPage{Assets: []map[string]interface{}{}, Bookmark: metadata.GetBookmark()}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through results: %v", err)
+		}
+		// Leave out archived assets unless they are requested
+		if !includeArchived && archivedIDs[queryResponse.Key] {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		page.Assets = append(page.Assets, record)
+	}
+	page.FetchedRecordsCount = len(page.Assets)
+	return page, nil
+}
+
+// GetAllAssetsOfTypeWithPagination is a helper function not meant for direct client invocation as it retrieves a page of at most pageSize records of the asset type with the specified ID prefix, starting from the bookmark, reading only the partition of the type index that holds them and leaving out archived assets, but returns the page as an interface. Contains the following specifications: 1) SPEC_IsValidPageSize
+func (s *SmartContract) GetAllAssetsOfTypeWithPagination(ctx contractapi.TransactionContextInterface, assetIDPrefix string, pageSize int32, bookmark string) (interface{}, error) {
+	// Ensure that the page size is within bounds
+	if err := SPEC_IsValidPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	return getAllAssetsOfTypeWithPagination(ctx, assetIDPrefix, false, pageSize, bookmark)
+}
+
+// GetAllAssetsOfTypeWithPaginationIncludingArchived is a helper function not meant for direct client invocation as it retrieves a page of at most pageSize records of the asset type with the specified ID prefix, starting from the bookmark and including archived assets, but returns the page as an interface. Contains the following specifications: 1) SPEC_IsValidPageSize
+func (s *SmartContract) GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, assetIDPrefix string, pageSize int32, bookmark string) (interface{}, error) {
+	// Ensure that the page size is within bounds
+	if err := SPEC_IsValidPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	return getAllAssetsOfTypeWithPagination(ctx, assetIDPrefix, true, pageSize, bookmark)
+}
+
+// getAllAssetsOfTypeWithPagination retrieves a page of at most pageSize records of the asset type with the specified ID prefix from the type index, starting from the bookmark and leaving out archived assets unless includeArchived is true
+func getAllAssetsOfTypeWithPagination(ctx contractapi.TransactionContextInterface, assetIDPrefix string, includeArchived bool, pageSize int32, bookmark string) (interface{}, error) {
+	// Retrieve the IDs of the archived assets
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Retrieve the IDs of a page of assets of the type
+	ids, nextBookmark, err := index.IDsOfTypeWithPagination(ctx, assetIDPrefix, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	assetJSONs := [][]byte{}
+	for _, id := range ids {
+		// Leave out archived assets unless they are requested
+		if !includeArchived && archivedIDs[id] {
+			continue
+		}
+		assetJSON, err := ctx.GetStub().GetState(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read asset %s: %v", id, err)
+		}
+		assetJSONs = append(assetJSONs, assetJSON)
+	}
+
+	switch assetIDPrefix {
+	case "cottonbale_":
+		page := &CottonBalePage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.CottonBales)
+	case "lot_":
+		page := &LotPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.Lots)
+	case "cottonyarn_":
+		page := &CottonYarnPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.CottonYarns)
+	case "unfinishedfabric_":
+		page := &UnfinishedFabricPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.UnfinishedFabrics)
+	case "finishedfabric_":
+		page := &FinishedFabricPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.FinishedFabrics)
+	case "cutpart_":
+		page := &CutPartPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.CutParts)
+	case "button_":
+		page := &ButtonPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.Buttons)
+	case "assembledgarment_":
+		page := &AssembledGarmentPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.AssembledGarments)
+	case "carton_":
+		page := &CartonPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.Cartons)
+	case "container_":
+		page := &ContainerPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.Containers)
+	case "billoflading_":
+		page := &BillOfLadingPage{Bookmark: nextBookmark, FetchedRecordsCount: len(assetJSONs)}
+		return page, unmarshalRecords(assetJSONs, &page.BillsOfLading)
+	default:
+		return nil, fmt.Errorf("invalid record type: %s", assetIDPrefix)
+	}
+}
+
+// GetAllCottonBalesWithPagination retrieves a page of cotton bales, leaving out archived ones
+func (s *SmartContract) GetAllCottonBalesWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CottonBalePage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "cottonbale_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*CottonBalePage), nil
+}
+
+// GetAllCottonBalesWithPaginationIncludingArchived retrieves a page of cotton bales, including archived ones
+func (s *SmartContract) GetAllCottonBalesWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CottonBalePage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "cottonbale_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*CottonBalePage), nil
+}
+
+// GetAllLotsWithPagination retrieves a page of lots, leaving out archived ones
+func (s *SmartContract) GetAllLotsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*LotPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "lot_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*LotPage), nil
+}
+
+// GetAllLotsWithPaginationIncludingArchived retrieves a page of lots, including archived ones
+func (s *SmartContract) GetAllLotsWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*LotPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "lot_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*LotPage), nil
+}
+
+// GetAllCottonYarnsWithPagination retrieves a page of cotton yarns, leaving out archived ones
+func (s *SmartContract) GetAllCottonYarnsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CottonYarnPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "cottonyarn_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*CottonYarnPage), nil
+}
+
+// GetAllCottonYarnsWithPaginationIncludingArchived retrieves a page of cotton yarns, including archived ones
+func (s *SmartContract) GetAllCottonYarnsWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CottonYarnPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "cottonyarn_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*CottonYarnPage), nil
+}
+
+// GetAllUnfinishedFabricsWithPagination retrieves a page of unfinished fabrics, leaving out archived ones
+func (s *SmartContract) GetAllUnfinishedFabricsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*UnfinishedFabricPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "unfinishedfabric_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*UnfinishedFabricPage), nil
+}
+
+// GetAllUnfinishedFabricsWithPaginationIncludingArchived retrieves a page of unfinished fabrics, including archived ones
+func (s *SmartContract) GetAllUnfinishedFabricsWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*UnfinishedFabricPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "unfinishedfabric_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*UnfinishedFabricPage), nil
+}
+
+// GetAllFinishedFabricsWithPagination retrieves a page of finished fabrics, leaving out archived ones
+func (s *SmartContract) GetAllFinishedFabricsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*FinishedFabricPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "finishedfabric_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*FinishedFabricPage), nil
+}
+
+// GetAllFinishedFabricsWithPaginationIncludingArchived retrieves a page of finished fabrics, including archived ones
+func (s *SmartContract) GetAllFinishedFabricsWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*FinishedFabricPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "finishedfabric_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*FinishedFabricPage), nil
+}
+
+// GetAllCutPartsWithPagination retrieves a page of cut parts, leaving out archived ones
+func (s *SmartContract) GetAllCutPartsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CutPartPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "cutpart_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*CutPartPage), nil
+}
+
+// GetAllCutPartsWithPaginationIncludingArchived retrieves a page of cut parts, including archived ones
+func (s *SmartContract) GetAllCutPartsWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CutPartPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "cutpart_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*CutPartPage), nil
+}
+
+// GetAllButtonsWithPagination retrieves a page of buttons, leaving out archived ones
+func (s *SmartContract) GetAllButtonsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*ButtonPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "button_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*ButtonPage), nil
+}
+
+// GetAllButtonsWithPaginationIncludingArchived retrieves a page of buttons, including archived ones
+func (s *SmartContract) GetAllButtonsWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*ButtonPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "button_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*ButtonPage), nil
+}
+
+// GetAllAssembledGarmentsWithPagination retrieves a page of assembled garments, leaving out archived ones
+func (s *SmartContract) GetAllAssembledGarmentsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AssembledGarmentPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "assembledgarment_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*AssembledGarmentPage), nil
+}
+
+// GetAllAssembledGarmentsWithPaginationIncludingArchived retrieves a page of assembled garments, including archived ones
+func (s *SmartContract) GetAllAssembledGarmentsWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*AssembledGarmentPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "assembledgarment_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*AssembledGarmentPage), nil
+}
+
+// GetAllCartonsWithPagination retrieves a page of cartons, leaving out archived ones
+func (s *SmartContract) GetAllCartonsWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CartonPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "carton_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*CartonPage), nil
+}
+
+// GetAllCartonsWithPaginationIncludingArchived retrieves a page of cartons, including archived ones
+func (s *SmartContract) GetAllCartonsWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*CartonPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "carton_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*CartonPage), nil
+}
+
+// GetAllContainersWithPagination retrieves a page of containers, leaving out archived ones
+func (s *SmartContract) GetAllContainersWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*ContainerPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "container_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*ContainerPage), nil
+}
+
+// GetAllContainersWithPaginationIncludingArchived retrieves a page of containers, including archived ones
+func (s *SmartContract) GetAllContainersWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*ContainerPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "container_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*ContainerPage), nil
+}
+
+// GetAllBillsOfLadingWithPagination retrieves a page of bills of lading, leaving out archived ones
+func (s *SmartContract) GetAllBillsOfLadingWithPagination(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*BillOfLadingPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPagination(ctx, "billoflading_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*BillOfLadingPage), nil
+}
+
+// GetAllBillsOfLadingWithPaginationIncludingArchived retrieves a page of bills of lading, including archived ones
+func (s *SmartContract) GetAllBillsOfLadingWithPaginationIncludingArchived(ctx contractapi.TransactionContextInterface, pageSize int32, bookmark string) (*BillOfLadingPage, error) {
+	page, err := s.GetAllAssetsOfTypeWithPaginationIncludingArchived(ctx, "billoflading_", pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return page.(*BillOfLadingPage), nil
+}
+
+// unmarshalRecords unmarshals the JSON of each record into the slice records points to, which is empty rather than nil when there are no records
+func unmarshalRecords(recordJSONs [][]byte, records interface{}) error {
+	arrayJSON := append(append([]byte("["), bytes.Join(recordJSONs, []byte(","))...), ']')
+	if err := json.Unmarshal(arrayJSON, records); err != nil {
+		return fmt.Errorf("failed to unmarshal records: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetAllLotsWithPagination(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org4MSP", func() error { return s.ArchiveAsset(ctx, "cottonbale_3", "wrong weight") })
+
+	// Page through the four lots of the fixture, three at a time
+	var pages []*LotPage
+	bookmark := ""
+	for {
+		var page *LotPage
+		err := ctx.Evaluate("Org1MSP", func() (err error) {
+			page, err = s.GetAllLotsWithPagination(ctx, 3, bookmark)
+			return err
+		})
+		checkErr(t, err, "")
+		pages = append(pages, page)
+		if bookmark = page.Bookmark; bookmark == "" || len(pages) > 2 {
+			break
+		}
+	}
+	if len(pages) != 2 || pages[0].FetchedRecordsCount != 3 || pages[1].FetchedRecordsCount != 1 {
+		t.Fatalf("expected pages of 3 and 1 lots, got %+v", pages)
+	}
+	if pages[0].Lots[0].ID != "lot_1" || pages[1].Lots[0].ID != "lot_4" {
+		t.Errorf("expected the lots in key order, got %s first and %s last", pages[0].Lots[0].ID, pages[1].Lots[0].ID)
+	}
+
+	// An archived bale is left out of its page unless requested
+	cottonBales, err := s.GetAllCottonBalesWithPagination(ctx, 10, "")
+	checkErr(t, err, "")
+	if cottonBales.FetchedRecordsCount != 4 || cottonBales.Bookmark != "" {
+		t.Errorf("expected the 4 cotton bales that were not archived on one page, got %d", cottonBales.FetchedRecordsCount)
+	}
+	cottonBales, err = s.GetAllCottonBalesWithPaginationIncludingArchived(ctx, 10, "")
+	checkErr(t, err, "")
+	if cottonBales.FetchedRecordsCount != 5 {
+		t.Errorf("expected the 5 cotton bales on request, got %d", cottonBales.FetchedRecordsCount)
+	}
+
+	// A type without assets returns an empty page rather than a null list
+	billsOfLading, err := s.GetAllBillsOfLadingWithPagination(ctx, 10, "")
+	checkErr(t, err, "")
+	if billsOfLading.BillsOfLading == nil || billsOfLading.FetchedRecordsCount != 0 {
+		t.Errorf("expected an empty page, got %+v", billsOfLading)
+	}
+
+	_, err = s.GetAllLotsWithPagination(ctx, 0, "")
+	checkErr(t, err, "the page size must be between 1 and 1000, got 0")
+	_, err = s.GetAllLotsWithPaginationIncludingArchived(ctx, 1001, "")
+	checkErr(t, err, "the page size must be between 1 and 1000, got 1001")
+	_, err = s.GetAllAssetsOfTypeWithPagination(ctx, "rolepolicy", 10, "")
+	checkErr(t, err, "invalid record type: rolepolicy")
+}
+
+func TestGetAllAssetsWithPagination(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	count, err := s.GetAllAssetsCountIncludingArchived(ctx)
+	checkErr(t, err, "")
+
+	// Paging through every record returns each one once
+	seen := map[string]bool{}
+	bookmark := ""
+	for i := 0; i <= count; i++ {
+		page, err := s.GetAllAssetsWithPaginationIncludingArchived(ctx, 4, bookmark)
+		checkErr(t, err, "")
+		for _, asset := range page.Assets {
+			id := fmt.Sprint(asset["ID"])
+			if seen[id] {
+				t.Errorf("%s was returned twice", id)
+			}
+			seen[id] = true
+		}
+		if bookmark = page.Bookmark; bookmark == "" {
+			break
+		}
+	}
+	if len(seen) != count {
+		t.Errorf("expected %d records, got %d", count, len(seen))
+	}
+}
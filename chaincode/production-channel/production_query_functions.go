@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/richquery"
+)
+
+// QueryAssets retrieves a page of at most pageSize assets matching a CouchDB selector, starting from the bookmark and leaving out archived assets, e.g., the lots flagged in Vadodara in July 2024 with {"DocType": "lot_", "IsFlagged": true, "Origin": "Vadodara, Gujarat, India", "AssemblyDate": {"$gte": "2024-07-01T00:00:00Z", "$lt": "2024-08-01T00:00:00Z"}}. It needs a CouchDB state database and can only be evaluated, not submitted. Contains the following specifications: 1) SPEC_IsValidSelector, 2) SPEC_IsValidPageSize
+func (s *SmartContract) QueryAssets(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int32, bookmark string) (*AssetPage, error) {
+	// Ensure that the selector is a JSON object with at least one condition
+	if err := SPEC_IsValidSelector(selectorJSON); err != nil {
+		return nil, err
+	}
+	// Ensure that the page size is within bounds
+	if err := SPEC_IsValidPageSize(pageSize); err != nil {
+		return nil, err
+	}
+	// Retrieve the IDs of the archived assets
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, nextBookmark, err := richquery.Records(ctx, selectorJSON, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AssetPage{Assets: []map[string]interface{}{}, Bookmark: nextBookmark}
+	for _, record := range records {
+		// Leave out archived assets
+		if archivedIDs[record.Key] {
+			continue
+		}
+		var asset map[string]interface{}
+		if err := json.Unmarshal(record.Value, &asset); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal asset %s: %v", record.Key, err)
+		}
+		page.Assets = append(page.Assets, asset)
+	}
+	page.FetchedRecordsCount = len(page.Assets)
+	return page, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestQueryAssets(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org4MSP", func() error { return s.SetFlag(ctx, "lot_2", true, "moisture damage") })
+	submit(t, ctx, "Org4MSP", func() error { return s.SetFlag(ctx, "lot_3", true, "moisture damage") })
+	submit(t, ctx, "Org4MSP", func() error { return s.ArchiveAsset(ctx, "cottonbale_3", "wrong weight") })
+
+	// The flagged lots from Karachi assembled last month
+	selector := fmt.Sprintf(`{"DocType": "lot_", "IsFlagged": true, "Origin": "Karachi", "AssemblyDate": {"$gte": %q, "$lt": %q}}`,
+		before.AddDate(0, 0, -1).Format(time.RFC3339), before.AddDate(0, 0, 1).Format(time.RFC3339))
+	page, err := s.QueryAssets(ctx, selector, 10, "")
+	checkErr(t, err, "")
+	if page.FetchedRecordsCount != 1 || page.Assets[0]["ID"] != "lot_3" || page.Bookmark != "" {
+		t.Errorf("expected only lot_3, got %+v", page)
+	}
+
+	// Archived assets are left out
+	page, err = s.QueryAssets(ctx, `{"DocType": "cottonbale_", "Origin": {"$in": ["Texas"]}}`, 10, "")
+	checkErr(t, err, "")
+	ids := []interface{}{}
+	for _, asset := range page.Assets {
+		ids = append(ids, asset["ID"])
+	}
+	if fmt.Sprint(ids) != "[cottonbale_1 cottonbale_2 cottonbale_4 cottonbale_5]" {
+		t.Errorf("expected the cotton bales that were not archived, got %v", ids)
+	}
+
+	_, err = s.QueryAssets(ctx, `["lot_"]`, 10, "")
+	checkErr(t, err, "the selector must be a JSON object")
+	_, err = s.QueryAssets(ctx, `{"Origin": {"$regex": "^K"}}`, 10, "")
+	checkErr(t, err, "unsupported selector operator $regex")
+}
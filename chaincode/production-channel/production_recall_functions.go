@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// InitiateRecall records a Recall and flags the root asset together with every asset derived from it, from lots and yarn through garments, cartons and containers. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole, 2) SPEC_IDPrefixOneOf, 3) SPEC_AssetExists, 4) SPEC_IsValidFlag, 5) SPEC_IsNewAsset
+func (s *SmartContract) InitiateRecall(ctx contractapi.TransactionContextInterface, rootID string, reason string) (*Recall, error) {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Ensure that the function is invoked by an organization allowed to recall assets
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Recall, "recall_"); err != nil {
+		return nil, err
+	}
+	// Ensure the root is a production asset
+	if err := SPEC_IDPrefixOneOf(rootID, "cottonbale_", "lot_", "cottonyarn_", "unfinishedfabric_", "finishedfabric_", "cutpart_", "button_", "assembledgarment_", "carton_", "container_"); err != nil {
+		return nil, err
+	}
+	// Ensure the root exists
+	if err := SPEC_AssetExists(ctx, rootID); err != nil {
+		return nil, err
+	}
+	// Ensure that a reason is provided, since it becomes the flagReason of every affected asset
+	if err := SPEC_IsValidFlag(true, reason); err != nil {
+		return nil, err
+	}
+	// Derive the recall ID from the transaction so that it is unique and identical on every endorsing peer
+	recallID := "recall_" + ctx.GetStub().GetTxID()
+	if err := SPEC_IsNewAsset(ctx, recallID); err != nil {
+		return nil, err
+	}
+
+	// Find the root and every asset derived from it
+	trace, err := WalkDownstream(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Flag every affected asset, keeping any earlier flagReason
+	flagReason := fmt.Sprintf("%s: %s", recallID, reason)
+	affectedAssets := []string{}
+	for _, node := range trace.Nodes {
+		asset := node.Asset
+		if isFlagged, _ := asset["IsFlagged"].(bool); isFlagged {
+			if existingReason, _ := asset["FlagReason"].(string); existingReason != "" && existingReason != "N/A" {
+				asset["FlagReason"] = existingReason + "; " + flagReason
+			} else {
+				asset["FlagReason"] = flagReason
+			}
+		} else {
+			asset["IsFlagged"] = true
+			asset["FlagReason"] = flagReason
+		}
+		asset["UpdatedAt"] = txTimestamp
+
+		updatedAssetJSON, err := json.Marshal(asset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON: %v", err)
+		}
+		if err := ctx.GetStub().PutState(node.ID, updatedAssetJSON); err != nil {
+			return nil, fmt.Errorf("failed to flag asset %s: %v", node.ID, err)
+		}
+		affectedAssets = append(affectedAssets, node.ID)
+	}
+
+	// Retrieve the invoking organization's MSP ID
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+
+	recall := Recall{
+		AffectedAssets: affectedAssets,
+		CreatorID:      clientMSPID,
+		DocType:        "recall_",
+		ID:             recallID,
+		InitiatedAt:    txTimestamp,
+		Reason:         reason,
+		RootID:         rootID,
+		SchemaVersion:  schemaVersion,
+		Status:         "open",
+		UpdatedAt:      txTimestamp,
+	}
+
+	// Convert recall to JSON
+	recallJSON, err := json.Marshal(recall)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the recall to the world state
+	if err := ctx.GetStub().PutState(recallID, recallJSON); err != nil {
+		return nil, err
+	}
+	// Index the recall by type
+	if err := index.PutType(ctx, recallID); err != nil {
+		return nil, err
+	}
+	// Emit the RecallInitiated event
+	if err := events.Emit(ctx, events.RecallInitiated, events.RecallInitiatedData{AffectedAssetCount: len(affectedAssets), Reason: reason, RecallID: recallID, RootID: rootID}); err != nil {
+		return nil, err
+	}
+	return &recall, nil
+}
+
+// GetRecall retrieves a recall with a specific ID from the world state. Contains the following specifications: 1) SPEC_IDPrefix
+func (s *SmartContract) GetRecall(ctx contractapi.TransactionContextInterface, recallID string) (*Recall, error) {
+	// Ensure the id begins with "recall_"
+	if err := SPEC_IDPrefix(recallID, "recall_"); err != nil {
+		return nil, err
+	}
+	recallJSON, err := ctx.GetStub().GetState(recallID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recall: %v", err)
+	}
+	if recallJSON == nil {
+		return nil, fmt.Errorf("recall %s does not exist", recallID)
+	}
+	var recall Recall
+	err = json.Unmarshal(recallJSON, &recall)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal recall: %v", err)
+	}
+	return &recall, nil
+}
+
+// CloseRecall closes an open recall with the given resolution. The affected assets stay flagged until SetFlag clears them one by one. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole, 2) SPEC_Chronology
+func (s *SmartContract) CloseRecall(ctx contractapi.TransactionContextInterface, recallID string, resolution string) error {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to recall assets
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Recall, "recall_"); err != nil {
+		return err
+	}
+	recall, err := s.GetRecall(ctx, recallID)
+	if err != nil {
+		return err
+	}
+	if recall.Status != "open" {
+		return fmt.Errorf("recall %s is not open, its status is '%s'", recallID, recall.Status)
+	}
+	if len(resolution) == 0 {
+		return fmt.Errorf("resolution must be provided to close recall %s", recallID)
+	}
+
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	recall.ClosedAt = txTimestamp
+	recall.Resolution = resolution
+	recall.Status = "closed"
+	recall.UpdatedAt = txTimestamp
+
+	// Ensure that the dates are in chronological order
+	if err := SPEC_Chronology(recall.InitiatedAt, recall.ClosedAt); err != nil {
+		return err
+	}
+
+	// Marshal the updated recall and put it back in the world state
+	updatedRecallJSON, err := json.Marshal(recall)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated recall: %v", err)
+	}
+	if err := ctx.GetStub().PutState(recallID, updatedRecallJSON); err != nil {
+		return err
+	}
+	// Emit the RecallClosed event
+	return events.Emit(ctx, events.RecallClosed, events.RecallClosedData{RecallID: recallID, Resolution: resolution})
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func TestInitiateRecall(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org6MSP", func() error { return s.SetFlag(ctx, "carton_1", true, "damaged") })
+	var recall *Recall
+	submit(t, ctx, "Org1MSP", func() (err error) {
+		recall, err = s.InitiateRecall(ctx, "cottonbale_1", "contaminated")
+		return err
+	})
+
+	// Every asset derived from the bale is affected, but not the other bale of its lot nor the button of the garment
+	affected := append([]string{}, recall.AffectedAssets...)
+	sort.Strings(affected)
+	want := "[assembledgarment_1 carton_1 container_1 cottonbale_1 cottonyarn_1 cutpart_1 finishedfabric_1 lot_1 lot_2 lot_3 lot_4 unfinishedfabric_1]"
+	if fmt.Sprint(affected) != want {
+		t.Errorf("expected the affected assets %s, got %v", want, affected)
+	}
+	flagReason := recall.ID + ": contaminated"
+	for _, id := range recall.AffectedAssets {
+		asset, err := getAssetMap(ctx, id)
+		checkErr(t, err, "")
+		wantReason := flagReason
+		// An earlier flagReason is kept
+		if id == "carton_1" {
+			wantReason = "damaged; " + flagReason
+		}
+		if asset["IsFlagged"] != true || asset["FlagReason"] != wantReason {
+			t.Errorf("expected %s to be flagged with %q, got %v with %q", id, wantReason, asset["IsFlagged"], asset["FlagReason"])
+		}
+	}
+	for _, id := range []string{"cottonbale_2", "button_1"} {
+		asset, err := getAssetMap(ctx, id)
+		checkErr(t, err, "")
+		if asset["IsFlagged"] != false {
+			t.Errorf("expected %s not to be flagged, got %q", id, asset["FlagReason"])
+		}
+	}
+
+	// The recall is recorded as open
+	recorded, err := s.GetRecall(ctx, recall.ID)
+	checkErr(t, err, "")
+	if recorded.Status != "open" || recorded.RootID != "cottonbale_1" || recorded.Reason != "contaminated" || recorded.CreatorID != "Org1MSP" || len(recorded.AffectedAssets) != 12 {
+		t.Errorf("unexpected recall %+v", recorded)
+	}
+	_, err = s.GetRecall(ctx, "lot_1")
+	checkErr(t, err, "must start with 'recall_'")
+	_, err = s.GetRecall(ctx, "recall_unknown")
+	checkErr(t, err, "recall recall_unknown does not exist")
+
+	_, err = s.InitiateRecall(ctx, "cottonbale_1", "")
+	checkErr(t, err, "flagReason must be provided")
+}
+
+func TestCloseRecall(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	var recall *Recall
+	submit(t, ctx, "Org1MSP", func() (err error) {
+		recall, err = s.InitiateRecall(ctx, "lot_3", "forced labour")
+		return err
+	})
+
+	err := ctx.Submit("Org3MSP", func() error { return s.CloseRecall(ctx, recall.ID, "") })
+	checkErr(t, err, "resolution must be provided")
+	submit(t, ctx, "Org3MSP", func() error { return s.CloseRecall(ctx, recall.ID, "supplier audited") })
+	closed, err := s.GetRecall(ctx, recall.ID)
+	checkErr(t, err, "")
+	if closed.Status != "closed" || closed.Resolution != "supplier audited" || !closed.ClosedAt.After(closed.InitiatedAt) {
+		t.Errorf("unexpected closed recall %+v", closed)
+	}
+
+	// A closed recall cannot be closed again
+	err = ctx.Submit("Org1MSP", func() error { return s.CloseRecall(ctx, recall.ID, "closed twice") })
+	checkErr(t, err, "is not open, its status is 'closed'")
+
+	// The affected assets stay flagged
+	lot, err := getAssetMap(ctx, "lot_3")
+	checkErr(t, err, "")
+	if lot["IsFlagged"] != true {
+		t.Errorf("expected lot_3 to stay flagged after the recall is closed")
+	}
+}
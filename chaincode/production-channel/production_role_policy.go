@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// defaultRolePolicy returns the role policy in force until SetRolePolicy stores one. Lots of each asset type can be created and transferred by the retailer, the agent and the producer of the asset type, and can be owned by them and by the next organization in the supply chain. Cartons and containers are owned by the full-package supplier until they are handed over to the retailer, and containers are moved by the full-package supplier or a carrier and delivered to the retailer. Lots are dispatched to the registered site of the receiving organization. The retailer and the agent govern the policy, migrate assets to new schema versions and backfill the type and owner indexes
+func defaultRolePolicy() *policy.RolePolicy {
+	producer := []string{policy.Create, policy.CreateLot, policy.TransferLot, policy.OwnLot}
+	receiver := []string{policy.TransferLot, policy.OwnLot}
+	buyer := []string{policy.CreateLot, policy.TransferLot, policy.OwnLot}
+	buyerOperations := func() map[string][]string {
+		return map[string][]string{
+			"assembledgarment_": buyer,
+			"button_":           buyer,
+			"cottonbale_":       buyer,
+			"cottonyarn_":       buyer,
+			"cutpart_":          buyer,
+			"finishedfabric_":   buyer,
+			"lot_":              {policy.Backfill},
+			"recall_":           {policy.Recall},
+			"unfinishedfabric_": buyer,
+			policy.AssetType:    {policy.Update, policy.Migrate, policy.Backfill},
+		}
+	}
+	retailerOperations := buyerOperations()
+	retailerOperations["carton_"] = []string{policy.Own}
+	retailerOperations["container_"] = []string{policy.Deliver, policy.Own}
+
+	return &policy.RolePolicy{
+		Roles: map[string]*policy.Role{
+			policy.Agent: {
+				MSPIDs:     []string{"Org2MSP"},
+				Operations: buyerOperations(),
+			},
+			policy.Auditor: {
+				MSPIDs:     []string{"Org3MSP"},
+				Operations: map[string][]string{"recall_": {policy.Recall}},
+			},
+			policy.Carrier: {
+				MSPIDs: []string{},
+				Operations: map[string][]string{
+					"container_": {policy.Depart, policy.Arrive},
+				},
+			},
+			policy.FullPackageSupplier: {
+				MSPIDs: []string{"Org6MSP"},
+				Operations: map[string][]string{
+					"assembledgarment_": producer,
+					"billoflading_":     {policy.Create},
+					"button_":           producer,
+					"carton_":           {policy.Create, policy.Own},
+					"container_":        {policy.Create, policy.Depart, policy.Arrive, policy.Own},
+					"cutpart_":          producer,
+					"finishedfabric_":   receiver,
+				},
+			},
+			policy.RawMaterialSupplier: {
+				MSPIDs: []string{"Org4MSP"},
+				Operations: map[string][]string{
+					"cottonbale_": producer,
+					"cottonyarn_": producer,
+				},
+			},
+			policy.Retailer: {
+				MSPIDs:     []string{"Org1MSP"},
+				Operations: retailerOperations,
+			},
+			policy.TextileMill: {
+				MSPIDs: []string{"Org5MSP"},
+				Operations: map[string][]string{
+					"cottonyarn_":       receiver,
+					"finishedfabric_":   producer,
+					"unfinishedfabric_": producer,
+				},
+			},
+		},
+		Sites: map[string]string{
+			"Org1MSP": "Los Angeles, California, USA",
+			"Org4MSP": "Vadodara, Gujarat, India",
+			"Org5MSP": "Vadodara, Gujarat, India",
+			"Org6MSP": "Ashulia, Bangladesh",
+		},
+	}
+}
+
+// ReadRolePolicy returns the role policy in force
+func ReadRolePolicy(ctx contractapi.TransactionContextInterface) (*policy.RolePolicy, error) {
+	return policy.Read(ctx, defaultRolePolicy())
+}
+
+// GetRolePolicy retrieves the role policy in force, which is the default policy (Version 0) until SetRolePolicy is first invoked
+func (s *SmartContract) GetRolePolicy(ctx contractapi.TransactionContextInterface) (*policy.RolePolicy, error) {
+	return ReadRolePolicy(ctx)
+}
+
+// SetRolePolicy replaces the role policy in force, e.g., to add a second textile mill to the textile-mill role. UpdatedAt, UpdatedBy and Version are set by the contract. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole, 2) SPEC_IsValidRolePolicy
+func (s *SmartContract) SetRolePolicy(ctx contractapi.TransactionContextInterface, rolePolicyJSON string) error {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to update the role policy
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Update, policy.AssetType); err != nil {
+		return err
+	}
+
+	var newRolePolicy policy.RolePolicy
+	err = json.Unmarshal([]byte(rolePolicyJSON), &newRolePolicy)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal role policy: %v", err)
+	}
+	// Ensure that the new role policy is well formed and still allows someone to update it
+	if err := SPEC_IsValidRolePolicy(&newRolePolicy); err != nil {
+		return err
+	}
+
+	// Retrieve the invoking organization's MSP ID
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	newRolePolicy.UpdatedAt = txTimestamp
+	newRolePolicy.UpdatedBy = clientMSPID
+	newRolePolicy.Version = rolePolicy.Version + 1
+
+	// Save the role policy to the world state
+	if err := policy.Write(ctx, &newRolePolicy); err != nil {
+		return err
+	}
+	// Emit the RolePolicyUpdated event
+	return events.Emit(ctx, events.RolePolicyUpdated, events.RolePolicyUpdatedData{Roles: newRolePolicy.RoleNames(), Version: newRolePolicy.Version})
+}
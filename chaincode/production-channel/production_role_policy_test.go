@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// rolePolicyJSON returns the default role policy, changed by edit, as JSON
+func rolePolicyJSON(t *testing.T, edit func(rolePolicy *policy.RolePolicy)) string {
+	t.Helper()
+	rolePolicy := defaultRolePolicy()
+	edit(rolePolicy)
+	rolePolicyJSON, err := json.Marshal(rolePolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(rolePolicyJSON)
+}
+
+func TestSetRolePolicy(t *testing.T) {
+	set := func(rolePolicyJSON string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetRolePolicy(ctx, rolePolicyJSON)
+		}
+	}
+	secondTextileMill := rolePolicyJSON(t, func(rolePolicy *policy.RolePolicy) {
+		rolePolicy.Roles[policy.TextileMill].MSPIDs = append(rolePolicy.Roles[policy.TextileMill].MSPIDs, "Org7MSP")
+		rolePolicy.Sites["Org7MSP"] = "Vadodara, Gujarat, India"
+	})
+	lockedOut := rolePolicyJSON(t, func(rolePolicy *policy.RolePolicy) {
+		delete(rolePolicy.Roles[policy.Retailer].Operations, policy.AssetType)
+		delete(rolePolicy.Roles[policy.Agent].Operations, policy.AssetType)
+	})
+	emptyMSPID := rolePolicyJSON(t, func(rolePolicy *policy.RolePolicy) {
+		rolePolicy.Roles[policy.TextileMill].MSPIDs = []string{""}
+	})
+
+	run(t, []testCase{
+		{"agent", "Org2MSP", set(secondTextileMill), ""},
+		{"retailer", "Org1MSP", set(secondTextileMill), ""},
+		{"textile mill", "Org5MSP", set(secondTextileMill), "not invoked by an allowed organization"},
+		{"auditor", "Org3MSP", set(secondTextileMill), "not invoked by an allowed organization"},
+		{"malformed", "Org1MSP", set(`{"Roles":`), "failed to unmarshal role policy"},
+		{"no roles", "Org1MSP", set(`{"Roles":{}}`), "must define at least one role"},
+		{"empty MSP ID", "Org1MSP", set(emptyMSPID), "role textile-mill contains an empty MSP ID"},
+		{"locks out updates", "Org1MSP", set(lockedOut), "must allow at least one organization to update rolepolicy"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		rolePolicy, err := s.GetRolePolicy(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rolePolicy.Version != 1 || !rolePolicy.HasRole("Org7MSP", policy.TextileMill) || rolePolicy.UpdatedAt.IsZero() {
+			t.Errorf("unexpected role policy %+v", rolePolicy)
+		}
+		last := ctx.Stub().LastEvent()
+		event, err := events.Decode(last.EventName, last.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, ok := event.Data.(*events.RolePolicyUpdatedData); !ok || data.Version != 1 || len(data.Roles) != 7 {
+			t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+		}
+
+		// The second textile mill can now receive yarn and produce fabric without a new chaincode
+		submit(t, ctx, "Org5MSP", func() error {
+			return s.DispatchLot(ctx, issued, "lot_2", "Org7MSP")
+		})
+		submit(t, ctx, "Org7MSP", func() error {
+			return s.ReceiveLot(ctx, "lot_2", issued.Add(time.Hour))
+		})
+		err = ctx.Submit("Org7MSP", func() error {
+			return s.CreateUnfinishedFabric(ctx, true, before, []string{"lot_2"}, "", "unfinishedfabric_9", false, "", "Lahore", 50, 45, 1.5)
+		})
+		checkErr(t, err, "")
+		checkCreated(t, ctx, "unfinishedfabric_9", "Org7MSP", "lot_2")
+	})
+}
+
+func TestGetRolePolicy(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	var rolePolicy *policy.RolePolicy
+	err := ctx.Evaluate("Org3MSP", func() (err error) {
+		rolePolicy, err = s.GetRolePolicy(ctx)
+		return err
+	})
+	checkErr(t, err, "")
+	if rolePolicy.Version != 0 || !rolePolicy.HasRole("Org4MSP", policy.RawMaterialSupplier) {
+		t.Fatalf("expected the default role policy, got %+v", rolePolicy)
+	}
+
+	// Revoking a role takes effect on the next transaction, and every update bumps the version
+	withoutRawMaterialSupplier := rolePolicyJSON(t, func(rolePolicy *policy.RolePolicy) {
+		rolePolicy.Roles[policy.RawMaterialSupplier].MSPIDs = []string{}
+	})
+	for i := 0; i < 2; i++ {
+		submit(t, ctx, "Org1MSP", func() error { return s.SetRolePolicy(ctx, withoutRawMaterialSupplier) })
+	}
+	err = ctx.Submit("Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "", "cottonbale_9", false, "", "Texas", "A", 480)
+	})
+	checkErr(t, err, "Organizations allowed to create cottonbale_: []")
+
+	err = ctx.Evaluate("Org3MSP", func() (err error) {
+		rolePolicy, err = s.GetRolePolicy(ctx)
+		return err
+	})
+	checkErr(t, err, "")
+	if rolePolicy.Version != 2 || rolePolicy.UpdatedBy != "Org1MSP" {
+		t.Errorf("expected version 2 updated by Org1MSP, got %+v", rolePolicy)
+	}
+	if _, err := getAssetMap(ctx, "cottonbale_9"); err == nil {
+		t.Error("expected cottonbale_9 not to be created")
+	}
+}
@@ -0,0 +1,564 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// grossWeightTolerance is the largest percentage by which the gross weight of a bill of lading may differ from the total weight of its containers
+const grossWeightTolerance float32 = 5
+
+// CreateBillOfLading issues a new asset (BillOfLading) covering one or more loaded containers. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_LotConsistency, 7) SPEC_NoDuplicateContainerInState, 8) SPEC_ShipmentMatchesContainer, 9) SPEC_WeightWithinTolerance, 10) SPEC_Chronology
+func (s *SmartContract) CreateBillOfLading(ctx contractapi.TransactionContextInterface, consignee string, containers []string, deliveryPlace string, dischargePort string, documentID string, flagReason string, freightTerms string, grossWeight float32, billOfLadingID string, isFlagged bool, issueDate time.Time, loadingPort string, receiptPlace string, sealNumber uint8, shipper string, url string, vessel string) error {
+	// Ensure the id begins with "billoflading_"
+	if err := SPEC_IDPrefix(billOfLadingID, "billoflading_"); err != nil {
+		return err
+	}
+	// Ensure the bill of lading does not already exist
+	if err := SPEC_IsNewAsset(ctx, billOfLadingID); err != nil {
+		return err
+	}
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "billoflading_"); err != nil {
+		return err
+	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	grossWeight = reportingUnits.ConvertWeight(grossWeight)
+	// Ensure that the flagReason is provided if isFlagged is true
+	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
+		return err
+	}
+	// Ensure that each container is listed once
+	if err := SPEC_NoDuplicateAssetInThisLot(containers); err != nil {
+		return err
+	}
+	// Ensure that each container exists
+	if err := SPEC_LotConsistency(ctx, containers, "container_"); err != nil {
+		return err
+	}
+	// Ensure that no container is already shipped under another bill of lading
+	if err := SPEC_NoDuplicateContainerInState(ctx, billOfLadingID, containers); err != nil {
+		return err
+	}
+
+	// Ensure that the vessel and ports are those of every container, and sum the containers' weights
+	var containersWeight float32
+	loadedContainers := []*Container{}
+	for _, containerID := range containers {
+		container, err := getContainer(ctx, containerID)
+		if err != nil {
+			return err
+		}
+		if err := SPEC_ShipmentMatchesContainer(container, vessel, loadingPort, dischargePort); err != nil {
+			return err
+		}
+		containersWeight += container.TotalWeight
+		loadedContainers = append(loadedContainers, container)
+	}
+	// Ensure that the gross weight matches the containers' weight
+	if err := SPEC_WeightWithinTolerance(containersWeight, grossWeight, grossWeightTolerance); err != nil {
+		return err
+	}
+
+	// Retrieve the invoking organization's MSP ID
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	billOfLading := BillOfLading{
+		Consignee:        consignee,
+		Containers:       containers,
+		ContainersWeight: containersWeight,
+		CreatorID:        clientMSPID,
+		DeliveryPlace:    deliveryPlace,
+		DischargePort:    dischargePort,
+		DocType:          "billoflading_",
+		DocumentID:       documentID,
+		FlagReason:       flagReason,
+		FreightTerms:     freightTerms,
+		GrossWeight:      grossWeight,
+		ID:               billOfLadingID,
+		IsFlagged:        isFlagged,
+		IssueDate:        issueDate,
+		LoadingPort:      loadingPort,
+		ReceiptPlace:     receiptPlace,
+		SchemaVersion:    schemaVersion,
+		SealNumber:       sealNumber,
+		Shipper:          shipper,
+		URL:              url,
+		UpdatedAt:        txTimestamp,
+		Vessel:           vessel,
+		WeightDifference: GetPercentageDifference(containersWeight, grossWeight),
+		WeightUnit:       ledgerUnits.Weight,
+	}
+
+	// Ensure that the bill of lading is issued after every container was loaded
+	for _, container := range loadedContainers {
+		if err := SPEC_Chronology(container.LoadedAt, billOfLading.IssueDate, billOfLading.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	// Convert bill of lading to JSON
+	billOfLadingJSON, err := json.Marshal(billOfLading)
+	if err != nil {
+		return err
+	}
+
+	// Save the bill of lading to the world state
+	if err := ctx.GetStub().PutState(billOfLadingID, billOfLadingJSON); err != nil {
+		return err
+	}
+	// Index the bill of lading by type
+	if err := index.PutType(ctx, billOfLadingID); err != nil {
+		return err
+	}
+	// Link each container to this bill of lading
+	if err := PutParentLinks(ctx, billOfLadingID, containers); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: billOfLadingID, AssetType: "billoflading_"})
+}
+
+// GetBillOfLadingByContainer retrieves the bill of lading a container is shipped under. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists
+func (s *SmartContract) GetBillOfLadingByContainer(ctx contractapi.TransactionContextInterface, containerID string) (*BillOfLading, error) {
+	// Ensure the id begins with "container_"
+	if err := SPEC_IDPrefix(containerID, "container_"); err != nil {
+		return nil, err
+	}
+	// Ensure the container exists
+	if err := SPEC_AssetExists(ctx, containerID); err != nil {
+		return nil, err
+	}
+
+	parentIDs, err := GetParentIDs(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, parentID := range parentIDs {
+		if !strings.HasPrefix(parentID, "billoflading_") {
+			continue
+		}
+		billOfLadingJSON, err := ctx.GetStub().GetState(parentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bill of lading %s: %v", parentID, err)
+		}
+		if billOfLadingJSON == nil {
+			return nil, fmt.Errorf("bill of lading %s does not exist", parentID)
+		}
+		var billOfLading BillOfLading
+		err = json.Unmarshal(billOfLadingJSON, &billOfLading)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal bill of lading: %v", err)
+		}
+		return &billOfLading, nil
+	}
+	return nil, fmt.Errorf("container %s is not shipped under any bill of lading", containerID)
+}
+
+// GetContainersByBillOfLading retrieves the containers shipped under a bill of lading. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists
+func (s *SmartContract) GetContainersByBillOfLading(ctx contractapi.TransactionContextInterface, billOfLadingID string) ([]*Container, error) {
+	// Ensure the id begins with "billoflading_"
+	if err := SPEC_IDPrefix(billOfLadingID, "billoflading_"); err != nil {
+		return nil, err
+	}
+	// Ensure the bill of lading exists
+	if err := SPEC_AssetExists(ctx, billOfLadingID); err != nil {
+		return nil, err
+	}
+
+	billOfLadingJSON, err := ctx.GetStub().GetState(billOfLadingID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bill of lading %s: %v", billOfLadingID, err)
+	}
+	var billOfLading BillOfLading
+	err = json.Unmarshal(billOfLadingJSON, &billOfLading)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal bill of lading: %v", err)
+	}
+
+	containers := []*Container{}
+	for _, containerID := range billOfLading.Containers {
+		container, err := getContainer(ctx, containerID)
+		if err != nil {
+			return nil, err
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+// getContainer retrieves a container from the world state
+func getContainer(ctx contractapi.TransactionContextInterface, containerID string) (*Container, error) {
+	containerJSON, err := ctx.GetStub().GetState(containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read container %s: %v", containerID, err)
+	}
+	if containerJSON == nil {
+		return nil, fmt.Errorf("container %s does not exist", containerID)
+	}
+	var container Container
+	err = json.Unmarshal(containerJSON, &container)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal container %s: %v", containerID, err)
+	}
+	return &container, nil
+}
+
+// getCarton reads a carton from the world state
+func getCarton(ctx contractapi.TransactionContextInterface, cartonID string) (*Carton, error) {
+	cartonJSON, err := ctx.GetStub().GetState(cartonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read carton %s: %v", cartonID, err)
+	}
+	if cartonJSON == nil {
+		return nil, fmt.Errorf("carton %s does not exist", cartonID)
+	}
+	var carton Carton
+	err = json.Unmarshal(cartonJSON, &carton)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal carton %s: %v", cartonID, err)
+	}
+	return &carton, nil
+}
+
+// TransferCarton transfers the ownership of a carton that is not loaded in a container, e.g., when it is handed over to its customer. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsNotFlagged, 5) SPEC_IsAllowedToOwn, 6) SPEC_IsCustomer
+func (s *SmartContract) TransferCarton(ctx contractapi.TransactionContextInterface, cartonID string, newOwner string) error {
+	// Ensure the id begins with "carton_"
+	if err := SPEC_IDPrefix(cartonID, "carton_"); err != nil {
+		return err
+	}
+	// Ensure the carton exists
+	if err := SPEC_AssetExists(ctx, cartonID); err != nil {
+		return err
+	}
+	carton, err := getCarton(ctx, cartonID)
+	if err != nil {
+		return err
+	}
+	// Ensure that the carton is transferred by its owner
+	if err := SPEC_IsInvokedByAllowedOrg(ctx, carton.Owner); err != nil {
+		return err
+	}
+	// Ensure that the carton is not flagged
+	if err := SPEC_IsNotFlagged(ctx, cartonID); err != nil {
+		return err
+	}
+	if newOwner == carton.Owner {
+		return fmt.Errorf("carton %s is already owned by %s", cartonID, newOwner)
+	}
+	// A loaded carton changes hands with its container
+	parentIDs, err := GetParentIDs(ctx, cartonID)
+	if err != nil {
+		return err
+	}
+	for _, parentID := range parentIDs {
+		if strings.HasPrefix(parentID, "container_") {
+			return fmt.Errorf("carton %s is loaded in container %s and can only be transferred with it", cartonID, parentID)
+		}
+	}
+
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the new owner is allowed to own cartons
+	if err := SPEC_IsAllowedToOwn(ctx, newOwner, rolePolicy.MSPIDsAllowedTo(policy.Own, "carton_")...); err != nil {
+		return err
+	}
+	// Ensure that a retailer only receives the cartons packed for it
+	if err := SPEC_IsCustomer(rolePolicy, carton, newOwner); err != nil {
+		return err
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	previousOwner := carton.Owner
+	if err := putCartonOwner(ctx, carton, newOwner, txTimestamp); err != nil {
+		return err
+	}
+	// Emit the AssetOwnershipChanged event
+	return events.Emit(ctx, events.AssetOwnershipChanged, events.AssetOwnershipChangedData{AssetID: cartonID, ContentIDs: []string{}, NewOwner: newOwner, PreviousOwner: previousOwner})
+}
+
+// TransferContainer transfers the ownership of a container and of every carton loaded in it, e.g., when the full-package supplier hands a delivered container over to the retailer. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsNotFlagged, 5) SPEC_IsAllowedToOwn, 6) SPEC_IsCustomer
+func (s *SmartContract) TransferContainer(ctx contractapi.TransactionContextInterface, containerID string, newOwner string) error {
+	// Ensure the id begins with "container_"
+	if err := SPEC_IDPrefix(containerID, "container_"); err != nil {
+		return err
+	}
+	// Ensure the container exists
+	if err := SPEC_AssetExists(ctx, containerID); err != nil {
+		return err
+	}
+	container, err := getContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	// Containers created before they had an owner are owned by their creator
+	owner := container.Owner
+	if owner == "" {
+		owner = container.CreatorID
+	}
+	// Ensure that the container is transferred by its owner
+	if err := SPEC_IsInvokedByAllowedOrg(ctx, owner); err != nil {
+		return err
+	}
+	// Ensure that the container is not flagged
+	if err := SPEC_IsNotFlagged(ctx, containerID); err != nil {
+		return err
+	}
+	if newOwner == owner {
+		return fmt.Errorf("container %s is already owned by %s", containerID, newOwner)
+	}
+
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the new owner is allowed to own containers and the cartons in them
+	if err := SPEC_IsAllowedToOwn(ctx, newOwner, rolePolicy.MSPIDsAllowedTo(policy.Own, "container_")...); err != nil {
+		return err
+	}
+	if err := SPEC_IsAllowedToOwn(ctx, newOwner, rolePolicy.MSPIDsAllowedTo(policy.Own, "carton_")...); err != nil {
+		return err
+	}
+	cartons := []*Carton{}
+	for _, cartonID := range container.Content {
+		carton, err := getCarton(ctx, cartonID)
+		if err != nil {
+			return err
+		}
+		// Ensure that a retailer only receives the cartons packed for it
+		if err := SPEC_IsCustomer(rolePolicy, carton, newOwner); err != nil {
+			return err
+		}
+		cartons = append(cartons, carton)
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, carton := range cartons {
+		if err := putCartonOwner(ctx, carton, newOwner, txTimestamp); err != nil {
+			return err
+		}
+	}
+	container.Owner = newOwner
+	container.PreviousOwner = owner
+	container.UpdatedAt = txTimestamp
+	// Convert container to JSON
+	containerJSON, err := json.Marshal(container)
+	if err != nil {
+		return err
+	}
+	// Save the container to the world state
+	if err := ctx.GetStub().PutState(containerID, containerJSON); err != nil {
+		return err
+	}
+	// Move the container to the partition of its new owner
+	if err := index.MoveOwner(ctx, owner, newOwner, containerID); err != nil {
+		return err
+	}
+	// Emit the AssetOwnershipChanged event
+	return events.Emit(ctx, events.AssetOwnershipChanged, events.AssetOwnershipChangedData{AssetID: containerID, ContentIDs: container.Content, NewOwner: newOwner, PreviousOwner: owner})
+}
+
+// putCartonOwner saves a carton with a new owner, keeping its current owner as the previous owner
+func putCartonOwner(ctx contractapi.TransactionContextInterface, carton *Carton, newOwner string, txTimestamp time.Time) error {
+	carton.PreviousOwner = carton.Owner
+	carton.Owner = newOwner
+	carton.UpdatedAt = txTimestamp
+
+	// Convert carton to JSON
+	cartonJSON, err := json.Marshal(carton)
+	if err != nil {
+		return err
+	}
+	// Save the carton to the world state
+	if err := ctx.GetStub().PutState(carton.ID, cartonJSON); err != nil {
+		return err
+	}
+	// Move the carton to the partition of its new owner
+	return index.MoveOwner(ctx, carton.PreviousOwner, newOwner, carton.ID)
+}
+
+// DepartContainer records that a loaded container has left its origin port. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsLegalStatusTransition, 5) SPEC_Chronology
+func (s *SmartContract) DepartContainer(ctx contractapi.TransactionContextInterface, containerID string, departedAt time.Time) error {
+	// Ensure the id begins with "container_"
+	if err := SPEC_IDPrefix(containerID, "container_"); err != nil {
+		return err
+	}
+	// Ensure the container exists
+	if err := SPEC_AssetExists(ctx, containerID); err != nil {
+		return err
+	}
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to record departures of containers
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Depart, "container_"); err != nil {
+		return err
+	}
+
+	container, err := getContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	// Ensure that the container is loaded
+	if err := SPEC_IsLegalStatusTransition(container, "departed"); err != nil {
+		return err
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the container departed after it was loaded and not in the future
+	if err := SPEC_Chronology(container.LoadedAt, departedAt, txTimestamp); err != nil {
+		return err
+	}
+
+	container.DepartedAt = departedAt
+	return putContainerStatus(ctx, container, "departed", txTimestamp)
+}
+
+// ArriveContainer records that a departed container has reached its destination port. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsLegalStatusTransition, 5) SPEC_Chronology
+func (s *SmartContract) ArriveContainer(ctx contractapi.TransactionContextInterface, containerID string, arrivedAt time.Time) error {
+	// Ensure the id begins with "container_"
+	if err := SPEC_IDPrefix(containerID, "container_"); err != nil {
+		return err
+	}
+	// Ensure the container exists
+	if err := SPEC_AssetExists(ctx, containerID); err != nil {
+		return err
+	}
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to record arrivals of containers
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Arrive, "container_"); err != nil {
+		return err
+	}
+
+	container, err := getContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	// Ensure that the container departed
+	if err := SPEC_IsLegalStatusTransition(container, "arrived"); err != nil {
+		return err
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the container arrived after it departed and not in the future
+	if err := SPEC_Chronology(container.DepartedAt, arrivedAt, txTimestamp); err != nil {
+		return err
+	}
+
+	container.ArrivedAt = arrivedAt
+	return putContainerStatus(ctx, container, "arrived", txTimestamp)
+}
+
+// DeliverContainer records that an arrived container has been delivered to the retailer. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_AssetExists, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsLegalStatusTransition, 5) SPEC_Chronology
+func (s *SmartContract) DeliverContainer(ctx contractapi.TransactionContextInterface, containerID string, deliveredAt time.Time) error {
+	// Ensure the id begins with "container_"
+	if err := SPEC_IDPrefix(containerID, "container_"); err != nil {
+		return err
+	}
+	// Ensure the container exists
+	if err := SPEC_AssetExists(ctx, containerID); err != nil {
+		return err
+	}
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to take delivery of containers
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Deliver, "container_"); err != nil {
+		return err
+	}
+
+	container, err := getContainer(ctx, containerID)
+	if err != nil {
+		return err
+	}
+	// Ensure that the container arrived
+	if err := SPEC_IsLegalStatusTransition(container, "delivered"); err != nil {
+		return err
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the container was delivered after it arrived and not in the future
+	if err := SPEC_Chronology(container.ArrivedAt, deliveredAt, txTimestamp); err != nil {
+		return err
+	}
+
+	container.DeliveredAt = deliveredAt
+	return putContainerStatus(ctx, container, "delivered", txTimestamp)
+}
+
+// putContainerStatus saves the container with its new status and emits the ContainerStatusChanged event
+func putContainerStatus(ctx contractapi.TransactionContextInterface, container *Container, status string, txTimestamp time.Time) error {
+	previousStatus := container.Status
+	if previousStatus == "" {
+		previousStatus = "loaded"
+	}
+	container.Status = status
+	container.UpdatedAt = txTimestamp
+
+	// Convert container to JSON
+	containerJSON, err := json.Marshal(container)
+	if err != nil {
+		return err
+	}
+	// Save the container to the world state
+	if err := ctx.GetStub().PutState(container.ID, containerJSON); err != nil {
+		return err
+	}
+	// Emit the ContainerStatusChanged event
+	return events.Emit(ctx, events.ContainerStatusChanged, events.ContainerStatusChangedData{ContainerID: container.ID, PreviousStatus: previousStatus, Status: status})
+}
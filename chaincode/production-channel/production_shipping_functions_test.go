@@ -0,0 +1,305 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// issued is after container_1 was loaded and before every transaction timestamp of the test ledger
+var issued = before.Add(24 * time.Hour)
+
+// createBillOfLading ships the containers on the vessel and between the ports of container_1
+func createBillOfLading(s *SmartContract, ctx contractapi.TransactionContextInterface, id string, grossWeight float32, issueDate time.Time, containers ...string) error {
+	return s.CreateBillOfLading(ctx, "Org1MSP", containers, "Los Angeles DC", "Los Angeles", "MAEU123456", "", "prepaid", grossWeight, id, false, issueDate, "Chittagong", "Dhaka", 7, "Org6MSP", "https://example.com/bl/MAEU123456", "Maersk Alabama")
+}
+
+func TestCreateBillOfLading(t *testing.T) {
+	create := func(id string, grossWeight float32, issueDate time.Time, containers ...string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return createBillOfLading(s, ctx, id, grossWeight, issueDate, containers...)
+		}
+	}
+	otherVoyage := func(vessel string, loadingPort string, dischargePort string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateBillOfLading(ctx, "Org1MSP", []string{"container_1"}, "", dischargePort, "", "", "", 1.0, "billoflading_9", false, issued, loadingPort, "", 7, "Org6MSP", "", vessel)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org6MSP", create("billoflading_9", 1.0, issued, "container_1"), ""},
+		{"gross weight within tolerance", "Org6MSP", create("billoflading_9", 1.04, issued, "container_1"), ""},
+		{"wrong prefix", "Org6MSP", create("container_9", 1.0, issued, "container_1"), "must start with 'billoflading_'"},
+		{"retailer", "Org1MSP", create("billoflading_9", 1.0, issued, "container_1"), "not invoked by an allowed organization"},
+		{"no containers", "Org6MSP", create("billoflading_9", 1.0, issued), "content list cannot be empty"},
+		{"duplicate containers", "Org6MSP", create("billoflading_9", 1.0, issued, "container_1", "container_1"), "duplicate asset ID found: container_1"},
+		{"carton instead of container", "Org6MSP", create("billoflading_9", 1.0, issued, "carton_1"), "does not have the correct prefix container_"},
+		{"missing container", "Org6MSP", create("billoflading_9", 1.0, issued, "container_9"), "container_9 does not exist"},
+		{"other vessel", "Org6MSP", otherVoyage("Ever Given", "Chittagong", "Los Angeles"), "vessel Ever Given does not match"},
+		{"other loading port", "Org6MSP", otherVoyage("Maersk Alabama", "Mongla", "Los Angeles"), "loading port Mongla does not match"},
+		{"other discharge port", "Org6MSP", otherVoyage("Maersk Alabama", "Chittagong", "Long Beach"), "discharge port Long Beach does not match"},
+		{"gross weight too high", "Org6MSP", create("billoflading_9", 1.2, issued, "container_1"), "more than the tolerance of 5%"},
+		{"gross weight too low", "Org6MSP", create("billoflading_9", 0.9, issued, "container_1"), "more than the tolerance of 5%"},
+		{"issued before loading", "Org6MSP", create("billoflading_9", 1.0, before.Add(-time.Hour), "container_1"), "is not after"},
+		{"issued in the future", "Org6MSP", create("billoflading_9", 1.0, after, "container_1"), "is not after"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		billOfLading := checkCreated(t, ctx, "billoflading_9", "Org6MSP", "container_1")
+		if billOfLading["ContainersWeight"] != 1.0 || billOfLading["Vessel"] != "Maersk Alabama" {
+			t.Errorf("unexpected bill of lading %v", billOfLading)
+		}
+	})
+}
+
+func TestShippingQueries(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateContainer(ctx, []string{"carton_1"}, "Los Angeles", "", "container_2", false, before, "Chittagong", 2.0, "Maersk Alabama")
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateContainer(ctx, []string{"carton_1"}, "Los Angeles", "", "container_3", false, before, "Chittagong", 1.0, "Maersk Alabama")
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return createBillOfLading(s, ctx, "billoflading_1", 3.0, issued, "container_1", "container_2")
+	})
+
+	// A container is shipped under a single bill of lading
+	err := ctx.Submit("Org6MSP", func() error {
+		return createBillOfLading(s, ctx, "billoflading_2", 2.0, issued, "container_3", "container_1")
+	})
+	checkErr(t, err, "container container_1 cannot be shipped under bill of lading billoflading_2 because it is already shipped under billoflading_1")
+
+	billOfLading, err := s.GetBillOfLadingByContainer(ctx, "container_2")
+	checkErr(t, err, "")
+	if billOfLading.ID != "billoflading_1" || billOfLading.ContainersWeight != 3.0 {
+		t.Errorf("unexpected bill of lading %+v", billOfLading)
+	}
+	_, err = s.GetBillOfLadingByContainer(ctx, "container_3")
+	checkErr(t, err, "container container_3 is not shipped under any bill of lading")
+	_, err = s.GetBillOfLadingByContainer(ctx, "container_9")
+	checkErr(t, err, "the asset container_9 does not exist")
+	_, err = s.GetBillOfLadingByContainer(ctx, "billoflading_1")
+	checkErr(t, err, "must start with 'container_'")
+
+	containers, err := s.GetContainersByBillOfLading(ctx, "billoflading_1")
+	checkErr(t, err, "")
+	if len(containers) != 2 || containers[0].ID != "container_1" || containers[1].ID != "container_2" {
+		t.Errorf("unexpected containers %+v", containers)
+	}
+	_, err = s.GetContainersByBillOfLading(ctx, "billoflading_9")
+	checkErr(t, err, "the asset billoflading_9 does not exist")
+
+	billsOfLading, err := s.GetAllBillsOfLading(ctx)
+	checkErr(t, err, "")
+	if len(billsOfLading) != 1 {
+		t.Errorf("expected 1 bill of lading, got %d", len(billsOfLading))
+	}
+
+	// The bill of lading is the last link of the trace in both directions
+	upstream, err := s.TraceUpstream(ctx, "billoflading_1")
+	checkErr(t, err, "")
+	if !contains(traceIDs(upstream), "cottonbale_1") {
+		t.Errorf("expected the upstream trace to reach cottonbale_1, got %v", traceIDs(upstream))
+	}
+	downstream, err := s.TraceDownstream(ctx, "cottonbale_1")
+	checkErr(t, err, "")
+	if !contains(traceIDs(downstream), "billoflading_1") {
+		t.Errorf("expected the downstream trace to reach billoflading_1, got %v", traceIDs(downstream))
+	}
+}
+
+func TestContainerStatusLifecycle(t *testing.T) {
+	departed := issued
+	arrived := departed.Add(24 * time.Hour)
+	delivered := arrived.Add(24 * time.Hour)
+	depart := func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return s.DepartContainer(ctx, "container_1", departed)
+	}
+	arrive := func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return s.ArriveContainer(ctx, "container_1", arrived)
+	}
+	deliver := func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return s.DeliverContainer(ctx, "container_1", delivered)
+	}
+
+	s, ctx := newSupplyChainLedger(t)
+	container, err := getContainer(ctx, "container_1")
+	checkErr(t, err, "")
+	if container.Status != "loaded" {
+		t.Fatalf("expected a new container to be loaded, got %q", container.Status)
+	}
+
+	steps := []testCase{
+		{"wrong prefix", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.DepartContainer(ctx, "carton_1", departed)
+		}, "must start with 'container_'"},
+		{"missing container", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.DepartContainer(ctx, "container_9", departed)
+		}, "the asset container_9 does not exist"},
+		{"arrive before departing", "Org6MSP", arrive, "container container_1 cannot become arrived because it is loaded, not departed"},
+		{"deliver before arriving", "Org1MSP", deliver, "container container_1 cannot become delivered because it is loaded, not arrived"},
+		{"depart by the retailer", "Org1MSP", depart, "Organizations allowed to depart container_: [Org6MSP]"},
+		{"depart before loading", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.DepartContainer(ctx, "container_1", before.Add(-time.Hour))
+		}, "is not after"},
+		{"depart in the future", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.DepartContainer(ctx, "container_1", after)
+		}, "is not after"},
+		{"depart", "Org6MSP", depart, ""},
+		{"depart twice", "Org6MSP", depart, "container container_1 cannot become departed because it is departed, not loaded"},
+		{"arrive before departure", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.ArriveContainer(ctx, "container_1", departed.Add(-time.Hour))
+		}, "is not after"},
+		{"arrive", "Org6MSP", arrive, ""},
+		{"deliver to the supplier", "Org6MSP", deliver, "Organizations allowed to deliver container_: [Org1MSP]"},
+		{"deliver before arrival", "Org1MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.DeliverContainer(ctx, "container_1", departed)
+		}, "is not after"},
+		{"deliver", "Org1MSP", deliver, ""},
+		{"depart after delivery", "Org6MSP", depart, "cannot become departed because it is delivered, not loaded"},
+	}
+	for _, step := range steps {
+		err := ctx.Submit(step.mspID, func() error { return step.invoke(s, ctx) })
+		checkErr(t, err, step.wantErr)
+		if err == nil {
+			last := ctx.Stub().LastEvent()
+			event, err := events.Decode(last.EventName, last.Payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if data, ok := event.Data.(*events.ContainerStatusChangedData); !ok || data.ContainerID != "container_1" {
+				t.Errorf("%s: unexpected event %s with data %+v", step.name, event.Name, event.Data)
+			}
+		}
+	}
+
+	container, err = getContainer(ctx, "container_1")
+	checkErr(t, err, "")
+	if container.Status != "delivered" || !container.DepartedAt.Equal(departed) || !container.ArrivedAt.Equal(arrived) || !container.DeliveredAt.Equal(delivered) {
+		t.Errorf("unexpected container %+v", container)
+	}
+}
+
+func TestContainerStatusByCarrier(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+
+	// A carrier joins the carrier role and can then move containers, but not take delivery of them
+	submit(t, ctx, "Org1MSP", func() error {
+		return s.SetRolePolicy(ctx, rolePolicyJSON(t, func(rolePolicy *policy.RolePolicy) {
+			rolePolicy.Roles[policy.Carrier].MSPIDs = []string{"Org7MSP"}
+		}))
+	})
+	submit(t, ctx, "Org7MSP", func() error {
+		return s.DepartContainer(ctx, "container_1", issued)
+	})
+	submit(t, ctx, "Org7MSP", func() error {
+		return s.ArriveContainer(ctx, "container_1", issued.Add(time.Hour))
+	})
+	err := ctx.Submit("Org7MSP", func() error {
+		return s.DeliverContainer(ctx, "container_1", issued.Add(2*time.Hour))
+	})
+	checkErr(t, err, "not invoked by an allowed organization")
+}
+
+// traceIDs returns the IDs of the nodes of a trace in breadth-first order
+func traceIDs(trace *ProvenanceTrace) []string {
+	ids := []string{}
+	for _, node := range trace.Nodes {
+		ids = append(ids, node.ID)
+	}
+	return ids
+}
+
+// newCartonLedger adds carton_2, a carton packed for Org1MSP and owned by Org6MSP that is not loaded in a container, to the supply chain ledger
+func newCartonLedger(t *testing.T) (*SmartContract, *chaincodetest.TransactionContext) {
+	t.Helper()
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCutPart(ctx, true, before, []string{"lot_4"}, "", "cutpart_2", false, "", "Dhaka", "front panel", 0.3)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateButton(ctx, true, before, "", "button_2", false, "", "Dhaka", 0.01)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateAssembledGarment(ctx, true, before, []string{"button_2"}, []string{"cutpart_2"}, "", "assembledgarment_2", false, "", "Dhaka", 0.31)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCarton(ctx, true, before, []string{"assembledgarment_2"}, "Org1MSP", "", "carton_2", false, "", "order_1", "Dhaka", "Org6MSP", 0.5)
+	})
+	return s, ctx
+}
+
+// checkOwnershipChanged fails the test unless the last event transferred the asset and its content from Org6MSP to Org1MSP
+func checkOwnershipChanged(t *testing.T, ctx *chaincodetest.TransactionContext, assetID string, contentIDs ...string) {
+	t.Helper()
+	last := ctx.Stub().LastEvent()
+	event, err := events.Decode(last.EventName, last.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := event.Data.(*events.AssetOwnershipChangedData); !ok || data.AssetID != assetID || data.NewOwner != "Org1MSP" || data.PreviousOwner != "Org6MSP" || len(data.ContentIDs) != len(contentIDs) {
+		t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+	}
+	for _, id := range append(contentIDs, assetID) {
+		asset, err := getAssetMap(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if asset["Owner"] != "Org1MSP" || asset["PreviousOwner"] != "Org6MSP" {
+			t.Errorf("expected %s to be owned by Org1MSP after Org6MSP, got %v and %v", id, asset["Owner"], asset["PreviousOwner"])
+		}
+	}
+}
+
+func TestTransferCarton(t *testing.T) {
+	transfer := func(cartonID string, newOwner string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.TransferCarton(ctx, cartonID, newOwner)
+		}
+	}
+	otherRetailer := rolePolicyJSON(t, func(rolePolicy *policy.RolePolicy) {
+		rolePolicy.Roles[policy.Retailer].MSPIDs = append(rolePolicy.Roles[policy.Retailer].MSPIDs, "Org7MSP")
+	})
+	runOn(t, newCartonLedger, []testCase{
+		{"valid", "Org6MSP", transfer("carton_2", "Org1MSP"), ""},
+		{"wrong prefix", "Org6MSP", transfer("container_1", "Org1MSP"), "must start with 'carton_'"},
+		{"missing carton", "Org6MSP", transfer("carton_9", "Org1MSP"), "the asset carton_9 does not exist"},
+		{"not the owner", "Org1MSP", transfer("carton_2", "Org1MSP"), "Allowed organizations: [Org6MSP]"},
+		{"to the owner", "Org6MSP", transfer("carton_2", "Org6MSP"), "carton carton_2 is already owned by Org6MSP"},
+		{"loaded in a container", "Org6MSP", transfer("carton_1", "Org1MSP"), "carton carton_1 is loaded in container container_1 and can only be transferred with it"},
+		{"not allowed to own cartons", "Org6MSP", transfer("carton_2", "Org5MSP"), "the proposed owner, Org5MSP, is not allowed to own this asset"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkOwnershipChanged(t, ctx, "carton_2")
+	})
+
+	// A second retailer can own cartons, but only those packed for it
+	s, ctx := newCartonLedger(t)
+	submit(t, ctx, "Org1MSP", func() error { return s.SetRolePolicy(ctx, otherRetailer) })
+	err := ctx.Submit("Org6MSP", func() error { return s.TransferCarton(ctx, "carton_2", "Org7MSP") })
+	checkErr(t, err, "carton carton_2 was packed for Org1MSP and cannot be handed over to Org7MSP")
+
+	// A flagged carton cannot change hands
+	submit(t, ctx, "Org6MSP", func() error { return s.SetFlag(ctx, "carton_2", true, "damaged") })
+	err = ctx.Submit("Org6MSP", func() error { return s.TransferCarton(ctx, "carton_2", "Org1MSP") })
+	checkErr(t, err, "the asset carton_2 is flagged")
+}
+
+func TestTransferContainer(t *testing.T) {
+	transfer := func(containerID string, newOwner string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.TransferContainer(ctx, containerID, newOwner)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org6MSP", transfer("container_1", "Org1MSP"), ""},
+		{"wrong prefix", "Org6MSP", transfer("carton_1", "Org1MSP"), "must start with 'container_'"},
+		{"missing container", "Org6MSP", transfer("container_9", "Org1MSP"), "the asset container_9 does not exist"},
+		{"not the owner", "Org1MSP", transfer("container_1", "Org1MSP"), "Allowed organizations: [Org6MSP]"},
+		{"to the owner", "Org6MSP", transfer("container_1", "Org6MSP"), "container container_1 is already owned by Org6MSP"},
+		{"not allowed to own containers", "Org6MSP", transfer("container_1", "Org2MSP"), "the proposed owner, Org2MSP, is not allowed to own this asset"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkOwnershipChanged(t, ctx, "container_1", "carton_1")
+	})
+}
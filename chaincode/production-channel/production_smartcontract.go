@@ -7,6 +7,10 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/index"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
 )
 
 // SmartContract provides functions for managing an Asset
@@ -14,7 +18,10 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
-// CreateCottonBale issues a new asset (CottonBale) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_Chronology
+// lotMembershipIndex is the composite key object type mapping an asset to the lot it is stored in
+const lotMembershipIndex = "member~lot"
+
+// CreateCottonBale issues a new asset (CottonBale) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_Chronology
 func (s *SmartContract) CreateCottonBale(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, flagReason string, cottonBaleID string, isFlagged bool, notes string, origin string, qualityGrade string, totalWeight float32) error {
 	// Ensure the id begins with "cottonbale_"
 	if err := SPEC_IDPrefix(cottonBaleID, "cottonbale_"); err != nil {
@@ -24,10 +31,21 @@ func (s *SmartContract) CreateCottonBale(ctx contractapi.TransactionContextInter
 	if err := SPEC_IsNewAsset(ctx, cottonBaleID); err != nil {
 		return err
 	}
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org4MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "cottonbale_"); err != nil {
 		return err
 	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
@@ -39,18 +57,27 @@ func (s *SmartContract) CreateCottonBale(ctx contractapi.TransactionContextInter
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	cottonBale := CottonBale{
-		Approval:     approval,
-		AssemblyDate: assemblyDate,
-		CreatorID:    clientMSPID,
-		FlagReason:   flagReason,
-		ID:           cottonBaleID,
-		IsFlagged:    isFlagged,
-		Notes:        notes,
-		Origin:       origin,
-		QualityGrade: qualityGrade,
-		TotalWeight:  totalWeight,
-		UpdatedAt:    time.Now(),
+		Approval:      approval,
+		AssemblyDate:  assemblyDate,
+		CreatorID:     clientMSPID,
+		DocType:       "cottonbale_",
+		FlagReason:    flagReason,
+		ID:            cottonBaleID,
+		IsFlagged:     isFlagged,
+		Notes:         notes,
+		Origin:        origin,
+		QualityGrade:  qualityGrade,
+		SchemaVersion: schemaVersion,
+		TotalWeight:   totalWeight,
+		UpdatedAt:     txTimestamp,
+		WeightUnit:    ledgerUnits.Weight,
 	}
 
 	// Ensure that the dates are in chronological order
@@ -65,10 +92,18 @@ func (s *SmartContract) CreateCottonBale(ctx contractapi.TransactionContextInter
 	}
 
 	// Save the cottonBale to the world state
-	return ctx.GetStub().PutState(cottonBaleID, cottonBaleJSON)
+	if err := ctx.GetStub().PutState(cottonBaleID, cottonBaleJSON); err != nil {
+		return err
+	}
+	// Index the cottonBale by type
+	if err := index.PutType(ctx, cottonBaleID); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: cottonBaleID, AssetType: "cottonbale_"})
 }
 
-// CreateLot issues a new asset (Lot) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_IsNotFlagged, 6) SPEC_LotConsistency, 7) SPEC_NoDuplicateAssetInThisLot, 8) SPEC_NoDuplicateAssetInState, 9) SPEC_Chronology
+// CreateLot issues a new asset (Lot) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_IsNotFlagged, 6) SPEC_NoDuplicateAssetInThisLot, 7) SPEC_LotConsistency, 8) SPEC_NoDuplicateAssetInState, 9) SPEC_CheckAssetsApproval, 10) SPEC_Chronology
 func (s *SmartContract) CreateLot(ctx contractapi.TransactionContextInterface, assemblyDate time.Time, assetIDPrefix string, content []string, destination string, flagReason string, lotID string, isFlagged bool, notes string, origin string, owner string, totalWeight float32) error {
 	// Ensure the id begins with "lot_"
 	if err := SPEC_IDPrefix(lotID, "lot_"); err != nil {
@@ -79,43 +114,21 @@ func (s *SmartContract) CreateLot(ctx contractapi.TransactionContextInterface, a
 		return err
 	}
 
-	// Switch on the assetIDPrefix to determine which organizations are allowed to invoke the function
-	switch assetIDPrefix {
-	case "cottonbale_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org4MSP"); err != nil {
-			return err
-		}
-
-	case "cottonyarn_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org4MSP"); err != nil {
-			return err
-		}
-
-	case "unfinishedfabric_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org5MSP"); err != nil {
-			return err
-		}
-
-	case "finishedfabric_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org5MSP"); err != nil {
-			return err
-		}
-
-	case "cutpart_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org6MSP"); err != nil {
-			return err
-		}
-
-	case "button_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org6MSP"); err != nil {
-			return err
-		}
-
-	case "assembledgarment_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org6MSP"); err != nil {
-			return err
-		}
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create lots of the asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.CreateLot, assetIDPrefix); err != nil {
+		return err
 	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
 
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
@@ -158,25 +171,38 @@ func (s *SmartContract) CreateLot(ctx contractapi.TransactionContextInterface, a
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	lot := Lot{
 		AllAssetsApproved: true,
 		AssemblyDate:      assemblyDate,
 		AssetIDPrefix:     assetIDPrefix,
+		ConsumptionState:  "available",
 		Content:           content,
 		ContentWeight:     contentWeight,
 		CreatorID:         clientMSPID,
+		Custodian:         clientMSPID,
 		Destination:       destination,
+		DocType:           "lot_",
 		FlagReason:        flagReason,
 		ID:                lotID,
 		IsFlagged:         isFlagged,
+		Location:          origin,
 		Notes:             notes,
 		Origin:            origin,
 		Owner:             clientMSPID,
 		PreviousOwner:     "Updated when ownership changes",
 		Quantity:          len(content),
+		SchemaVersion:     schemaVersion,
 		TotalWeight:       totalWeight,
-		UpdatedAt:         time.Now(),
+		TransitState:      "on_site",
+		UpdatedAt:         txTimestamp,
 		WeightDifference:  weightDifference,
+		WeightUnit:        ledgerUnits.Weight,
 	}
 
 	// Ensure that the dates are in chronological order
@@ -191,11 +217,35 @@ func (s *SmartContract) CreateLot(ctx contractapi.TransactionContextInterface, a
 	}
 
 	// Save the lot to the world state
-	return ctx.GetStub().PutState(lotID, lotJSON)
+	if err := ctx.GetStub().PutState(lotID, lotJSON); err != nil {
+		return err
+	}
+	// Index the lot by type and owner
+	if err := index.PutType(ctx, lotID); err != nil {
+		return err
+	}
+	if err := index.PutOwner(ctx, lot.Owner, lotID); err != nil {
+		return err
+	}
+	// Link each asset in the content list to this lot
+	if err := PutParentLinks(ctx, lotID, content); err != nil {
+		return err
+	}
+	// Record this lot as the lot of each asset in the content list
+	if err := PutLotMembership(ctx, lotID, content); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: lotID, AssetType: "lot_"})
 }
 
-// CreateCottonYarn issues a new asset (CottonYarn) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_CheckLotAssetType, 7) SPEC_Chronology
+// CreateCottonYarn issues a new asset (CottonYarn) to the state with select attributes, drawing its own weight from its content lots. It applies the specifications of CreateCottonYarnWithDraws
 func (s *SmartContract) CreateCottonYarn(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, content []string, flagReason string, cottonYarnID string, isFlagged bool, notes string, origin string, totalWeight float32, yarnCount int) error {
+	return s.CreateCottonYarnWithDraws(ctx, approval, assemblyDate, content, nil, flagReason, cottonYarnID, isFlagged, notes, origin, totalWeight, yarnCount)
+}
+
+// CreateCottonYarnWithDraws issues a new asset (CottonYarn) to the state with select attributes, recording the weight drawn from each content lot. An empty drawnWeights draws the weight of the asset itself from its content lots in order. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_CheckLotAssetType, 7) SPEC_IsNotRetired, 8) SPEC_IsOnSite, 9) SPEC_IsHeldByInvoker, 10) SPEC_IsAvailable, 11) SPEC_Chronology
+func (s *SmartContract) CreateCottonYarnWithDraws(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, content []string, drawnWeights []float32, flagReason string, cottonYarnID string, isFlagged bool, notes string, origin string, totalWeight float32, yarnCount int) error {
 	// Ensure the id begins with "cottonyarn_"
 	if err := SPEC_IDPrefix(cottonYarnID, "cottonyarn_"); err != nil {
 		return err
@@ -204,10 +254,22 @@ func (s *SmartContract) CreateCottonYarn(ctx contractapi.TransactionContextInter
 	if err := SPEC_IsNewAsset(ctx, cottonYarnID); err != nil {
 		return err
 	}
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org4MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "cottonyarn_"); err != nil {
 		return err
 	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	drawnWeights = reportingUnits.ConvertWeights(drawnWeights)
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
@@ -221,13 +283,31 @@ func (s *SmartContract) CreateCottonYarn(ctx contractapi.TransactionContextInter
 		if err := SPEC_CheckLotAssetType(ctx, lotID, "cottonbale_"); err != nil {
 			return err
 		}
+		// Ensure that the lot was not split or merged into other lots
+		if err := SPEC_IsNotRetired(ctx, lotID); err != nil {
+			return err
+		}
+		// Ensure that the lot is not in transit
+		if err := SPEC_IsOnSite(ctx, lotID); err != nil {
+			return err
+		}
+		// Ensure that the lot is held by the invoking organization
+		if err := SPEC_IsHeldByInvoker(ctx, lotID); err != nil {
+			return err
+		}
 	}
 
-	// Calculate the content weight by summing asset weights in content
-	contentWeight, err := GetContentWeight(ctx, content)
+	// Retrieve the weight drawn from each content lot, which defaults to the weight of this asset, and the content weight
+	draws, contentWeight, err := GetDrawnWeights(ctx, content, drawnWeights, totalWeight)
 	if err != nil {
 		return err
 	}
+	// Ensure that something is drawn from each content lot, that it was not already consumed and that it still holds the weight drawn from it
+	for i, lotID := range content {
+		if err := SPEC_IsAvailable(ctx, lotID, draws[i]); err != nil {
+			return err
+		}
+	}
 	// Calculate percentage difference between total weight and content weight
 	var weightDifference float32 = GetPercentageDifference(contentWeight, totalWeight)
 
@@ -237,20 +317,29 @@ func (s *SmartContract) CreateCottonYarn(ctx contractapi.TransactionContextInter
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	cottonYarn := CottonYarn{
 		Approval:         approval,
 		AssemblyDate:     assemblyDate,
 		Content:          content,
 		ContentWeight:    contentWeight,
 		CreatorID:        clientMSPID,
+		DocType:          "cottonyarn_",
 		FlagReason:       flagReason,
 		ID:               cottonYarnID,
 		IsFlagged:        isFlagged,
 		Notes:            notes,
 		Origin:           origin,
+		SchemaVersion:    schemaVersion,
 		TotalWeight:      totalWeight,
-		UpdatedAt:        time.Now(),
+		UpdatedAt:        txTimestamp,
 		WeightDifference: weightDifference,
+		WeightUnit:       ledgerUnits.Weight,
 		YarnCount:        yarnCount,
 	}
 
@@ -266,11 +355,32 @@ func (s *SmartContract) CreateCottonYarn(ctx contractapi.TransactionContextInter
 	}
 
 	// Save the cottonYarn to the world state
-	return ctx.GetStub().PutState(cottonYarnID, cottonYarnJSON)
+	if err := ctx.GetStub().PutState(cottonYarnID, cottonYarnJSON); err != nil {
+		return err
+	}
+	// Index the cottonYarn by type
+	if err := index.PutType(ctx, cottonYarnID); err != nil {
+		return err
+	}
+	// Link each content lot to this cottonYarn
+	if err := PutParentLinks(ctx, cottonYarnID, content); err != nil {
+		return err
+	}
+	// Draw the weight taken by this cottonYarn from each content lot
+	if err := ConsumeLots(ctx, cottonYarnID, content, draws, len(drawnWeights) == 0, txTimestamp); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: cottonYarnID, AssetType: "cottonyarn_"})
 }
 
-// CreateUnfinishedFabric issues a new asset (UnfinishedFabric) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_CheckLotAssetType, 7) SPEC_Chronology
+// CreateUnfinishedFabric issues a new asset (UnfinishedFabric) to the state with select attributes, drawing its own weight from its content lots. It applies the specifications of CreateUnfinishedFabricWithDraws
 func (s *SmartContract) CreateUnfinishedFabric(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, content []string, flagReason string, unfinishedFabricID string, isFlagged bool, notes string, origin string, length float32, totalWeight float32, width float32) error {
+	return s.CreateUnfinishedFabricWithDraws(ctx, approval, assemblyDate, content, nil, flagReason, unfinishedFabricID, isFlagged, notes, origin, length, totalWeight, width)
+}
+
+// CreateUnfinishedFabricWithDraws issues a new asset (UnfinishedFabric) to the state with select attributes, recording the weight drawn from each content lot. An empty drawnWeights draws the weight of the asset itself from its content lots in order. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_CheckLotAssetType, 7) SPEC_IsNotRetired, 8) SPEC_IsOnSite, 9) SPEC_IsHeldByInvoker, 10) SPEC_IsAvailable, 11) SPEC_Chronology
+func (s *SmartContract) CreateUnfinishedFabricWithDraws(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, content []string, drawnWeights []float32, flagReason string, unfinishedFabricID string, isFlagged bool, notes string, origin string, length float32, totalWeight float32, width float32) error {
 	// Ensure the id begins with "unfinishedfabric_"
 	if err := SPEC_IDPrefix(unfinishedFabricID, "unfinishedfabric_"); err != nil {
 		return err
@@ -279,10 +389,24 @@ func (s *SmartContract) CreateUnfinishedFabric(ctx contractapi.TransactionContex
 	if err := SPEC_IsNewAsset(ctx, unfinishedFabricID); err != nil {
 		return err
 	}
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org5MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "unfinishedfabric_"); err != nil {
 		return err
 	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	drawnWeights = reportingUnits.ConvertWeights(drawnWeights)
+	length = reportingUnits.ConvertLength(length)
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
+	width = reportingUnits.ConvertWidth(width)
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
@@ -296,13 +420,31 @@ func (s *SmartContract) CreateUnfinishedFabric(ctx contractapi.TransactionContex
 		if err := SPEC_CheckLotAssetType(ctx, lotID, "cottonyarn_"); err != nil {
 			return err
 		}
+		// Ensure that the lot was not split or merged into other lots
+		if err := SPEC_IsNotRetired(ctx, lotID); err != nil {
+			return err
+		}
+		// Ensure that the lot is not in transit
+		if err := SPEC_IsOnSite(ctx, lotID); err != nil {
+			return err
+		}
+		// Ensure that the lot is held by the invoking organization
+		if err := SPEC_IsHeldByInvoker(ctx, lotID); err != nil {
+			return err
+		}
 	}
 
-	// Calculate the content weight by summing asset weights in content
-	contentWeight, err := GetContentWeight(ctx, content)
+	// Retrieve the weight drawn from each content lot, which defaults to the weight of this asset, and the content weight
+	draws, contentWeight, err := GetDrawnWeights(ctx, content, drawnWeights, totalWeight)
 	if err != nil {
 		return err
 	}
+	// Ensure that something is drawn from each content lot, that it was not already consumed and that it still holds the weight drawn from it
+	for i, lotID := range content {
+		if err := SPEC_IsAvailable(ctx, lotID, draws[i]); err != nil {
+			return err
+		}
+	}
 	// Calculate percentage difference between total weight and content weight
 	var weightDifference float32 = GetPercentageDifference(contentWeight, totalWeight)
 
@@ -312,22 +454,33 @@ func (s *SmartContract) CreateUnfinishedFabric(ctx contractapi.TransactionContex
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	unfinishedFabric := UnfinishedFabric{
 		Approval:         approval,
 		AssemblyDate:     assemblyDate,
 		Content:          content,
 		ContentWeight:    contentWeight,
 		CreatorID:        clientMSPID,
+		DocType:          "unfinishedfabric_",
 		FlagReason:       flagReason,
 		ID:               unfinishedFabricID,
 		IsFlagged:        isFlagged,
 		Length:           length,
+		LengthUnit:       ledgerUnits.Length,
 		Notes:            notes,
 		Origin:           origin,
+		SchemaVersion:    schemaVersion,
 		TotalWeight:      totalWeight,
-		UpdatedAt:        time.Now(),
+		UpdatedAt:        txTimestamp,
 		WeightDifference: weightDifference,
+		WeightUnit:       ledgerUnits.Weight,
 		Width:            width,
+		WidthUnit:        ledgerUnits.Width,
 	}
 
 	// Ensure that the dates are in chronological order
@@ -342,11 +495,32 @@ func (s *SmartContract) CreateUnfinishedFabric(ctx contractapi.TransactionContex
 	}
 
 	// Save the unfinishedFabric to the world state
-	return ctx.GetStub().PutState(unfinishedFabricID, unfinishedFabricJSON)
+	if err := ctx.GetStub().PutState(unfinishedFabricID, unfinishedFabricJSON); err != nil {
+		return err
+	}
+	// Index the unfinishedFabric by type
+	if err := index.PutType(ctx, unfinishedFabricID); err != nil {
+		return err
+	}
+	// Link each content lot to this unfinishedFabric
+	if err := PutParentLinks(ctx, unfinishedFabricID, content); err != nil {
+		return err
+	}
+	// Draw the weight taken by this unfinishedFabric from each content lot
+	if err := ConsumeLots(ctx, unfinishedFabricID, content, draws, len(drawnWeights) == 0, txTimestamp); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: unfinishedFabricID, AssetType: "unfinishedfabric_"})
 }
 
-// CreateFinishedFabric issues a new asset (FinishedFabric) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_CheckLotAssetType, 7) SPEC_Chronology
+// CreateFinishedFabric issues a new asset (FinishedFabric) to the state with select attributes, drawing its own weight from its content lots. It applies the specifications of CreateFinishedFabricWithDraws
 func (s *SmartContract) CreateFinishedFabric(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, content []string, finishedFabricID string, flagReason string, isFlagged bool, length float32, notes string, origin string, totalWeight float32, width float32) error {
+	return s.CreateFinishedFabricWithDraws(ctx, approval, assemblyDate, content, nil, finishedFabricID, flagReason, isFlagged, length, notes, origin, totalWeight, width)
+}
+
+// CreateFinishedFabricWithDraws issues a new asset (FinishedFabric) to the state with select attributes, recording the weight drawn from each content lot. An empty drawnWeights draws the weight of the asset itself from its content lots in order. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_CheckLotAssetType, 7) SPEC_IsNotRetired, 8) SPEC_IsOnSite, 9) SPEC_IsHeldByInvoker, 10) SPEC_IsAvailable, 11) SPEC_Chronology
+func (s *SmartContract) CreateFinishedFabricWithDraws(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, content []string, drawnWeights []float32, finishedFabricID string, flagReason string, isFlagged bool, length float32, notes string, origin string, totalWeight float32, width float32) error {
 	// Ensure the id begins with "finishedfabric_"
 	if err := SPEC_IDPrefix(finishedFabricID, "finishedfabric_"); err != nil {
 		return err
@@ -355,10 +529,24 @@ func (s *SmartContract) CreateFinishedFabric(ctx contractapi.TransactionContextI
 	if err := SPEC_IsNewAsset(ctx, finishedFabricID); err != nil {
 		return err
 	}
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org5MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
 		return err
 	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "finishedfabric_"); err != nil {
+		return err
+	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	drawnWeights = reportingUnits.ConvertWeights(drawnWeights)
+	length = reportingUnits.ConvertLength(length)
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
+	width = reportingUnits.ConvertWidth(width)
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
@@ -372,13 +560,31 @@ func (s *SmartContract) CreateFinishedFabric(ctx contractapi.TransactionContextI
 		if err := SPEC_CheckLotAssetType(ctx, lotID, "unfinishedfabric_"); err != nil {
 			return err
 		}
+		// Ensure that the lot was not split or merged into other lots
+		if err := SPEC_IsNotRetired(ctx, lotID); err != nil {
+			return err
+		}
+		// Ensure that the lot is not in transit
+		if err := SPEC_IsOnSite(ctx, lotID); err != nil {
+			return err
+		}
+		// Ensure that the lot is held by the invoking organization
+		if err := SPEC_IsHeldByInvoker(ctx, lotID); err != nil {
+			return err
+		}
 	}
 
-	// Calculate the content weight by summing asset weights in content
-	contentWeight, err := GetContentWeight(ctx, content)
+	// Retrieve the weight drawn from each content lot, which defaults to the weight of this asset, and the content weight
+	draws, contentWeight, err := GetDrawnWeights(ctx, content, drawnWeights, totalWeight)
 	if err != nil {
 		return err
 	}
+	// Ensure that something is drawn from each content lot, that it was not already consumed and that it still holds the weight drawn from it
+	for i, lotID := range content {
+		if err := SPEC_IsAvailable(ctx, lotID, draws[i]); err != nil {
+			return err
+		}
+	}
 	// Calculate percentage difference between total weight and content weight
 	var weightDifference float32 = GetPercentageDifference(contentWeight, totalWeight)
 
@@ -388,22 +594,33 @@ func (s *SmartContract) CreateFinishedFabric(ctx contractapi.TransactionContextI
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	finishedFabric := FinishedFabric{
 		Approval:         approval,
 		AssemblyDate:     assemblyDate,
 		Content:          content,
 		ContentWeight:    contentWeight,
 		CreatorID:        clientMSPID,
+		DocType:          "finishedfabric_",
 		FlagReason:       flagReason,
 		ID:               finishedFabricID,
 		IsFlagged:        isFlagged,
 		Length:           length,
+		LengthUnit:       ledgerUnits.Length,
 		Notes:            notes,
 		Origin:           origin,
+		SchemaVersion:    schemaVersion,
 		TotalWeight:      totalWeight,
-		UpdatedAt:        time.Now(),
+		UpdatedAt:        txTimestamp,
 		WeightDifference: weightDifference,
+		WeightUnit:       ledgerUnits.Weight,
 		Width:            width,
+		WidthUnit:        ledgerUnits.Width,
 	}
 
 	// Ensure that the dates are in chronological order
@@ -418,11 +635,32 @@ func (s *SmartContract) CreateFinishedFabric(ctx contractapi.TransactionContextI
 	}
 
 	// Save the finishedFabric to the world state
-	return ctx.GetStub().PutState(finishedFabricID, finishedFabricJSON)
+	if err := ctx.GetStub().PutState(finishedFabricID, finishedFabricJSON); err != nil {
+		return err
+	}
+	// Index the finishedFabric by type
+	if err := index.PutType(ctx, finishedFabricID); err != nil {
+		return err
+	}
+	// Link each content lot to this finishedFabric
+	if err := PutParentLinks(ctx, finishedFabricID, content); err != nil {
+		return err
+	}
+	// Draw the weight taken by this finishedFabric from each content lot
+	if err := ConsumeLots(ctx, finishedFabricID, content, draws, len(drawnWeights) == 0, txTimestamp); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: finishedFabricID, AssetType: "finishedfabric_"})
 }
 
-// CreateCutPart issues a new asset (CutPart) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_CheckLotAssetType, 7) SPEC_Chronology
+// CreateCutPart issues a new asset (CutPart) to the state with select attributes, drawing its own weight from its content lots. It applies the specifications of CreateCutPartWithDraws
 func (s *SmartContract) CreateCutPart(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, content []string, flagReason string, cutPartID string, isFlagged bool, notes string, origin string, patternPiece string, totalWeight float32) error {
+	return s.CreateCutPartWithDraws(ctx, approval, assemblyDate, content, nil, flagReason, cutPartID, isFlagged, notes, origin, patternPiece, totalWeight)
+}
+
+// CreateCutPartWithDraws issues a new asset (CutPart) to the state with select attributes, recording the weight drawn from each content lot. An empty drawnWeights draws the weight of the asset itself from its content lots in order. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_CheckLotAssetType, 7) SPEC_IsNotRetired, 8) SPEC_IsOnSite, 9) SPEC_IsHeldByInvoker, 10) SPEC_IsAvailable, 11) SPEC_Chronology
+func (s *SmartContract) CreateCutPartWithDraws(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, content []string, drawnWeights []float32, flagReason string, cutPartID string, isFlagged bool, notes string, origin string, patternPiece string, totalWeight float32) error {
 	// Ensure the id begins with "cutpart_"
 	if err := SPEC_IDPrefix(cutPartID, "cutpart_"); err != nil {
 		return err
@@ -431,10 +669,22 @@ func (s *SmartContract) CreateCutPart(ctx contractapi.TransactionContextInterfac
 	if err := SPEC_IsNewAsset(ctx, cutPartID); err != nil {
 		return err
 	}
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org6MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "cutpart_"); err != nil {
+		return err
+	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
 		return err
 	}
+	drawnWeights = reportingUnits.ConvertWeights(drawnWeights)
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
@@ -448,12 +698,30 @@ func (s *SmartContract) CreateCutPart(ctx contractapi.TransactionContextInterfac
 		if err := SPEC_CheckLotAssetType(ctx, lotID, "finishedfabric_"); err != nil {
 			return err
 		}
+		// Ensure that the lot was not split or merged into other lots
+		if err := SPEC_IsNotRetired(ctx, lotID); err != nil {
+			return err
+		}
+		// Ensure that the lot is not in transit
+		if err := SPEC_IsOnSite(ctx, lotID); err != nil {
+			return err
+		}
+		// Ensure that the lot is held by the invoking organization
+		if err := SPEC_IsHeldByInvoker(ctx, lotID); err != nil {
+			return err
+		}
 	}
-	// Calculate the content weight by summing asset weights in content
-	contentWeight, err := GetContentWeight(ctx, content)
+	// Retrieve the weight drawn from each content lot, which defaults to the weight of this asset, and the content weight
+	draws, contentWeight, err := GetDrawnWeights(ctx, content, drawnWeights, totalWeight)
 	if err != nil {
 		return err
 	}
+	// Ensure that something is drawn from each content lot, that it was not already consumed and that it still holds the weight drawn from it
+	for i, lotID := range content {
+		if err := SPEC_IsAvailable(ctx, lotID, draws[i]); err != nil {
+			return err
+		}
+	}
 	// Calculate percentage difference between total weight and content weight
 	var weightDifference float32 = GetPercentageDifference(contentWeight, totalWeight)
 
@@ -463,21 +731,30 @@ func (s *SmartContract) CreateCutPart(ctx contractapi.TransactionContextInterfac
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	cutPart := CutPart{
 		Approval:         approval,
 		AssemblyDate:     assemblyDate,
 		Content:          content,
 		ContentWeight:    contentWeight,
 		CreatorID:        clientMSPID,
+		DocType:          "cutpart_",
 		FlagReason:       flagReason,
 		ID:               cutPartID,
 		IsFlagged:        isFlagged,
 		Notes:            notes,
 		Origin:           origin,
 		PatternPiece:     patternPiece,
+		SchemaVersion:    schemaVersion,
 		TotalWeight:      totalWeight,
-		UpdatedAt:        time.Now(),
+		UpdatedAt:        txTimestamp,
 		WeightDifference: weightDifference,
+		WeightUnit:       ledgerUnits.Weight,
 	}
 
 	// Ensure that the dates are in chronological order
@@ -492,10 +769,26 @@ func (s *SmartContract) CreateCutPart(ctx contractapi.TransactionContextInterfac
 	}
 
 	// Save the cutPart to the world state
-	return ctx.GetStub().PutState(cutPartID, cutPartsJSON)
+	if err := ctx.GetStub().PutState(cutPartID, cutPartsJSON); err != nil {
+		return err
+	}
+	// Index the cutPart by type
+	if err := index.PutType(ctx, cutPartID); err != nil {
+		return err
+	}
+	// Link each content lot to this cutPart
+	if err := PutParentLinks(ctx, cutPartID, content); err != nil {
+		return err
+	}
+	// Draw the weight taken by this cutPart from each content lot
+	if err := ConsumeLots(ctx, cutPartID, content, draws, len(drawnWeights) == 0, txTimestamp); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: cutPartID, AssetType: "cutpart_"})
 }
 
-// CreateButton issues a new asset (Button) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_Chronology
+// CreateButton issues a new asset (Button) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_Chronology
 func (s *SmartContract) CreateButton(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, flagReason string, buttonID string, isFlagged bool, notes string, origin string, totalWeight float32) error {
 	// Ensure the id begins with "button_"
 	if err := SPEC_IDPrefix(buttonID, "button_"); err != nil {
@@ -505,10 +798,21 @@ func (s *SmartContract) CreateButton(ctx contractapi.TransactionContextInterface
 	if err := SPEC_IsNewAsset(ctx, buttonID); err != nil {
 		return err
 	}
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org6MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "button_"); err != nil {
 		return err
 	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
@@ -520,17 +824,26 @@ func (s *SmartContract) CreateButton(ctx contractapi.TransactionContextInterface
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	button := Button{
-		Approval:     approval,
-		AssemblyDate: assemblyDate,
-		CreatorID:    clientMSPID,
-		FlagReason:   flagReason,
-		ID:           buttonID,
-		IsFlagged:    isFlagged,
-		Notes:        notes,
-		Origin:       origin,
-		TotalWeight:  totalWeight,
-		UpdatedAt:    time.Now(),
+		Approval:      approval,
+		AssemblyDate:  assemblyDate,
+		CreatorID:     clientMSPID,
+		DocType:       "button_",
+		FlagReason:    flagReason,
+		ID:            buttonID,
+		IsFlagged:     isFlagged,
+		Notes:         notes,
+		Origin:        origin,
+		SchemaVersion: schemaVersion,
+		TotalWeight:   totalWeight,
+		UpdatedAt:     txTimestamp,
+		WeightUnit:    ledgerUnits.Weight,
 	}
 
 	// Ensure that the dates are in chronological order
@@ -545,10 +858,18 @@ func (s *SmartContract) CreateButton(ctx contractapi.TransactionContextInterface
 	}
 
 	// Save the button to the world state
-	return ctx.GetStub().PutState(buttonID, buttonJSON)
+	if err := ctx.GetStub().PutState(buttonID, buttonJSON); err != nil {
+		return err
+	}
+	// Index the button by type
+	if err := index.PutType(ctx, buttonID); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: buttonID, AssetType: "button_"})
 }
 
-// CreateAssembledGarment issues a new asset (AssembledGarment) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_LotConsistency, 7) SPEC_LotConsistency, 8) SPEC_Chronology
+// CreateAssembledGarment issues a new asset (AssembledGarment) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_LotConsistency, 7) SPEC_Chronology
 func (s *SmartContract) CreateAssembledGarment(ctx contractapi.TransactionContextInterface, approval bool, assemblyDate time.Time, buttons []string, cutParts []string, flagReason string, assembledGarmentID string, isFlagged bool, notes string, origin string, totalWeight float32) error {
 	// Ensure the id begins with "assembledgarment_"
 	if err := SPEC_IDPrefix(assembledGarmentID, "assembledgarment_"); err != nil {
@@ -558,10 +879,21 @@ func (s *SmartContract) CreateAssembledGarment(ctx contractapi.TransactionContex
 	if err := SPEC_IsNewAsset(ctx, assembledGarmentID); err != nil {
 		return err
 	}
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org6MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "assembledgarment_"); err != nil {
+		return err
+	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
 		return err
 	}
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
@@ -601,6 +933,12 @@ func (s *SmartContract) CreateAssembledGarment(ctx contractapi.TransactionContex
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	assembledGarment := AssembledGarment{
 		Approval:         approval,
 		AssemblyDate:     assemblyDate,
@@ -608,14 +946,17 @@ func (s *SmartContract) CreateAssembledGarment(ctx contractapi.TransactionContex
 		ContentWeight:    contentWeight,
 		CreatorID:        clientMSPID,
 		CutParts:         cutParts,
+		DocType:          "assembledgarment_",
 		FlagReason:       flagReason,
 		ID:               assembledGarmentID,
 		IsFlagged:        isFlagged,
 		Notes:            notes,
 		Origin:           origin,
+		SchemaVersion:    schemaVersion,
 		TotalWeight:      totalWeight,
-		UpdatedAt:        time.Now(),
+		UpdatedAt:        txTimestamp,
 		WeightDifference: weightDifference,
+		WeightUnit:       ledgerUnits.Weight,
 	}
 
 	// Ensure that the dates are in chronological order
@@ -630,11 +971,23 @@ func (s *SmartContract) CreateAssembledGarment(ctx contractapi.TransactionContex
 	}
 
 	// Save the assembledGarment to the world state
-	return ctx.GetStub().PutState(assembledGarmentID, assembledGarmentJSON)
+	if err := ctx.GetStub().PutState(assembledGarmentID, assembledGarmentJSON); err != nil {
+		return err
+	}
+	// Index the assembledGarment by type
+	if err := index.PutType(ctx, assembledGarmentID); err != nil {
+		return err
+	}
+	// Link each button and cut part to this assembledGarment
+	if err := PutParentLinks(ctx, assembledGarmentID, append(buttons, cutParts...)); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: assembledGarmentID, AssetType: "assembledgarment_"})
 }
 
-// CreateCarton issues a new asset (Carton) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_LotConsistency, 7) SPEC_Chronology
-func (s *SmartContract) CreateCarton(ctx contractapi.TransactionContextInterface, allAssetsApproved bool, assemblyDate time.Time, content []string, customerID string, flagReason string, cartonID string, isFlagged bool, notes string, origin string, owner string, totalWeight float32) error {
+// CreateCarton issues a new asset (Carton) to the state with select attributes. The customer must be the retailer that placed the order, which is read from the admin channel, so the transaction must be endorsed by peers that have joined it. TransferCarton and TransferContainer only hand the carton over to this customer. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_LotConsistency, 7) SPEC_HasRole, 8) SPEC_IsOrderRetailer, 9) SPEC_IsAllowedToOwn, 10) SPEC_Chronology
+func (s *SmartContract) CreateCarton(ctx contractapi.TransactionContextInterface, allAssetsApproved bool, assemblyDate time.Time, content []string, customerID string, flagReason string, cartonID string, isFlagged bool, notes string, orderID string, origin string, owner string, totalWeight float32) error {
 	// Ensure the id begins with "carton_"
 	if err := SPEC_IDPrefix(cartonID, "carton_"); err != nil {
 		return err
@@ -643,10 +996,21 @@ func (s *SmartContract) CreateCarton(ctx contractapi.TransactionContextInterface
 	if err := SPEC_IsNewAsset(ctx, cartonID); err != nil {
 		return err
 	}
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org6MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "carton_"); err != nil {
 		return err
 	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
@@ -658,6 +1022,18 @@ func (s *SmartContract) CreateCarton(ctx contractapi.TransactionContextInterface
 	if err := SPEC_LotConsistency(ctx, content, "assembledgarment_"); err != nil {
 		return err
 	}
+	// Ensure that the carton is packed for an organization holding the retailer role
+	if err := SPEC_HasRole(rolePolicy, customerID, policy.Retailer); err != nil {
+		return err
+	}
+	// Ensure that the customer is the retailer that placed the order
+	if err := SPEC_IsOrderRetailer(ctx, orderID, customerID); err != nil {
+		return err
+	}
+	// Ensure that the owner is allowed to own cartons
+	if err := SPEC_IsAllowedToOwn(ctx, owner, rolePolicy.MSPIDsAllowedTo(policy.Own, "carton_")...); err != nil {
+		return err
+	}
 
 	// Calculate the content weight by summing asset weights in content
 	contentWeight, err := GetContentWeight(ctx, content)
@@ -667,14 +1043,18 @@ func (s *SmartContract) CreateCarton(ctx contractapi.TransactionContextInterface
 	// Calculate percentage difference between total weight and content weight
 	var weightDifference float32 = GetPercentageDifference(contentWeight, totalWeight)
 
-	//
-
 	// Retrieve the invoking organization's MSP ID
 	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
 	if err != nil {
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	carton := Carton{
 		AllAssetsApproved: allAssetsApproved,
 		AssemblyDate:      assemblyDate,
@@ -682,17 +1062,21 @@ func (s *SmartContract) CreateCarton(ctx contractapi.TransactionContextInterface
 		ContentWeight:     contentWeight,
 		CreatorID:         clientMSPID,
 		CustomerID:        customerID,
+		DocType:           "carton_",
 		FlagReason:        flagReason,
 		ID:                cartonID,
 		IsFlagged:         isFlagged,
 		Notes:             notes,
+		OrderID:           orderID,
 		Origin:            origin,
 		Owner:             owner,
 		PreviousOwner:     "Updated when ownership changes",
 		Quantity:          len(content),
+		SchemaVersion:     schemaVersion,
 		TotalWeight:       totalWeight,
-		UpdatedAt:         time.Now(),
+		UpdatedAt:         txTimestamp,
 		WeightDifference:  weightDifference,
+		WeightUnit:        ledgerUnits.Weight,
 	}
 
 	// Ensure that the dates are in chronological order
@@ -707,10 +1091,25 @@ func (s *SmartContract) CreateCarton(ctx contractapi.TransactionContextInterface
 	}
 
 	// Save the carton to the world state
-	return ctx.GetStub().PutState(cartonID, cartonJSON)
+	if err := ctx.GetStub().PutState(cartonID, cartonJSON); err != nil {
+		return err
+	}
+	// Index the carton by type and owner
+	if err := index.PutType(ctx, cartonID); err != nil {
+		return err
+	}
+	if err := index.PutOwner(ctx, carton.Owner, cartonID); err != nil {
+		return err
+	}
+	// Link each assembled garment to this carton
+	if err := PutParentLinks(ctx, cartonID, content); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: cartonID, AssetType: "carton_"})
 }
 
-// CreateContainer issues a new asset (Container) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsValidFlag, 5) SPEC_oDuplicateAssetInThisLot, 6) SPEC_LotConsistency, 7) SPEC_Chronology
+// CreateContainer issues a new asset (Container) to the state with select attributes. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsNewAsset, 3) SPEC_IsInvokedByAllowedRole, 4) SPEC_IsValidFlag, 5) SPEC_NoDuplicateAssetInThisLot, 6) SPEC_LotConsistency, 7) SPEC_Chronology
 func (s *SmartContract) CreateContainer(ctx contractapi.TransactionContextInterface, content []string, destinationPort string, flagReason string, containerID string, isFlagged bool, loadedAt time.Time, originPort string, totalWeight float32, vessel string) error {
 	// Ensure the id begins with "container_"
 	if err := SPEC_IDPrefix(containerID, "container_"); err != nil {
@@ -720,10 +1119,21 @@ func (s *SmartContract) CreateContainer(ctx contractapi.TransactionContextInterf
 	if err := SPEC_IsNewAsset(ctx, containerID); err != nil {
 		return err
 	}
-	// Ensure that the function is invoked by an allowed organization
-	if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org6MSP"); err != nil {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to create this asset type
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Create, "container_"); err != nil {
 		return err
 	}
+	// Convert the reported quantities to the ledger's units
+	reportingUnits, err := GetReportingUnits(ctx, rolePolicy)
+	if err != nil {
+		return err
+	}
+	totalWeight = reportingUnits.ConvertWeight(totalWeight)
 	// Ensure that the flagReason is provided if isFlagged is true
 	if err := SPEC_IsValidFlag(isFlagged, flagReason); err != nil {
 		return err
@@ -751,21 +1161,33 @@ func (s *SmartContract) CreateContainer(ctx contractapi.TransactionContextInterf
 		return fmt.Errorf("failed to get client MSPID: %v", err)
 	}
 
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	container := Container{
 		AssetType:        "carton",
 		Content:          content,
 		ContentWeight:    contentWeight,
 		CreatorID:        clientMSPID,
 		DestinationPort:  destinationPort,
+		DocType:          "container_",
 		FlagReason:       flagReason,
 		ID:               containerID,
 		IsFlagged:        isFlagged,
 		LoadedAt:         loadedAt,
 		OriginPort:       originPort,
+		Owner:            clientMSPID,
+		PreviousOwner:    "Updated when ownership changes",
+		SchemaVersion:    schemaVersion,
+		Status:           "loaded",
 		TotalWeight:      totalWeight,
-		UpdatedAt:        time.Now(),
+		UpdatedAt:        txTimestamp,
 		Vessel:           vessel,
 		WeightDifference: weightDifference,
+		WeightUnit:       ledgerUnits.Weight,
 	}
 
 	// Ensure that the dates are in chronological order
@@ -780,10 +1202,25 @@ func (s *SmartContract) CreateContainer(ctx contractapi.TransactionContextInterf
 	}
 
 	// Save the container to the world state
-	return ctx.GetStub().PutState(containerID, containerJSON)
+	if err := ctx.GetStub().PutState(containerID, containerJSON); err != nil {
+		return err
+	}
+	// Index the container by type and owner
+	if err := index.PutType(ctx, containerID); err != nil {
+		return err
+	}
+	if err := index.PutOwner(ctx, container.Owner, containerID); err != nil {
+		return err
+	}
+	// Link each carton to this container
+	if err := PutParentLinks(ctx, containerID, content); err != nil {
+		return err
+	}
+	// Emit the AssetCreated event
+	return events.Emit(ctx, events.AssetCreated, events.AssetCreatedData{AssetID: containerID, AssetType: "container_"})
 }
 
-// UpdateLotOwner updates the owner field of an asset in the world state. Contains the following specifications: 1) SPEC_IDPrefix, 2) SPEC_IsAssetOwner, 3) SPEC_IsInvokedByAllowedOrg, 4) SPEC_IsAllowedToOwn, 5) SPEC_Chronology
+// UpdateLotOwner updates the owner field of an asset in the world state. It does not move the lot, whose physical handover is recorded by DispatchLot and ReceiveLot. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole, 2) SPEC_IsAllowedToOwn, 3) SPEC_IsNotRetired
 func (s *SmartContract) UpdateLotOwner(ctx contractapi.TransactionContextInterface, lotID string, newOwner string) error {
 
 	// Retrieve the asset from the world state using the provided ID
@@ -801,76 +1238,136 @@ func (s *SmartContract) UpdateLotOwner(ctx contractapi.TransactionContextInterfa
 		return fmt.Errorf("failed to unmarshal JSON: %v", err)
 	}
 
-	// Switch on the assetIDPrefix to determine whether: 1) the function is invoked by an allowed organization, and 2) the new owner is allowed to own the lot based on the underlying asset
-	switch asset["AssetIDPrefix"] {
-	case "cottonbale_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org4MSP"); err != nil {
-			return err
-		}
-		if err := SPEC_IsAllowedToOwn(ctx, newOwner, "Org1MSP", "Org2MSP", "Org4MSP"); err != nil {
-			return err
-		}
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to transfer lots of the underlying asset type
+	assetIDPrefix, _ := asset["AssetIDPrefix"].(string)
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.TransferLot, assetIDPrefix); err != nil {
+		return err
+	}
+	// Ensure that the new owner is allowed to own lots of the underlying asset type
+	if err := SPEC_IsAllowedToOwn(ctx, newOwner, rolePolicy.MSPIDsAllowedTo(policy.OwnLot, assetIDPrefix)...); err != nil {
+		return err
+	}
+	// Ensure that the lot was not split or merged into other lots
+	if err := SPEC_IsNotRetired(ctx, lotID); err != nil {
+		return err
+	}
 
-	case "cottonyarn_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org4MSP", "Org5MSP"); err != nil {
-			return err
-		}
-		if err := SPEC_IsAllowedToOwn(ctx, newOwner, "Org1MSP", "Org2MSP", "Org4MSP", "Org5MSP"); err != nil {
-			return err
-		}
+	asset["PreviousOwner"] = asset["Owner"]
+	asset["Owner"] = newOwner
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	asset["UpdatedAt"] = txTimestamp
 
-	case "unfinishedfabric_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org5MSP"); err != nil {
-			return err
-		}
-		if err := SPEC_IsAllowedToOwn(ctx, newOwner, "Org1MSP", "Org2MSP", "Org5MSP"); err != nil {
-			return err
-		}
+	// Marshal the updated asset back to JSON
+	updatedAssetJSON, err := json.Marshal(asset)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	// Save the updated asset to the world state
+	if err := ctx.GetStub().PutState(lotID, updatedAssetJSON); err != nil {
+		return err
+	}
+	// Move the lot to the partition of its new owner
+	previousOwner, _ := asset["PreviousOwner"].(string)
+	if err := index.MoveOwner(ctx, previousOwner, newOwner, lotID); err != nil {
+		return err
+	}
+	// Emit the LotOwnershipTransferred event
+	return events.Emit(ctx, events.LotOwnershipTransferred, events.LotOwnershipTransferredData{LotID: lotID, NewOwner: newOwner, PreviousOwner: previousOwner})
+}
 
-	case "finishedfabric_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org5MSP", "Org6MSP"); err != nil {
-			return err
-		}
-		if err := SPEC_IsAllowedToOwn(ctx, newOwner, "Org1MSP", "Org2MSP", "Org5MSP", "Org6MSP"); err != nil {
-			return err
-		}
+// LotMembershipBackfill is the outcome of one page of BackfillLotMembership
+type LotMembershipBackfill struct {
+	AssetCount   int      `json:"AssetCount"` // assets whose lot membership key was recorded
+	HasMore      bool     `json:"HasMore"`    // lots remain, so the backfill must be invoked again
+	LotIDs       []string `json:"LotIDs"`     // lots whose content was recorded
+	NextStartKey string   `json:"NextStartKey"`
+}
 
-	case "cutpart_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org6MSP"); err != nil {
-			return err
+// BackfillLotMembership records the lot membership key of every asset stored in at most pageSize existing lots, in key order starting from startKey. It is meant to be invoked once on ledgers created before lot membership keys existed, again with the returned NextStartKey until HasMore is false. Lots retired by SplitLot or MergeLots and archived lots are skipped, as their content was released. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole, 2) SPEC_IsValidPageSize
+func (s *SmartContract) BackfillLotMembership(ctx contractapi.TransactionContextInterface, startKey string, pageSize int32) (*LotMembershipBackfill, error) {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Ensure that the function is invoked by an organization allowed to backfill lots
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Backfill, "lot_"); err != nil {
+		return nil, err
+	}
+	// Ensure that the page size is within bounds
+	if err := SPEC_IsValidPageSize(pageSize); err != nil {
+		return nil, err
+	}
+
+	// Read only the lots, i.e., the keys from "lot_" up to the next prefix, "lot`"
+	if startKey < "lot_" {
+		startKey = "lot_"
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, "lot`")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state by range: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	result := &LotMembershipBackfill{LotIDs: []string{}}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through results: %v", err)
 		}
-		if err := SPEC_IsAllowedToOwn(ctx, newOwner, "Org1MSP", "Org2MSP", "Org6MSP"); err != nil {
-			return err
+		if len(result.LotIDs) == int(pageSize) {
+			result.HasMore = true
+			result.NextStartKey = queryResponse.Key
+			break
 		}
 
-	case "button_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org6MSP"); err != nil {
-			return err
+		var lot Lot
+		err = json.Unmarshal(queryResponse.Value, &lot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal lot: %v", err)
 		}
-		if err := SPEC_IsAllowedToOwn(ctx, newOwner, "Org1MSP", "Org2MSP", "Org6MSP"); err != nil {
-			return err
+		// Leave the content of retired and archived lots released
+		if len(lot.SuccessorIDs) > 0 {
+			continue
 		}
-
-	case "assembledgarment_":
-		if err := SPEC_IsInvokedByAllowedOrg(ctx, "Org1MSP", "Org2MSP", "Org6MSP"); err != nil {
-			return err
+		tombstone, err := getTombstone(ctx, lot.ID)
+		if err != nil {
+			return nil, err
 		}
-		if err := SPEC_IsAllowedToOwn(ctx, newOwner, "Org1MSP", "Org2MSP", "Org6MSP"); err != nil {
-			return err
+		if tombstone != nil {
+			continue
 		}
+		if err := PutLotMembership(ctx, lot.ID, lot.Content); err != nil {
+			return nil, err
+		}
+		result.LotIDs = append(result.LotIDs, lot.ID)
+		result.AssetCount += len(lot.Content)
 	}
 
-	asset["PreviousOwner"] = asset["Owner"]
-	asset["Owner"] = newOwner
-	asset["UpdatedAt"] = time.Now()
+	return result, nil
+}
 
-	// Marshal the updated asset back to JSON
-	updatedAssetJSON, err := json.Marshal(asset)
-	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %v", err)
+// PutLotMembership records the given lot as the lot of each asset in content
+func PutLotMembership(ctx contractapi.TransactionContextInterface, lotID string, content []string) error {
+	for _, assetID := range content {
+		memberKey, err := ctx.GetStub().CreateCompositeKey(lotMembershipIndex, []string{assetID})
+		if err != nil {
+			return fmt.Errorf("failed to create lot membership key for asset %s: %v", assetID, err)
+		}
+		if err := ctx.GetStub().PutState(memberKey, []byte(lotID)); err != nil {
+			return fmt.Errorf("failed to save lot membership of asset %s: %v", assetID, err)
+		}
 	}
-	// Save the updated asset to the world state
-	return ctx.GetStub().PutState(lotID, updatedAssetJSON)
+	return nil
 }
 
 func main() {
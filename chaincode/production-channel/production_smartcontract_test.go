@@ -0,0 +1,675 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+)
+
+var (
+	// before is earlier than every transaction timestamp of the test ledger
+	before = chaincodetest.GenesisTime.AddDate(0, -1, 0)
+	// after is later than every transaction timestamp of the test ledger
+	after = chaincodetest.GenesisTime.AddDate(1, 0, 0)
+)
+
+// testCase is a single invocation of the contract by an organization, expected to fail with wantErr unless wantErr is empty
+type testCase struct {
+	name    string
+	mspID   string
+	invoke  func(s *SmartContract, ctx contractapi.TransactionContextInterface) error
+	wantErr string
+}
+
+// run submits each test case as a transaction against a fresh supply chain ledger and calls check after each successful one
+func run(t *testing.T, tests []testCase, check func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext)) {
+	t.Helper()
+	runOn(t, newSupplyChainLedger, tests, check)
+}
+
+// runOn is run against a fresh ledger built by newLedger
+func runOn(t *testing.T, newLedger func(t *testing.T) (*SmartContract, *chaincodetest.TransactionContext), tests []testCase, check func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext)) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, ctx := newLedger(t)
+			err := ctx.Submit(test.mspID, func() error { return test.invoke(s, ctx) })
+			checkErr(t, err, test.wantErr)
+			if test.wantErr == "" && check != nil {
+				check(t, s, ctx)
+			}
+		})
+	}
+}
+
+// submit runs fn as a transaction of the given organization and fails the test if it returns an error
+func submit(t *testing.T, ctx *chaincodetest.TransactionContext, mspID string, fn func() error) {
+	t.Helper()
+	if err := ctx.Submit(mspID, fn); err != nil {
+		t.Fatalf("setup transaction failed: %v", err)
+	}
+}
+
+// checkErr fails the test unless err matches wantErr, where an empty wantErr means no error
+func checkErr(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" && err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wantErr != "" && (err == nil || !strings.Contains(err.Error(), wantErr)) {
+		t.Fatalf("expected error containing %q, got %v", wantErr, err)
+	}
+}
+
+// checkCreated fails the test unless the asset was saved by the given organization, is linked to each of its children and was announced by an AssetCreated event
+func checkCreated(t *testing.T, ctx *chaincodetest.TransactionContext, id string, creatorID string, children ...string) map[string]interface{} {
+	t.Helper()
+	asset, err := getAssetMap(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset["CreatorID"] != creatorID {
+		t.Errorf("expected CreatorID %s, got %v", creatorID, asset["CreatorID"])
+	}
+	for _, childID := range children {
+		parentIDs, err := GetParentIDs(ctx, childID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !contains(parentIDs, id) {
+			t.Errorf("expected a link from %s to %s, got %v", childID, id, parentIDs)
+		}
+	}
+
+	last := ctx.Stub().LastEvent()
+	if last == nil {
+		t.Fatal("expected an AssetCreated event, got none")
+	}
+	event, err := events.Decode(last.EventName, last.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := event.Data.(*events.AssetCreatedData); !ok || data.AssetID != id || !strings.HasPrefix(id, data.AssetType) {
+		t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+	}
+	return asset
+}
+
+func contains(ids []string, id string) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// newSupplyChainLedger returns a ledger holding one asset of every type, each made from the one before it:
+// deployAdminChaincode stands in for the admin chaincode on the admin channel, which holds order_1, placed by the retailer Org1MSP,
+// order_2, placed by Org2MSP, and factory_1
+func deployAdminChaincode(ctx *chaincodetest.TransactionContext) {
+	assets := map[string]string{
+		"order_1":   `{"CreatorID":"Org1MSP","DocType":"order_","ID":"order_1","ReceiverID":"Org2MSP"}`,
+		"order_2":   `{"CreatorID":"Org2MSP","DocType":"order_","ID":"order_2","ReceiverID":"Org2MSP"}`,
+		"factory_1": `{"DocType":"factory_","ID":"factory_1","OrgMSPID":"Org4MSP"}`,
+	}
+	ctx.Stub().DeployChaincode(adminChannel, adminChaincode, func(args [][]byte) peer.Response {
+		asset, found := assets[string(args[1])]
+		if string(args[0]) != "GetAsset" || !found {
+			return shim.Error(fmt.Sprintf("the asset %s does not exist", args[1]))
+		}
+		return shim.Success([]byte(asset))
+	})
+}
+
+// cottonbale_1 and cottonbale_2 -> lot_1 -> cottonyarn_1 -> lot_2 -> unfinishedfabric_1 -> lot_3 -> finishedfabric_1 -> lot_4 -> cutpart_1,
+// and button_1 and cutpart_1 -> assembledgarment_1 -> carton_1 -> container_1.
+// cottonbale_3 is not in any lot, cottonbale_4 is flagged and cottonbale_5 is not approved
+func newSupplyChainLedger(t *testing.T) (*SmartContract, *chaincodetest.TransactionContext) {
+	t.Helper()
+	s := &SmartContract{}
+	ctx := chaincodetest.NewTransactionContext("production", "Org4MSP")
+	deployAdminChaincode(ctx)
+	// Lots are dispatched to, and received by, the organization that uses them
+	dispatched := before.Add(time.Hour)
+	received := dispatched.Add(time.Hour)
+
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "", "cottonbale_1", false, "", "Texas", "A", 480)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "", "cottonbale_2", false, "", "Texas", "A", 520)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "", "cottonbale_3", false, "", "Texas", "B", 500)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, before, "contaminated", "cottonbale_4", true, "", "Texas", "B", 500)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, false, before, "", "cottonbale_5", false, "", "Texas", "C", 500)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonbale_", []string{"cottonbale_1", "cottonbale_2"}, "Org4MSP", "", "lot_1", false, "", "Texas", "Org4MSP", 1000)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonYarnWithDraws(ctx, true, before, []string{"lot_1"}, []float32{900}, "", "cottonyarn_1", false, "", "Texas", 900, 30)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonyarn_", []string{"cottonyarn_1"}, "Vadodara, Gujarat, India", "", "lot_2", false, "", "Texas", "Org4MSP", 900)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.DispatchLot(ctx, dispatched, "lot_2", "Org5MSP")
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.ReceiveLot(ctx, "lot_2", received)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateUnfinishedFabricWithDraws(ctx, true, before, []string{"lot_2"}, []float32{850}, "", "unfinishedfabric_1", false, "", "Karachi", 1000, 850, 1.5)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateLot(ctx, before, "unfinishedfabric_", []string{"unfinishedfabric_1"}, "Org5MSP", "", "lot_3", false, "", "Karachi", "Org5MSP", 850)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateFinishedFabricWithDraws(ctx, true, before, []string{"lot_3"}, []float32{800}, "finishedfabric_1", "", false, 980, "", "Karachi", 800, 1.5)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateLot(ctx, before, "finishedfabric_", []string{"finishedfabric_1"}, "Ashulia, Bangladesh", "", "lot_4", false, "", "Karachi", "Org5MSP", 800)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.DispatchLot(ctx, dispatched, "lot_4", "Org6MSP")
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.ReceiveLot(ctx, "lot_4", received)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCutPartWithDraws(ctx, true, before, []string{"lot_4"}, []float32{0.3}, "", "cutpart_1", false, "", "Dhaka", "front panel", 0.3)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateButton(ctx, true, before, "", "button_1", false, "", "Dhaka", 0.01)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateAssembledGarment(ctx, true, before, []string{"button_1"}, []string{"cutpart_1"}, "", "assembledgarment_1", false, "", "Dhaka", 0.31)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateCarton(ctx, true, before, []string{"assembledgarment_1"}, "Org1MSP", "", "carton_1", false, "", "order_1", "Dhaka", "Org6MSP", 0.5)
+	})
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateContainer(ctx, []string{"carton_1"}, "Los Angeles", "", "container_1", false, before, "Chittagong", 1.0, "Maersk Alabama")
+	})
+	return s, ctx
+}
+
+func TestNewChaincode(t *testing.T) {
+	if _, err := contractapi.NewChaincode(&SmartContract{}); err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+}
+
+func TestCreateCottonBale(t *testing.T) {
+	create := func(id string, isFlagged bool, flagReason string, assemblyDate time.Time) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateCottonBale(ctx, true, assemblyDate, flagReason, id, isFlagged, "", "Texas", "A", 500)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org4MSP", create("cottonbale_9", false, "", before), ""},
+		{"wrong prefix", "Org4MSP", create("lot_9", false, "", before), "must start with 'cottonbale_'"},
+		{"already exists", "Org4MSP", create("cottonbale_1", false, "", before), "already exists"},
+		{"retailer", "Org1MSP", create("cottonbale_9", false, "", before), "not invoked by an allowed organization"},
+		{"full-package supplier", "Org6MSP", create("cottonbale_9", false, "", before), "not invoked by an allowed organization"},
+		{"flagged without reason", "Org4MSP", create("cottonbale_9", true, "", before), "flagReason must be provided"},
+		{"assembled in the future", "Org4MSP", create("cottonbale_9", false, "", after), "is not after"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkCreated(t, ctx, "cottonbale_9", "Org4MSP")
+	})
+}
+
+func TestCreateLot(t *testing.T) {
+	create := func(id string, prefix string, content ...string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateLot(ctx, before, prefix, content, "Org5MSP", "", id, false, "", "Texas", "Org4MSP", 500)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org4MSP", create("lot_9", "cottonbale_", "cottonbale_3"), ""},
+		{"wrong prefix", "Org4MSP", create("cottonbale_9", "cottonbale_", "cottonbale_3"), "must start with 'lot_'"},
+		{"already exists", "Org4MSP", create("lot_1", "cottonbale_", "cottonbale_3"), "already exists"},
+		{"not allowed for the asset type", "Org6MSP", create("lot_9", "cottonbale_", "cottonbale_3"), "not invoked by an allowed organization"},
+		{"flagged content", "Org4MSP", create("lot_9", "cottonbale_", "cottonbale_4"), "the asset cottonbale_4 is flagged"},
+		{"missing content", "Org4MSP", create("lot_9", "cottonbale_", "cottonbale_9"), "cottonbale_9 does not exist"},
+		{"empty content", "Org4MSP", create("lot_9", "cottonbale_"), "content list cannot be empty"},
+		{"duplicate content", "Org4MSP", create("lot_9", "cottonbale_", "cottonbale_3", "cottonbale_3"), "duplicate asset ID found: cottonbale_3"},
+		{"content of another type", "Org4MSP", create("lot_9", "cottonbale_", "cottonbale_3", "cottonyarn_1"), "does not have the correct prefix cottonbale_"},
+		{"content already in another lot", "Org4MSP", create("lot_9", "cottonbale_", "cottonbale_1"), "already stored in lot lot_1"},
+		{"unapproved content", "Org4MSP", create("lot_9", "cottonbale_", "cottonbale_5"), "cottonbale_5 has approval set to false"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		lot := checkCreated(t, ctx, "lot_9", "Org4MSP", "cottonbale_3")
+		if lot["ContentWeight"] != 500.0 || lot["Quantity"] != 1.0 {
+			t.Errorf("unexpected lot %v", lot)
+		}
+		// The lot membership key makes the bale unavailable to other lots
+		checkErr(t, SPEC_NoDuplicateAssetInState(ctx, "lot_10", []string{"cottonbale_3"}), "already stored in lot lot_9")
+	})
+}
+
+func TestCreateCottonYarn(t *testing.T) {
+	create := func(id string, content ...string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateCottonYarn(ctx, true, before, content, "", id, false, "", "Texas", 90, 30)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org4MSP", create("cottonyarn_9", "lot_1"), ""},
+		{"wrong prefix", "Org4MSP", create("lot_9", "lot_1"), "must start with 'cottonyarn_'"},
+		{"already exists", "Org4MSP", create("cottonyarn_1", "lot_1"), "already exists"},
+		{"textile mill", "Org5MSP", create("cottonyarn_9", "lot_1"), "not invoked by an allowed organization"},
+		{"duplicate content", "Org4MSP", create("cottonyarn_9", "lot_1", "lot_1"), "duplicate asset ID found: lot_1"},
+		{"lot of another type", "Org4MSP", create("cottonyarn_9", "lot_2"), "does not have the correct prefix cottonbale_"},
+		{"missing lot", "Org4MSP", create("cottonyarn_9", "lot_9"), "lot lot_9 does not exist"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		// The yarn draws its own weight from the 100 left in lot_1 after cottonyarn_1, while its content weight is that of lot_1
+		yarn := checkCreated(t, ctx, "cottonyarn_9", "Org4MSP", "lot_1")
+		if yarn["ContentWeight"] != 1000.0 {
+			t.Errorf("expected ContentWeight 1000, got %v", yarn["ContentWeight"])
+		}
+	})
+}
+
+func TestCreateUnfinishedFabric(t *testing.T) {
+	create := func(id string, content ...string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateUnfinishedFabric(ctx, true, before, content, "", id, false, "", "Karachi", 50, 45, 1.5)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org5MSP", create("unfinishedfabric_9", "lot_2"), ""},
+		{"wrong prefix", "Org5MSP", create("finishedfabric_9", "lot_2"), "must start with 'unfinishedfabric_'"},
+		{"already exists", "Org5MSP", create("unfinishedfabric_1", "lot_2"), "already exists"},
+		{"raw-material supplier", "Org4MSP", create("unfinishedfabric_9", "lot_2"), "not invoked by an allowed organization"},
+		{"duplicate content", "Org5MSP", create("unfinishedfabric_9", "lot_2", "lot_2"), "duplicate asset ID found: lot_2"},
+		{"lot of another type", "Org5MSP", create("unfinishedfabric_9", "lot_1"), "does not have the correct prefix cottonyarn_"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkCreated(t, ctx, "unfinishedfabric_9", "Org5MSP", "lot_2")
+	})
+}
+
+func TestCreateFinishedFabric(t *testing.T) {
+	create := func(id string, content ...string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateFinishedFabric(ctx, true, before, content, id, "", false, 50, "", "Karachi", 45, 1.5)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org5MSP", create("finishedfabric_9", "lot_3"), ""},
+		{"wrong prefix", "Org5MSP", create("unfinishedfabric_9", "lot_3"), "must start with 'finishedfabric_'"},
+		{"already exists", "Org5MSP", create("finishedfabric_1", "lot_3"), "already exists"},
+		{"full-package supplier", "Org6MSP", create("finishedfabric_9", "lot_3"), "not invoked by an allowed organization"},
+		{"duplicate content", "Org5MSP", create("finishedfabric_9", "lot_3", "lot_3"), "duplicate asset ID found: lot_3"},
+		{"lot of another type", "Org5MSP", create("finishedfabric_9", "lot_2"), "does not have the correct prefix unfinishedfabric_"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkCreated(t, ctx, "finishedfabric_9", "Org5MSP", "lot_3")
+	})
+}
+
+func TestCreateCutPart(t *testing.T) {
+	create := func(id string, content ...string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateCutPart(ctx, true, before, content, "", id, false, "", "Dhaka", "sleeve", 0.1)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org6MSP", create("cutpart_9", "lot_4"), ""},
+		{"wrong prefix", "Org6MSP", create("button_9", "lot_4"), "must start with 'cutpart_'"},
+		{"already exists", "Org6MSP", create("cutpart_1", "lot_4"), "already exists"},
+		{"retailer", "Org1MSP", create("cutpart_9", "lot_4"), "not invoked by an allowed organization"},
+		{"duplicate content", "Org6MSP", create("cutpart_9", "lot_4", "lot_4"), "duplicate asset ID found: lot_4"},
+		{"lot of another type", "Org6MSP", create("cutpart_9", "lot_3"), "does not have the correct prefix finishedfabric_"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkCreated(t, ctx, "cutpart_9", "Org6MSP", "lot_4")
+	})
+}
+
+func TestCreateButton(t *testing.T) {
+	create := func(id string, isFlagged bool, flagReason string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateButton(ctx, true, before, flagReason, id, isFlagged, "", "Dhaka", 0.01)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org6MSP", create("button_9", false, ""), ""},
+		{"wrong prefix", "Org6MSP", create("cutpart_9", false, ""), "must start with 'button_'"},
+		{"already exists", "Org6MSP", create("button_1", false, ""), "already exists"},
+		{"retailer", "Org1MSP", create("button_9", false, ""), "not invoked by an allowed organization"},
+		{"not flagged with reason", "Org6MSP", create("button_9", false, "cracked"), "flagReason must be empty or N/A"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkCreated(t, ctx, "button_9", "Org6MSP")
+	})
+}
+
+func TestCreateAssembledGarment(t *testing.T) {
+	create := func(id string, buttons []string, cutParts []string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateAssembledGarment(ctx, true, before, buttons, cutParts, "", id, false, "", "Dhaka", 0.31)
+		}
+	}
+	buttons, cutParts := []string{"button_1"}, []string{"cutpart_1"}
+	run(t, []testCase{
+		{"valid", "Org6MSP", create("assembledgarment_9", buttons, cutParts), ""},
+		{"wrong prefix", "Org6MSP", create("carton_9", buttons, cutParts), "must start with 'assembledgarment_'"},
+		{"already exists", "Org6MSP", create("assembledgarment_1", buttons, cutParts), "already exists"},
+		{"textile mill", "Org5MSP", create("assembledgarment_9", buttons, cutParts), "not invoked by an allowed organization"},
+		{"duplicate buttons", "Org6MSP", create("assembledgarment_9", []string{"button_1", "button_1"}, cutParts), "duplicate asset ID found: button_1"},
+		{"duplicate cut parts", "Org6MSP", create("assembledgarment_9", buttons, []string{"cutpart_1", "cutpart_1"}), "duplicate asset ID found: cutpart_1"},
+		{"cut part as button", "Org6MSP", create("assembledgarment_9", cutParts, cutParts), "does not have the correct prefix button_"},
+		{"missing cut part", "Org6MSP", create("assembledgarment_9", buttons, []string{"cutpart_9"}), "cutpart_9 does not exist"},
+		{"no buttons", "Org6MSP", create("assembledgarment_9", []string{}, cutParts), "content list cannot be empty"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		garment := checkCreated(t, ctx, "assembledgarment_9", "Org6MSP", "button_1", "cutpart_1")
+		if fmt.Sprintf("%.2f", garment["ContentWeight"]) != "0.31" {
+			t.Errorf("expected ContentWeight 0.31, got %v", garment["ContentWeight"])
+		}
+	})
+}
+
+func TestCreateCarton(t *testing.T) {
+	create := func(id string, content ...string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateCarton(ctx, true, before, content, "Org1MSP", "", id, false, "", "order_1", "Dhaka", "Org6MSP", 0.5)
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org6MSP", create("carton_9", "assembledgarment_1"), ""},
+		{"wrong prefix", "Org6MSP", create("container_9", "assembledgarment_1"), "must start with 'carton_'"},
+		{"already exists", "Org6MSP", create("carton_1", "assembledgarment_1"), "already exists"},
+		{"agent", "Org2MSP", create("carton_9", "assembledgarment_1"), "not invoked by an allowed organization"},
+		{"duplicate content", "Org6MSP", create("carton_9", "assembledgarment_1", "assembledgarment_1"), "duplicate asset ID found: assembledgarment_1"},
+		{"content of another type", "Org6MSP", create("carton_9", "cutpart_1"), "does not have the correct prefix assembledgarment_"},
+		{"customer is not a retailer", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateCarton(ctx, true, before, []string{"assembledgarment_1"}, "Org2MSP", "", "carton_9", false, "", "order_1", "Dhaka", "Org6MSP", 0.5)
+		}, "organization Org2MSP does not hold the retailer role"},
+		{"owner not allowed to own cartons", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateCarton(ctx, true, before, []string{"assembledgarment_1"}, "Org1MSP", "", "carton_9", false, "", "order_1", "Dhaka", "Org5MSP", 0.5)
+		}, "the proposed owner, Org5MSP, is not allowed to own this asset"},
+		{"customer did not place the order", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateCarton(ctx, true, before, []string{"assembledgarment_1"}, "Org1MSP", "", "carton_9", false, "", "order_2", "Dhaka", "Org6MSP", 0.5)
+		}, "order order_2 was placed by Org2MSP, not by the customer Org1MSP"},
+		{"unknown order", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateCarton(ctx, true, before, []string{"assembledgarment_1"}, "Org1MSP", "", "carton_9", false, "", "order_9", "Dhaka", "Org6MSP", 0.5)
+		}, "failed to read order order_9 from admin-channel: the asset order_9 does not exist"},
+		{"not an order", "Org6MSP", func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateCarton(ctx, true, before, []string{"assembledgarment_1"}, "Org1MSP", "", "carton_9", false, "", "factory_1", "Dhaka", "Org6MSP", 0.5)
+		}, "asset factory_1 on admin-channel is not an order"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		carton := checkCreated(t, ctx, "carton_9", "Org6MSP", "assembledgarment_1")
+		if carton["CustomerID"] != "Org1MSP" || carton["OrderID"] != "order_1" || carton["Quantity"] != 1.0 {
+			t.Errorf("unexpected carton %v", carton)
+		}
+	})
+}
+
+func TestCreateContainer(t *testing.T) {
+	create := func(id string, content ...string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.CreateContainer(ctx, content, "Los Angeles", "", id, false, before, "Chittagong", 1.0, "Maersk Alabama")
+		}
+	}
+	run(t, []testCase{
+		{"valid", "Org6MSP", create("container_9", "carton_1"), ""},
+		{"wrong prefix", "Org6MSP", create("carton_9", "carton_1"), "must start with 'container_'"},
+		{"already exists", "Org6MSP", create("container_1", "carton_1"), "already exists"},
+		{"auditor", "Org3MSP", create("container_9", "carton_1"), "not invoked by an allowed organization"},
+		{"duplicate content", "Org6MSP", create("container_9", "carton_1", "carton_1"), "duplicate asset ID found: carton_1"},
+		{"content of another type", "Org6MSP", create("container_9", "assembledgarment_1"), "does not have the correct prefix carton_"},
+		{"empty content", "Org6MSP", create("container_9"), "content list cannot be empty"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		checkCreated(t, ctx, "container_9", "Org6MSP", "carton_1")
+	})
+}
+
+// orgs lists every organization of the network
+var orgs = []string{"Org1MSP", "Org2MSP", "Org3MSP", "Org4MSP", "Org5MSP", "Org6MSP"}
+
+// lotOrgs lists, for each asset type a lot can hold, the organizations allowed to create it and the organizations allowed to own it
+var lotOrgs = map[string]struct{ creators, owners []string }{
+	"cottonbale_":       {[]string{"Org1MSP", "Org2MSP", "Org4MSP"}, []string{"Org1MSP", "Org2MSP", "Org4MSP"}},
+	"cottonyarn_":       {[]string{"Org1MSP", "Org2MSP", "Org4MSP"}, []string{"Org1MSP", "Org2MSP", "Org4MSP", "Org5MSP"}},
+	"unfinishedfabric_": {[]string{"Org1MSP", "Org2MSP", "Org5MSP"}, []string{"Org1MSP", "Org2MSP", "Org5MSP"}},
+	"finishedfabric_":   {[]string{"Org1MSP", "Org2MSP", "Org5MSP"}, []string{"Org1MSP", "Org2MSP", "Org5MSP", "Org6MSP"}},
+	"cutpart_":          {[]string{"Org1MSP", "Org2MSP", "Org6MSP"}, []string{"Org1MSP", "Org2MSP", "Org6MSP"}},
+	"button_":           {[]string{"Org1MSP", "Org2MSP", "Org6MSP"}, []string{"Org1MSP", "Org2MSP", "Org6MSP"}},
+	"assembledgarment_": {[]string{"Org1MSP", "Org2MSP", "Org6MSP"}, []string{"Org1MSP", "Org2MSP", "Org6MSP"}},
+}
+
+func TestCreateLotAccessControl(t *testing.T) {
+	for prefix, allowed := range lotOrgs {
+		for _, mspID := range orgs {
+			t.Run(prefix+mspID, func(t *testing.T) {
+				ctx := chaincodetest.NewTransactionContext("production", mspID)
+				// An allowed organization gets past the access check and fails on the empty content list instead
+				wantErr := "not invoked by an allowed organization"
+				if contains(allowed.creators, mspID) {
+					wantErr = "content list cannot be empty"
+				}
+				err := ctx.Submit(mspID, func() error {
+					return (&SmartContract{}).CreateLot(ctx, before, prefix, []string{}, "", "", "lot_9", false, "", "", "", 0)
+				})
+				checkErr(t, err, wantErr)
+			})
+		}
+	}
+}
+
+func TestUpdateLotOwnerAccessControl(t *testing.T) {
+	for prefix, allowed := range lotOrgs {
+		lotJSON, _ := json.Marshal(Lot{AssetIDPrefix: prefix, Content: []string{}, ID: "lot_9", Owner: "Org2MSP"})
+		for _, mspID := range orgs {
+			t.Run("invoked by "+prefix+mspID, func(t *testing.T) {
+				ctx := chaincodetest.NewTransactionContext("production", mspID)
+				ctx.Stub().Seed("lot_9", lotJSON)
+				wantErr := "not invoked by an allowed organization"
+				if contains(allowed.owners, mspID) {
+					wantErr = ""
+				}
+				err := ctx.Submit(mspID, func() error { return (&SmartContract{}).UpdateLotOwner(ctx, "lot_9", "Org1MSP") })
+				checkErr(t, err, wantErr)
+			})
+			t.Run("owned by "+prefix+mspID, func(t *testing.T) {
+				ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+				ctx.Stub().Seed("lot_9", lotJSON)
+				wantErr := "is not allowed to own this asset"
+				if contains(allowed.owners, mspID) {
+					wantErr = ""
+				}
+				err := ctx.Submit("Org1MSP", func() error { return (&SmartContract{}).UpdateLotOwner(ctx, "lot_9", mspID) })
+				checkErr(t, err, wantErr)
+				if wantErr != "" {
+					return
+				}
+				lot, _ := getAssetMap(ctx, "lot_9")
+				if lot["Owner"] != mspID || lot["PreviousOwner"] != "Org2MSP" {
+					t.Errorf("unexpected owners %v -> %v", lot["PreviousOwner"], lot["Owner"])
+				}
+			})
+		}
+	}
+}
+
+func TestAccessControl(t *testing.T) {
+	recall := func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.InitiateRecall(ctx, "cottonbale_1", "contaminated")
+		return err
+	}
+	backfill := func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		_, err := s.BackfillLotMembership(ctx, "", 10)
+		return err
+	}
+	run(t, []testCase{
+		{"retailer initiates recall", "Org1MSP", recall, ""},
+		{"agent initiates recall", "Org2MSP", recall, ""},
+		{"auditor initiates recall", "Org3MSP", recall, ""},
+		{"raw-material supplier initiates recall", "Org4MSP", recall, "not invoked by an allowed organization"},
+		{"full-package supplier initiates recall", "Org6MSP", recall, "not invoked by an allowed organization"},
+		{"retailer backfills lot membership", "Org1MSP", backfill, ""},
+		{"agent backfills lot membership", "Org2MSP", backfill, ""},
+		{"full-package supplier backfills lot membership", "Org6MSP", backfill, "not invoked by an allowed organization"},
+	}, nil)
+}
+
+func TestCloseRecallAccessControl(t *testing.T) {
+	for _, mspID := range orgs {
+		t.Run(mspID, func(t *testing.T) {
+			s, ctx := newSupplyChainLedger(t)
+			var recall *Recall
+			submit(t, ctx, "Org1MSP", func() error {
+				var err error
+				recall, err = s.InitiateRecall(ctx, "lot_1", "contaminated")
+				return err
+			})
+			wantErr := "not invoked by an allowed organization"
+			if contains([]string{"Org1MSP", "Org2MSP", "Org3MSP"}, mspID) {
+				wantErr = ""
+			}
+			err := ctx.Submit(mspID, func() error { return s.CloseRecall(ctx, recall.ID, "bales destroyed") })
+			checkErr(t, err, wantErr)
+		})
+	}
+}
+
+// TestInitProductionLedgerScript replays test-network/initProductionLedger_200.sh, which hands lots over with UpdateLotOwner
+// and lets every asset draw its own weight from its content lots
+// newInitScriptLedger replays test-network/initProductionLedger_200.sh, which ships an order of 200 shirts in container_1
+func newInitScriptLedger(t *testing.T) (*SmartContract, *chaincodetest.TransactionContext) {
+	t.Helper()
+	s := &SmartContract{}
+	ctx := chaincodetest.NewTransactionContext("production", "Org4MSP")
+	deployAdminChaincode(ctx)
+	date := func(day int, hour int, min int) time.Time {
+		return time.Date(2024, time.July, day, hour, min, 0, 0, time.UTC)
+	}
+	ids := func(prefix string, first int, last int) []string {
+		list := []string{}
+		for i := first; i <= last; i++ {
+			list = append(list, fmt.Sprintf("%s%d", prefix, i))
+		}
+		return list
+	}
+	const india = "Vadodara, Gujarat, India"
+	const bangladesh = "Ashulia, Bangladesh"
+
+	// 1. Cotton bale and its lot (org4)
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateCottonBale(ctx, true, date(1, 10, 0), "", "cottonbale_1", false, "", india, "Medium", 480)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, date(1, 11, 0), "cottonbale_", []string{"cottonbale_1"}, india, "", "lot_1", false, "", india, "Org4MSP", 480)
+	})
+	// 2. Cotton yarn and its lots (org4)
+	for _, id := range ids("cottonyarn_", 1, 310) {
+		submit(t, ctx, "Org4MSP", func() error {
+			return s.CreateCottonYarn(ctx, true, date(3, 10, 0), []string{"lot_1"}, "", id, false, "", india, 0.397, 30)
+		})
+	}
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, date(4, 11, 0), "cottonyarn_", ids("cottonyarn_", 1, 155), india, "", "lot_2", false, "", india, "Org4MSP", 61.535)
+	})
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, date(4, 11, 10), "cottonyarn_", ids("cottonyarn_", 156, 310), india, "", "lot_3", false, "", india, "Org4MSP", 61.535)
+	})
+	// 3. Yarn lots sold to the textiles manufacturer (org5), dispatched by org4 and received by org5
+	for _, id := range []string{"lot_2", "lot_3"} {
+		submit(t, ctx, "Org5MSP", func() error { return s.UpdateLotOwner(ctx, id, "Org5MSP") })
+		submit(t, ctx, "Org4MSP", func() error { return s.DispatchLot(ctx, date(5, 9, 0), id, "Org5MSP") })
+		submit(t, ctx, "Org5MSP", func() error { return s.ReceiveLot(ctx, id, date(5, 15, 0)) })
+	}
+	// 4. Unfinished fabric and its lots (org5)
+	for i, content := range [][]string{{"lot_2"}, {"lot_2"}, {"lot_2"}, {"lot_2", "lot_3"}, {"lot_3"}, {"lot_3"}, {"lot_3"}} {
+		submit(t, ctx, "Org5MSP", func() error {
+			return s.CreateUnfinishedFabric(ctx, true, date(6, 10, 0), content, "", fmt.Sprintf("unfinishedfabric_%d", i+1), false, "", india, 50, 16.74, 60)
+		})
+	}
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateLot(ctx, date(7, 11, 0), "unfinishedfabric_", ids("unfinishedfabric_", 1, 4), india, "", "lot_4", false, "", india, "Org5MSP", 66.96)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateLot(ctx, date(7, 11, 10), "unfinishedfabric_", ids("unfinishedfabric_", 5, 7), india, "", "lot_5", false, "", india, "Org5MSP", 50.22)
+	})
+	// 5. Finished fabric and its lots (org5)
+	for i, content := range [][]string{{"lot_4"}, {"lot_4"}, {"lot_4"}, {"lot_4"}, {"lot_5"}, {"lot_5"}, {"lot_5"}} {
+		submit(t, ctx, "Org5MSP", func() error {
+			return s.CreateFinishedFabric(ctx, true, date(9, 10, 0), content, fmt.Sprintf("finishedfabric_%d", i+1), "", false, 47.5, "", india, 15.9025, 58.8)
+		})
+	}
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateLot(ctx, date(10, 11, 0), "finishedfabric_", ids("finishedfabric_", 1, 4), bangladesh, "", "lot_6", false, "", india, "Org5MSP", 63.61)
+	})
+	submit(t, ctx, "Org5MSP", func() error {
+		return s.CreateLot(ctx, date(10, 11, 10), "finishedfabric_", ids("finishedfabric_", 5, 7), bangladesh, "", "lot_7", false, "", india, "Org5MSP", 47.71)
+	})
+	// 6. Fabric lots sold to the full package supplier (org6), dispatched by org5 and received by org6
+	for _, id := range []string{"lot_6", "lot_7"} {
+		submit(t, ctx, "Org6MSP", func() error { return s.UpdateLotOwner(ctx, id, "Org6MSP") })
+		submit(t, ctx, "Org5MSP", func() error { return s.DispatchLot(ctx, date(11, 9, 0), id, "Org6MSP") })
+		submit(t, ctx, "Org6MSP", func() error { return s.ReceiveLot(ctx, id, date(12, 15, 0)) })
+	}
+	// 7. and 8. Cut parts and buttons (org6)
+	parts := []string{"front_panel", "back_panel", "left_sleeve", "right_sleeve", "collar", "front_pocket"}
+	for i, id := range ids("cutpart_", 1, 1200) {
+		cutLot := "lot_6"
+		if i >= 685 {
+			cutLot = "lot_7"
+		}
+		submit(t, ctx, "Org6MSP", func() error {
+			return s.CreateCutPart(ctx, true, date(13, 11, 10), []string{cutLot}, "", id, false, "", bangladesh, parts[i/200], 0.091)
+		})
+	}
+	for _, id := range ids("button_", 1, 2000) {
+		submit(t, ctx, "Org6MSP", func() error {
+			return s.CreateButton(ctx, true, date(14, 11, 0), "", id, false, "", bangladesh, 0.00165)
+		})
+	}
+	// 9. Shirts (org6)
+	for shirt := 1; shirt <= 200; shirt++ {
+		cutParts := []string{}
+		for offset := 0; offset < 1200; offset += 200 {
+			cutParts = append(cutParts, fmt.Sprintf("cutpart_%d", offset+shirt))
+		}
+		buttons := ids("button_", (shirt-1)*10+1, shirt*10)
+		submit(t, ctx, "Org6MSP", func() error {
+			return s.CreateAssembledGarment(ctx, true, date(16+(shirt-1)/100, 10, 0), buttons, cutParts, "", fmt.Sprintf("assembledgarment_%d", shirt), false, "", bangladesh, 0.554)
+		})
+	}
+	// 10. Cartons of 50 shirts (org6)
+	for carton := 1; carton <= 4; carton++ {
+		submit(t, ctx, "Org6MSP", func() error {
+			return s.CreateCarton(ctx, true, date(18, 10, 0), ids("assembledgarment_", (carton-1)*50+1, carton*50), "Org1MSP", "", fmt.Sprintf("carton_%d", carton), false, "", "order_1", bangladesh, "Org6MSP", 32)
+		})
+	}
+	// 11. Container (org6)
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateContainer(ctx, ids("carton_", 1, 4), "Los Angeles, California, USA", "", "container_1", false, date(20, 8, 0), "Chittagong, Bangladesh", 38448, "EXAMPLE Hong Kong")
+	})
+	return s, ctx
+}
+
+func TestInitProductionLedgerScript(t *testing.T) {
+	_, ctx := newInitScriptLedger(t)
+	checkCreated(t, ctx, "container_1", "Org6MSP", "carton_1", "carton_2", "carton_3", "carton_4")
+	for _, id := range []string{"lot_2", "lot_3", "lot_6", "lot_7"} {
+		lot, err := getLot(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lot.Custodian != lot.Owner || lot.Location != lot.Destination || lot.TransitState != "on_site" {
+			t.Errorf("expected %s to be held by its owner %s at %s, got %s at %s (%s)", id, lot.Owner, lot.Destination, lot.Custodian, lot.Location, lot.TransitState)
+		}
+	}
+}
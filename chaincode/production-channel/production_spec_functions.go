@@ -3,54 +3,38 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/spec"
 )
 
-// SPEC_IsNewAsset ensures that the asset does not already exist
-func SPEC_IsNewAsset(ctx contractapi.TransactionContextInterface, id string) error {
-	exists, err := ctx.GetStub().GetState(id)
-	if err != nil {
-		return fmt.Errorf("failed to read from world state: %v", err)
-	}
-	if exists != nil {
-		return fmt.Errorf("the asset %s already exists", id)
-	}
-	return nil
-}
-
-// SPEC_AssetExists ensures that the asset exists
-func SPEC_AssetExists(ctx contractapi.TransactionContextInterface, assetID string) error {
-	assetJSON, err := ctx.GetStub().GetState(assetID)
-	if err != nil {
-		return fmt.Errorf("failed to read from world state: %v", err)
-	}
-	if assetJSON == nil {
-		return fmt.Errorf("the asset %s does not exist", assetID)
-	}
-	return nil
-}
-
-// SPEC_IDPrefix ensures that the id starts with the correct prefix
-func SPEC_IDPrefix(id string, prefix string) error {
-	if !strings.HasPrefix(id, prefix) {
-		return fmt.Errorf("the id '%s' must start with '%s'", id, prefix)
-	}
-
-	return nil
-}
+// The following specifications are shared by the admin-channel and production-channel contracts, see the spec package
+var (
+	SPEC_IsNewAsset             = spec.IsNewAsset
+	SPEC_AssetExists            = spec.AssetExists
+	SPEC_IDPrefix               = spec.IDPrefix
+	SPEC_IsValidFlag            = spec.IsValidFlag
+	SPEC_Chronology             = spec.Chronology
+	SPEC_IsInvokedByAllowedOrg  = spec.IsInvokedByAllowedOrg
+	SPEC_IsInvokedByAllowedRole = spec.IsInvokedByAllowedRole
+	SPEC_IsValidRolePolicy      = spec.IsValidRolePolicy
+	SPEC_IsValidMigration       = spec.IsValidMigration
+	SPEC_IsValidBatchSize       = spec.IsValidBatchSize
+	SPEC_IsValidPageSize        = spec.IsValidPageSize
+	SPEC_IsValidSelector        = spec.IsValidSelector
+)
 
-// SPEC_IsValidFlag ensures that (the flagReason is provided if isFlagged is true) and (the flagReason is empty or N/A if isFlagged is false)
-func SPEC_IsValidFlag(isFlagged bool, flagReason string) error {
-	if isFlagged && (len(flagReason) == 0 || flagReason == "N/A") {
-		return fmt.Errorf("flagReason must be provided if isFlagged is true")
-	} else if !isFlagged && (len(flagReason) != 0 && flagReason != "N/A") {
-		return fmt.Errorf("flagReason must be empty or N/A if isFlagged is false")
-	} else {
-		return nil
+// SPEC_IDPrefixOneOf ensures that the id starts with one of the allowed prefixes
+func SPEC_IDPrefixOneOf(id string, prefixes ...string) error {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(id, prefix) {
+			return nil
+		}
 	}
+	return fmt.Errorf("the id '%s' must start with one of %v", id, prefixes)
 }
 
 // SPEC_IsNotFlagged ensures that the asset is not flagged
@@ -80,32 +64,7 @@ func SPEC_IsNotFlagged(ctx contractapi.TransactionContextInterface, assetID stri
 	return nil
 }
 
-// SPEC_Chronoloy ensures that the provided dates are in chronological order
-func SPEC_Chronology(dates ...time.Time) error {
-	for i := 1; i < len(dates); i++ {
-		if !dates[i].After(dates[i-1]) {
-			return fmt.Errorf("date %v is not after date %v", dates[i], dates[i-1])
-		}
-	}
-	return nil
-}
-
-// SPEC_IsInvokedByAllowedOrg checks if the function is invoked by one of the allowed OrgMSPIDs
-func SPEC_IsInvokedByAllowedOrg(ctx contractapi.TransactionContextInterface, allowedOrgMSPIDs ...string) error {
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
-	if err != nil {
-		return fmt.Errorf("failed to get client MSPID: %v", err)
-	}
-
-	for _, orgMSPID := range allowedOrgMSPIDs {
-		if clientMSPID == orgMSPID {
-			return nil
-		}
-	}
-	return fmt.Errorf("the function is not invoked by an allowed organization. Invoked by: %s. Allowed organizations: %v", clientMSPID, allowedOrgMSPIDs)
-}
-
-// SPEC_LotConsistency ensures that the content list is not empty, that each asset in the content list exists in the ledger, and that each asset has the correct prefix
+// SPEC_LotConsistency ensures that the content list is not empty, that each asset in the content list exists in the ledger and is not archived, and that each asset has the correct prefix
 func SPEC_LotConsistency(ctx contractapi.TransactionContextInterface, content []string, assetIDPrefix string) error {
 	// Check if content list is empty
 	if len(content) == 0 {
@@ -123,44 +82,71 @@ func SPEC_LotConsistency(ctx contractapi.TransactionContextInterface, content []
 		if err := SPEC_AssetExists(ctx, assetID); err != nil {
 			return fmt.Errorf("asset %s does not exist or could not be accessed: %v", assetID, err)
 		}
+
+		// Check if the asset was archived
+		if err := SPEC_IsNotArchived(ctx, assetID); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// SPEC_NoDuplicateAssetInLots ensures that each asset in the given lot is not stored in any other lot's contents, i.e., the state
-func SPEC_NoDuplicateAssetInState(ctx contractapi.TransactionContextInterface, currentLotID string, content []string) error {
-	// Get all lots from the ledger
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+// SPEC_IsNotArchived ensures that the asset has no tombstone
+func SPEC_IsNotArchived(ctx contractapi.TransactionContextInterface, assetID string) error {
+	tombstone, err := getTombstone(ctx, assetID)
 	if err != nil {
-		return fmt.Errorf("failed to get all lots from world state: %v", err)
+		return err
+	}
+	if tombstone != nil {
+		return fmt.Errorf("asset %s was archived by %s: %s", assetID, tombstone.ArchivedBy, tombstone.Reason)
 	}
-	defer resultsIterator.Close()
+	return nil
+}
 
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
+// SPEC_IsUnreferenced ensures that no asset that is not archived itself was made from or holds the asset, e.g., a lot holding a cotton bale
+func SPEC_IsUnreferenced(ctx contractapi.TransactionContextInterface, assetID string) error {
+	parentIDs, err := GetParentIDs(ctx, assetID)
+	if err != nil {
+		return err
+	}
+	referencedBy := []string{}
+	for _, parentID := range parentIDs {
+		tombstone, err := getTombstone(ctx, parentID)
 		if err != nil {
-			return fmt.Errorf("failed to iterate through lots: %v", err)
+			return err
+		}
+		if tombstone == nil {
+			referencedBy = append(referencedBy, parentID)
 		}
+	}
+	if len(referencedBy) > 0 {
+		return fmt.Errorf("asset %s is still referenced by %v", assetID, referencedBy)
+	}
+	return nil
+}
 
-		var checkLot Lot
-		err = json.Unmarshal(queryResponse.Value, &checkLot)
+// SPEC_NoDuplicateAssetInState ensures that each asset in the given lot is not stored in any other lot's contents, i.e., the state, by reading the lot membership key of each asset. An asset may be stored in one of the predecessor lots that the given lot is split or merged from
+func SPEC_NoDuplicateAssetInState(ctx contractapi.TransactionContextInterface, currentLotID string, content []string, predecessorIDs ...string) error {
+	for _, assetID := range content {
+		memberKey, err := ctx.GetStub().CreateCompositeKey(lotMembershipIndex, []string{assetID})
 		if err != nil {
-			return fmt.Errorf("failed to unmarshal lot: %v", err)
+			return fmt.Errorf("failed to create lot membership key for asset %s: %v", assetID, err)
 		}
-
-		// Skip the lot being checked
-		if checkLot.ID == currentLotID {
-			continue
+		existingLotID, err := ctx.GetStub().GetState(memberKey)
+		if err != nil {
+			return fmt.Errorf("failed to read lot membership of asset %s: %v", assetID, err)
 		}
 
 		// Check for duplicate assets in state's existing other lots
-		for _, assetID := range content {
-			for _, existingAssetID := range checkLot.Content {
-				if assetID == existingAssetID {
-					return fmt.Errorf("asset %s cannot be placed in lot %s because it is already stored in lot %s", assetID, currentLotID, checkLot.ID)
-				}
+		isPredecessor := false
+		for _, predecessorID := range predecessorIDs {
+			if string(existingLotID) == predecessorID {
+				isPredecessor = true
 			}
 		}
+		if existingLotID != nil && string(existingLotID) != currentLotID && !isPredecessor {
+			return fmt.Errorf("asset %s cannot be placed in lot %s because it is already stored in lot %s", assetID, currentLotID, string(existingLotID))
+		}
 	}
 
 	return nil
@@ -199,6 +185,112 @@ func SPEC_CheckLotAssetType(ctx contractapi.TransactionContextInterface, lotID s
 	return nil
 }
 
+// SPEC_IsNotRetired ensures that the lot was not split or merged into other lots
+func SPEC_IsNotRetired(ctx contractapi.TransactionContextInterface, lotID string) error {
+	lot, err := getLot(ctx, lotID)
+	if err != nil {
+		return err
+	}
+	if len(lot.SuccessorIDs) > 0 {
+		return fmt.Errorf("lot %s is retired and was succeeded by %v", lotID, lot.SuccessorIDs)
+	}
+	return nil
+}
+
+// SPEC_IsOnSite ensures that the lot is not in transit, i.e., that it was received at the end of its last dispatch
+func SPEC_IsOnSite(ctx contractapi.TransactionContextInterface, lotID string) error {
+	lot, err := getLot(ctx, lotID)
+	if err != nil {
+		return err
+	}
+	if lot.TransitState == "in_transit" {
+		return fmt.Errorf("lot %s is in transit to %s and cannot be used until %s receives it", lotID, lot.Destination, lot.ReceiverID)
+	}
+	return nil
+}
+
+// SPEC_IsHeldByInvoker ensures that the invoking organization physically holds the lot, i.e., that it is the custodian of the lot, or its owner for lots created before custody was tracked
+func SPEC_IsHeldByInvoker(ctx contractapi.TransactionContextInterface, lotID string) error {
+	lot, err := getLot(ctx, lotID)
+	if err != nil {
+		return err
+	}
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+	if custodian := getCustodian(lot); clientMSPID != custodian {
+		return fmt.Errorf("lot %s is held by %s and cannot be used by %s", lotID, custodian, clientMSPID)
+	}
+	return nil
+}
+
+// SPEC_IsInTransit ensures that the lot was dispatched and not received yet
+func SPEC_IsInTransit(lot *Lot) error {
+	if lot.TransitState != "in_transit" {
+		return fmt.Errorf("lot %s is not in transit", lot.ID)
+	}
+	return nil
+}
+
+// SPEC_DestinationMatchesSite ensures that the destination of the lot is the registered site of the receiving organization
+func SPEC_DestinationMatchesSite(rolePolicy *policy.RolePolicy, lot *Lot, receiverID string) error {
+	site, ok := rolePolicy.Sites[receiverID]
+	if !ok {
+		return fmt.Errorf("organization %s has no registered site", receiverID)
+	}
+	if lot.Destination != site {
+		return fmt.Errorf("destination %s of lot %s does not match the registered site %s of %s", lot.Destination, lot.ID, site, receiverID)
+	}
+	return nil
+}
+
+// SPEC_IsAvailable ensures that the lot was not consumed and that the drawn weight is positive and still available in the lot
+func SPEC_IsAvailable(ctx contractapi.TransactionContextInterface, lotID string, drawnWeight float32) error {
+	lot, err := getLot(ctx, lotID)
+	if err != nil {
+		return err
+	}
+	if lot.ConsumptionState == "consumed" {
+		return fmt.Errorf("lot %s was already consumed by %v", lotID, consumerIDs(lot))
+	}
+	if drawnWeight < 0 {
+		return fmt.Errorf("the weight drawn from lot %s cannot be negative, got %v", lotID, drawnWeight)
+	}
+	if drawnWeight == 0 {
+		return fmt.Errorf("nothing is drawn from lot %s", lotID)
+	}
+	if availableWeight := lot.TotalWeight - lot.ConsumedWeight; drawnWeight > availableWeight {
+		return fmt.Errorf("cannot draw %v from lot %s because only %v of its %v is still available", drawnWeight, lotID, availableWeight, lot.TotalWeight)
+	}
+	return nil
+}
+
+// SPEC_ContentPreserved ensures that the portions hold every asset of the content exactly once and no other asset
+func SPEC_ContentPreserved(content []string, portions [][]string) error {
+	remaining := make(map[string]bool)
+	for _, assetID := range content {
+		remaining[assetID] = true
+	}
+	for _, portion := range portions {
+		if len(portion) == 0 {
+			return fmt.Errorf("content list cannot be empty")
+		}
+		for _, assetID := range portion {
+			if !remaining[assetID] {
+				return fmt.Errorf("asset %s is not in the content or is listed more than once", assetID)
+			}
+			delete(remaining, assetID)
+		}
+	}
+	for _, assetID := range content {
+		if remaining[assetID] {
+			return fmt.Errorf("asset %s is missing from the portions", assetID)
+		}
+	}
+	return nil
+}
+
 // SPEC_NoDuplicateAssetInThisLot ensures that there are no duplicate asset IDs in the content list
 func SPEC_NoDuplicateAssetInThisLot(content []string) error {
 	assetMap := make(map[string]bool)
@@ -245,6 +337,34 @@ func SPEC_CheckAssetsApproval(ctx contractapi.TransactionContextInterface, conte
 	return nil
 }
 
+// SPEC_HasRole ensures that the organization is a member of the role, e.g., that the customer of a carton is a retailer
+func SPEC_HasRole(rolePolicy *policy.RolePolicy, mspID string, role string) error {
+	if !rolePolicy.HasRole(mspID, role) {
+		return fmt.Errorf("organization %s does not hold the %s role", mspID, role)
+	}
+	return nil
+}
+
+// SPEC_IsOrderRetailer ensures that a carton is packed for the retailer that placed the order it fulfils
+func SPEC_IsOrderRetailer(ctx contractapi.TransactionContextInterface, orderID string, customerID string) error {
+	order, err := ReadOrder(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if order.CreatorID != customerID {
+		return fmt.Errorf("order %s was placed by %s, not by the customer %s", orderID, order.CreatorID, customerID)
+	}
+	return nil
+}
+
+// SPEC_IsCustomer ensures that a carton handed over to a retailer goes to the retailer it was packed for
+func SPEC_IsCustomer(rolePolicy *policy.RolePolicy, carton *Carton, newOwner string) error {
+	if rolePolicy.HasRole(newOwner, policy.Retailer) && newOwner != carton.CustomerID {
+		return fmt.Errorf("carton %s was packed for %s and cannot be handed over to %s", carton.ID, carton.CustomerID, newOwner)
+	}
+	return nil
+}
+
 // SPEC_IsAllowedToOwn checks if the new owner is allowed to own the asset based on the allowedOrgMSPIDs
 func SPEC_IsAllowedToOwn(ctx contractapi.TransactionContextInterface, newOwner string, allowedOrgMSPIDs ...string) error {
 
@@ -253,6 +373,96 @@ func SPEC_IsAllowedToOwn(ctx contractapi.TransactionContextInterface, newOwner s
 			return nil
 		}
 	}
-	return fmt.Errorf("the proposed owner, %s, is not allowed to own this lot.  Allowed organizations: %v", newOwner, allowedOrgMSPIDs)
+	return fmt.Errorf("the proposed owner, %s, is not allowed to own this asset.  Allowed organizations: %v", newOwner, allowedOrgMSPIDs)
+
+}
+
+// SPEC_NoDuplicateContainerInState checks that none of the containers is already shipped under another bill of lading
+func SPEC_NoDuplicateContainerInState(ctx contractapi.TransactionContextInterface, currentBillOfLadingID string, containers []string) error {
+	for _, containerID := range containers {
+		parentIDs, err := GetParentIDs(ctx, containerID)
+		if err != nil {
+			return err
+		}
+		for _, parentID := range parentIDs {
+			if strings.HasPrefix(parentID, "billoflading_") && parentID != currentBillOfLadingID {
+				return fmt.Errorf("container %s cannot be shipped under bill of lading %s because it is already shipped under %s", containerID, currentBillOfLadingID, parentID)
+			}
+		}
+	}
+	return nil
+}
+
+// SPEC_ShipmentMatchesContainer checks that the vessel and ports of a bill of lading are those the container was loaded for
+func SPEC_ShipmentMatchesContainer(container *Container, vessel string, loadingPort string, dischargePort string) error {
+	if container.Vessel != vessel {
+		return fmt.Errorf("vessel %s does not match the vessel %s of container %s", vessel, container.Vessel, container.ID)
+	}
+	if container.OriginPort != loadingPort {
+		return fmt.Errorf("loading port %s does not match the origin port %s of container %s", loadingPort, container.OriginPort, container.ID)
+	}
+	if container.DestinationPort != dischargePort {
+		return fmt.Errorf("discharge port %s does not match the destination port %s of container %s", dischargePort, container.DestinationPort, container.ID)
+	}
+	return nil
+}
+
+// SPEC_WeightWithinTolerance checks that the declared weight differs from the measured weight by at most tolerance percent
+func SPEC_WeightWithinTolerance(measuredWeight float32, declaredWeight float32, tolerance float32) error {
+	if measuredWeight <= 0 {
+		return fmt.Errorf("the measured weight must be positive, got %v", measuredWeight)
+	}
+	weightDifference := GetPercentageDifference(measuredWeight, declaredWeight)
+	if weightDifference > tolerance || weightDifference < -tolerance {
+		return fmt.Errorf("the declared weight %v differs from the measured weight %v by %.2f%%, more than the tolerance of %v%%", declaredWeight, measuredWeight, weightDifference, tolerance)
+	}
+	return nil
+}
+
+// containerStatusTransitions maps each container status to the status a container must be in to reach it
+var containerStatusTransitions = map[string]string{
+	"departed":  "loaded",
+	"arrived":   "departed",
+	"delivered": "arrived",
+}
+
+// SPEC_IsLegalStatusTransition checks that the container can move from its current status to the new status
+func SPEC_IsLegalStatusTransition(container *Container, newStatus string) error {
+	previousStatus, ok := containerStatusTransitions[newStatus]
+	if !ok {
+		return fmt.Errorf("invalid container status %s", newStatus)
+	}
+	// Containers created before statuses were recorded have no status and are still loaded
+	currentStatus := container.Status
+	if currentStatus == "" {
+		currentStatus = "loaded"
+	}
+	if currentStatus != previousStatus {
+		return fmt.Errorf("container %s cannot become %s because it is %s, not %s", container.ID, newStatus, currentStatus, previousStatus)
+	}
+	return nil
+}
 
+// SPEC_IsValidYieldPolicy ensures that the yield policy holds an expected yield above 0 and at most 1 for every production stage and for no other stage
+func SPEC_IsValidYieldPolicy(yieldPolicy *YieldPolicy) error {
+	names := []string{}
+	for name := range yieldPolicy.ExpectedYields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if getStage(name) == nil {
+			return fmt.Errorf("the yield policy holds an expected yield for unknown stage %s", name)
+		}
+	}
+	for _, stage := range productionStages {
+		expectedYield, ok := yieldPolicy.ExpectedYields[stage.Name]
+		if !ok {
+			return fmt.Errorf("the yield policy has no expected yield for stage %s", stage.Name)
+		}
+		if expectedYield <= 0 || expectedYield > 1 {
+			return fmt.Errorf("the expected yield of stage %s must be above 0 and at most 1, got %v", stage.Name, expectedYield)
+		}
+	}
+	return nil
 }
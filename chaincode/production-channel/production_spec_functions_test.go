@@ -0,0 +1,290 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+func TestSPEC_IDPrefixOneOf(t *testing.T) {
+	tests := []struct {
+		id       string
+		prefixes []string
+		wantErr  string
+	}{
+		{"carton_1", []string{"assembledgarment_", "carton_"}, ""},
+		{"assembledgarment_1", []string{"assembledgarment_", "carton_"}, ""},
+		{"lot_1", []string{"assembledgarment_", "carton_"}, "must start with one of [assembledgarment_ carton_]"},
+		{"lot_1", nil, "must start with one of []"},
+	}
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			checkErr(t, SPEC_IDPrefixOneOf(test.id, test.prefixes...), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_IsNotFlagged(t *testing.T) {
+	_, ctx := newSupplyChainLedger(t)
+	ctx.Stub().Seed("cottonbale_8", []byte(`{"ID":"cottonbale_8"}`))
+	tests := []struct {
+		id      string
+		wantErr string
+	}{
+		{"cottonbale_1", ""},
+		{"cottonbale_4", "the asset cottonbale_4 is flagged"},
+		{"cottonbale_8", "IsFlagged field missing"},
+		{"cottonbale_9", "the asset cottonbale_9 does not exist"},
+	}
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			checkErr(t, SPEC_IsNotFlagged(ctx, test.id), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_LotConsistency(t *testing.T) {
+	_, ctx := newSupplyChainLedger(t)
+	tests := []struct {
+		name    string
+		content []string
+		prefix  string
+		wantErr string
+	}{
+		{"consistent", []string{"cottonbale_1", "cottonbale_3"}, "cottonbale_", ""},
+		{"empty", []string{}, "cottonbale_", "content list cannot be empty"},
+		{"other type", []string{"cottonbale_1", "button_1"}, "cottonbale_", "asset button_1 does not have the correct prefix cottonbale_"},
+		{"missing", []string{"cottonbale_9"}, "cottonbale_", "asset cottonbale_9 does not exist"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_LotConsistency(ctx, test.content, test.prefix), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_NoDuplicateAssetInState(t *testing.T) {
+	_, ctx := newSupplyChainLedger(t)
+	tests := []struct {
+		name           string
+		lotID          string
+		content        []string
+		predecessorIDs []string
+		wantErr        string
+	}{
+		{"assets in no lot", "lot_9", []string{"cottonbale_3", "cottonbale_4"}, nil, ""},
+		{"assets already in this lot", "lot_1", []string{"cottonbale_1", "cottonbale_2"}, nil, ""},
+		{"asset in another lot", "lot_9", []string{"cottonbale_3", "cottonbale_2"}, nil, "asset cottonbale_2 cannot be placed in lot lot_9 because it is already stored in lot lot_1"},
+		{"asset in a predecessor lot", "lot_9", []string{"cottonbale_3", "cottonbale_2"}, []string{"lot_8", "lot_1"}, ""},
+		{"asset in a lot other than the predecessors", "lot_9", []string{"cottonbale_2"}, []string{"lot_8"}, "already stored in lot lot_1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_NoDuplicateAssetInState(ctx, test.lotID, test.content, test.predecessorIDs...), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_CheckLotAssetType(t *testing.T) {
+	_, ctx := newSupplyChainLedger(t)
+	ctx.Stub().Seed("lot_8", []byte(`{"ID":"lot_8"}`))
+	tests := []struct {
+		name    string
+		lotID   string
+		prefix  string
+		wantErr string
+	}{
+		{"cotton bale lot", "lot_1", "cottonbale_", ""},
+		{"finished fabric lot", "lot_4", "finishedfabric_", ""},
+		{"lot of another type", "lot_2", "cottonbale_", "asset cottonyarn_1 in lot lot_2 does not have the correct prefix cottonbale_"},
+		{"lot without content", "lot_8", "cottonbale_", "content field missing"},
+		{"missing lot", "lot_9", "cottonbale_", "lot lot_9 does not exist"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_CheckLotAssetType(ctx, test.lotID, test.prefix), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_NoDuplicateAssetInThisLot(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []string
+		wantErr string
+	}{
+		{"unique", []string{"cottonbale_1", "cottonbale_2"}, ""},
+		{"empty", []string{}, ""},
+		{"duplicate", []string{"cottonbale_1", "cottonbale_2", "cottonbale_1"}, "duplicate asset ID found: cottonbale_1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_NoDuplicateAssetInThisLot(test.content), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_CheckAssetsApproval(t *testing.T) {
+	_, ctx := newSupplyChainLedger(t)
+	ctx.Stub().Seed("cottonbale_8", []byte(`{"ID":"cottonbale_8"}`))
+	tests := []struct {
+		name    string
+		content []string
+		wantErr string
+	}{
+		{"approved", []string{"cottonbale_1", "cottonbale_3"}, ""},
+		{"not approved", []string{"cottonbale_1", "cottonbale_5"}, "asset cottonbale_5 has approval set to false"},
+		{"approval missing", []string{"cottonbale_8"}, "approval field missing"},
+		{"missing", []string{"cottonbale_9"}, "asset cottonbale_9 does not exist"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_CheckAssetsApproval(ctx, test.content), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_IsAllowedToOwn(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	tests := []struct {
+		name     string
+		newOwner string
+		allowed  []string
+		wantErr  string
+	}{
+		{"allowed", "Org6MSP", []string{"Org1MSP", "Org6MSP"}, ""},
+		{"not allowed", "Org4MSP", []string{"Org1MSP", "Org6MSP"}, "the proposed owner, Org4MSP, is not allowed to own this asset"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_IsAllowedToOwn(ctx, test.newOwner, test.allowed...), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_ShipmentMatchesContainer(t *testing.T) {
+	container := &Container{DestinationPort: "Los Angeles", ID: "container_1", OriginPort: "Chittagong", Vessel: "Maersk Alabama"}
+	tests := []struct {
+		name          string
+		vessel        string
+		loadingPort   string
+		dischargePort string
+		wantErr       string
+	}{
+		{"matching", "Maersk Alabama", "Chittagong", "Los Angeles", ""},
+		{"other vessel", "Ever Given", "Chittagong", "Los Angeles", "vessel Ever Given does not match the vessel Maersk Alabama of container container_1"},
+		{"other loading port", "Maersk Alabama", "Mongla", "Los Angeles", "loading port Mongla does not match the origin port Chittagong"},
+		{"other discharge port", "Maersk Alabama", "Chittagong", "Long Beach", "discharge port Long Beach does not match the destination port Los Angeles"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_ShipmentMatchesContainer(container, test.vessel, test.loadingPort, test.dischargePort), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_IsAvailable(t *testing.T) {
+	_, ctx := newSupplyChainLedger(t)
+	tests := []struct {
+		name        string
+		lotID       string
+		drawnWeight float32
+		wantErr     string
+	}{
+		{"part of the available weight", "lot_1", 60, ""},
+		{"all of the available weight", "lot_1", 100, ""},
+		{"nothing", "lot_1", 0, "nothing is drawn from lot lot_1"},
+		{"more than the available weight", "lot_1", 101, "cannot draw 101 from lot lot_1 because only 100 of its 1000 is still available"},
+		{"negative weight", "lot_1", -1, "cannot be negative"},
+		{"missing lot", "lot_9", 1, "lot lot_9 does not exist"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_IsAvailable(ctx, test.lotID, test.drawnWeight), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_ContentPreserved(t *testing.T) {
+	content := []string{"cottonbale_1", "cottonbale_2", "cottonbale_3"}
+	tests := []struct {
+		name     string
+		portions [][]string
+		wantErr  string
+	}{
+		{"partition", [][]string{{"cottonbale_2"}, {"cottonbale_3", "cottonbale_1"}}, ""},
+		{"empty portion", [][]string{{"cottonbale_1", "cottonbale_2", "cottonbale_3"}, {}}, "content list cannot be empty"},
+		{"missing asset", [][]string{{"cottonbale_1"}, {"cottonbale_3"}}, "asset cottonbale_2 is missing from the portions"},
+		{"asset in two portions", [][]string{{"cottonbale_1", "cottonbale_2"}, {"cottonbale_2", "cottonbale_3"}}, "asset cottonbale_2 is not in the content or is listed more than once"},
+		{"foreign asset", [][]string{{"cottonbale_1", "cottonbale_2"}, {"cottonbale_3", "cottonbale_4"}}, "asset cottonbale_4 is not in the content"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_ContentPreserved(content, test.portions), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_IsLegalStatusTransition(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		newStatus string
+		wantErr   string
+	}{
+		{"loaded to departed", "loaded", "departed", ""},
+		{"departed to arrived", "departed", "arrived", ""},
+		{"arrived to delivered", "arrived", "delivered", ""},
+		{"no status to departed", "", "departed", ""},
+		{"loaded to arrived", "loaded", "arrived", "container container_1 cannot become arrived because it is loaded, not departed"},
+		{"delivered to departed", "delivered", "departed", "cannot become departed because it is delivered, not loaded"},
+		{"arrived to arrived", "arrived", "arrived", "cannot become arrived because it is arrived, not departed"},
+		{"back to loaded", "departed", "loaded", "invalid container status loaded"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			container := &Container{ID: "container_1", Status: test.status}
+			checkErr(t, SPEC_IsLegalStatusTransition(container, test.newStatus), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_WeightWithinTolerance(t *testing.T) {
+	tests := []struct {
+		name     string
+		measured float32
+		declared float32
+		wantErr  string
+	}{
+		{"exact", 100, 100, ""},
+		{"at the upper bound", 100, 105, ""},
+		{"at the lower bound", 100, 95, ""},
+		{"too heavy", 100, 106, "differs from the measured weight 100 by 6.00%"},
+		{"too light", 100, 94, "differs from the measured weight 100 by -6.00%"},
+		{"nothing measured", 0, 0, "the measured weight must be positive"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_WeightWithinTolerance(test.measured, test.declared, 5), test.wantErr)
+		})
+	}
+}
+
+func TestSPEC_DestinationMatchesSite(t *testing.T) {
+	rolePolicy := defaultRolePolicy()
+	lot := &Lot{Destination: "Ashulia, Bangladesh", ID: "lot_1"}
+	tests := []struct {
+		name       string
+		receiverID string
+		wantErr    string
+	}{
+		{"registered site", "Org6MSP", ""},
+		{"other site", "Org5MSP", "destination Ashulia, Bangladesh of lot lot_1 does not match the registered site Vadodara, Gujarat, India of Org5MSP"},
+		{"no site", "Org3MSP", "organization Org3MSP has no registered site"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, SPEC_DestinationMatchesSite(rolePolicy, lot, test.receiverID), test.wantErr)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/spec"
+)
+
+// specRegistry records the specifications enforced by each function of the contract, in the order they are checked. It must list the same specifications as the doc comment of each function
+var specRegistry = spec.NewRegistry().
+	Register("ArchiveAsset", "SPEC_AssetExists", "SPEC_IsInvokedByAllowedOrg", "SPEC_IsNotArchived", "SPEC_IsUnreferenced").
+	Register("ArriveContainer", "SPEC_IDPrefix", "SPEC_AssetExists", "SPEC_IsInvokedByAllowedRole", "SPEC_IsLegalStatusTransition", "SPEC_Chronology").
+	Register("AssetExists").
+	Register("BackfillIndexes", "SPEC_IsInvokedByAllowedRole").
+	Register("BackfillLotMembership", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidPageSize").
+	Register("CloseRecall", "SPEC_IsInvokedByAllowedRole", "SPEC_Chronology").
+	Register("CreateAssembledGarment", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_NoDuplicateAssetInThisLot", "SPEC_LotConsistency", "SPEC_Chronology").
+	Register("CreateAssembledGarmentsBatch", "SPEC_IsValidBatchSize").
+	Register("CreateBillOfLading", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_NoDuplicateAssetInThisLot", "SPEC_LotConsistency", "SPEC_NoDuplicateContainerInState", "SPEC_ShipmentMatchesContainer", "SPEC_WeightWithinTolerance", "SPEC_Chronology").
+	Register("CreateButton", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_Chronology").
+	Register("CreateButtonsBatch", "SPEC_IsValidBatchSize").
+	Register("CreateCarton", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_NoDuplicateAssetInThisLot", "SPEC_LotConsistency", "SPEC_HasRole", "SPEC_IsOrderRetailer", "SPEC_IsAllowedToOwn", "SPEC_Chronology").
+	Register("CreateCartonsBatch", "SPEC_IsValidBatchSize").
+	Register("CreateContainer", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_NoDuplicateAssetInThisLot", "SPEC_LotConsistency", "SPEC_Chronology").
+	Register("CreateCottonBale", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_Chronology").
+	Register("CreateCottonYarn").
+	Register("CreateCottonYarnWithDraws", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_NoDuplicateAssetInThisLot", "SPEC_CheckLotAssetType", "SPEC_IsNotRetired", "SPEC_IsOnSite", "SPEC_IsHeldByInvoker", "SPEC_IsAvailable", "SPEC_Chronology").
+	Register("CreateCutPart").
+	Register("CreateCutPartWithDraws", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_NoDuplicateAssetInThisLot", "SPEC_CheckLotAssetType", "SPEC_IsNotRetired", "SPEC_IsOnSite", "SPEC_IsHeldByInvoker", "SPEC_IsAvailable", "SPEC_Chronology").
+	Register("CreateCutPartsBatch", "SPEC_IsValidBatchSize").
+	Register("CreateFinishedFabric").
+	Register("CreateFinishedFabricWithDraws", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_NoDuplicateAssetInThisLot", "SPEC_CheckLotAssetType", "SPEC_IsNotRetired", "SPEC_IsOnSite", "SPEC_IsHeldByInvoker", "SPEC_IsAvailable", "SPEC_Chronology").
+	Register("CreateLot", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_IsNotFlagged", "SPEC_NoDuplicateAssetInThisLot", "SPEC_LotConsistency", "SPEC_NoDuplicateAssetInState", "SPEC_CheckAssetsApproval", "SPEC_Chronology").
+	Register("CreateUnfinishedFabric").
+	Register("CreateUnfinishedFabricWithDraws", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidFlag", "SPEC_NoDuplicateAssetInThisLot", "SPEC_CheckLotAssetType", "SPEC_IsNotRetired", "SPEC_IsOnSite", "SPEC_IsHeldByInvoker", "SPEC_IsAvailable", "SPEC_Chronology").
+	Register("DeliverContainer", "SPEC_IDPrefix", "SPEC_AssetExists", "SPEC_IsInvokedByAllowedRole", "SPEC_IsLegalStatusTransition", "SPEC_Chronology").
+	Register("DepartContainer", "SPEC_IDPrefix", "SPEC_AssetExists", "SPEC_IsInvokedByAllowedRole", "SPEC_IsLegalStatusTransition", "SPEC_Chronology").
+	Register("DispatchLot", "SPEC_IDPrefix", "SPEC_AssetExists", "SPEC_IsInvokedByAllowedOrg", "SPEC_IsNotRetired", "SPEC_IsOnSite", "SPEC_IsAllowedToOwn", "SPEC_DestinationMatchesSite", "SPEC_Chronology").
+	Register("GetAllAssembledGarments").
+	Register("GetAllAssembledGarmentsIncludingArchived").
+	Register("GetAllAssembledGarmentsWithPagination").
+	Register("GetAllAssembledGarmentsWithPaginationIncludingArchived").
+	Register("GetAllAssets").
+	Register("GetAllAssetsCount").
+	Register("GetAllAssetsCountIncludingArchived").
+	Register("GetAllAssetsIncludingArchived").
+	Register("GetAllAssetsOfType").
+	Register("GetAllAssetsOfTypeCount").
+	Register("GetAllAssetsOfTypeCountIncludingArchived").
+	Register("GetAllAssetsOfTypeIncludingArchived").
+	Register("GetAllAssetsOfTypeWithPagination", "SPEC_IsValidPageSize").
+	Register("GetAllAssetsOfTypeWithPaginationIncludingArchived", "SPEC_IsValidPageSize").
+	Register("GetAllAssetsWithPagination", "SPEC_IsValidPageSize").
+	Register("GetAllAssetsWithPaginationIncludingArchived", "SPEC_IsValidPageSize").
+	Register("GetAllBillsOfLading").
+	Register("GetAllBillsOfLadingIncludingArchived").
+	Register("GetAllBillsOfLadingWithPagination").
+	Register("GetAllBillsOfLadingWithPaginationIncludingArchived").
+	Register("GetAllButtons").
+	Register("GetAllButtonsIncludingArchived").
+	Register("GetAllButtonsWithPagination").
+	Register("GetAllButtonsWithPaginationIncludingArchived").
+	Register("GetAllCartons").
+	Register("GetAllCartonsIncludingArchived").
+	Register("GetAllCartonsWithPagination").
+	Register("GetAllCartonsWithPaginationIncludingArchived").
+	Register("GetAllContainers").
+	Register("GetAllContainersIncludingArchived").
+	Register("GetAllContainersWithPagination").
+	Register("GetAllContainersWithPaginationIncludingArchived").
+	Register("GetAllCottonBales").
+	Register("GetAllCottonBalesIncludingArchived").
+	Register("GetAllCottonBalesWithPagination").
+	Register("GetAllCottonBalesWithPaginationIncludingArchived").
+	Register("GetAllCottonYarns").
+	Register("GetAllCottonYarnsIncludingArchived").
+	Register("GetAllCottonYarnsWithPagination").
+	Register("GetAllCottonYarnsWithPaginationIncludingArchived").
+	Register("GetAllCutParts").
+	Register("GetAllCutPartsIncludingArchived").
+	Register("GetAllCutPartsWithPagination").
+	Register("GetAllCutPartsWithPaginationIncludingArchived").
+	Register("GetAllFinishedFabrics").
+	Register("GetAllFinishedFabricsIncludingArchived").
+	Register("GetAllFinishedFabricsWithPagination").
+	Register("GetAllFinishedFabricsWithPaginationIncludingArchived").
+	Register("GetAllLots").
+	Register("GetAllLotsIncludingArchived").
+	Register("GetAllLotsWithPagination").
+	Register("GetAllLotsWithPaginationIncludingArchived").
+	Register("GetAllUnfinishedFabrics").
+	Register("GetAllUnfinishedFabricsIncludingArchived").
+	Register("GetAllUnfinishedFabricsWithPagination").
+	Register("GetAllUnfinishedFabricsWithPaginationIncludingArchived").
+	Register("GetArchivedAssets").
+	Register("GetAsset").
+	Register("GetAssetHistory").
+	Register("GetAssetsByOwner").
+	Register("GetAssetsByOwnerIncludingArchived").
+	Register("GetAssetsPendingMigration").
+	Register("GetBillOfLadingByContainer", "SPEC_IDPrefix", "SPEC_AssetExists").
+	Register("GetChannelStatistics").
+	Register("GetChannelStatisticsIncludingArchived").
+	Register("GetContainersByBillOfLading", "SPEC_IDPrefix", "SPEC_AssetExists").
+	Register("GetFunctionSpecifications").
+	Register("GetMassBalanceReport", "SPEC_IDPrefixOneOf", "SPEC_AssetExists").
+	Register("GetRecall", "SPEC_IDPrefix").
+	Register("GetRolePolicy").
+	Register("GetYieldPolicy").
+	Register("InitiateRecall", "SPEC_IsInvokedByAllowedRole", "SPEC_IDPrefixOneOf", "SPEC_AssetExists", "SPEC_IsValidFlag", "SPEC_IsNewAsset").
+	Register("MergeLots", "SPEC_IDPrefix", "SPEC_IsNewAsset", "SPEC_NoDuplicateAssetInThisLot", "SPEC_LotConsistency", "SPEC_IsInvokedByAllowedRole", "SPEC_IsInvokedByAllowedOrg", "SPEC_IsNotRetired", "SPEC_IsOnSite", "SPEC_IsNotFlagged", "SPEC_CheckLotAssetType", "SPEC_IsAvailable", "SPEC_NoDuplicateAssetInState", "SPEC_WeightWithinTolerance").
+	Register("MigrateAssets", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidMigration").
+	Register("QueryAssets", "SPEC_IsValidSelector", "SPEC_IsValidPageSize").
+	Register("ReceiveLot", "SPEC_IDPrefix", "SPEC_AssetExists", "SPEC_IsInTransit", "SPEC_IsInvokedByAllowedOrg", "SPEC_Chronology").
+	Register("SetFlag", "SPEC_IsValidFlag").
+	Register("SetNotes").
+	Register("SetRolePolicy", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidRolePolicy").
+	Register("SetYieldPolicy", "SPEC_IsInvokedByAllowedRole", "SPEC_IsValidYieldPolicy").
+	Register("SplitLot", "SPEC_IDPrefix", "SPEC_AssetExists", "SPEC_IsInvokedByAllowedRole", "SPEC_IsInvokedByAllowedOrg", "SPEC_IsNotRetired", "SPEC_IsOnSite", "SPEC_IsNotFlagged", "SPEC_IsAvailable", "SPEC_NoDuplicateAssetInThisLot", "SPEC_IsNewAsset", "SPEC_ContentPreserved", "SPEC_NoDuplicateAssetInState", "SPEC_WeightWithinTolerance").
+	Register("TraceDownstream", "SPEC_IDPrefixOneOf", "SPEC_AssetExists").
+	Register("TraceUpstream", "SPEC_IDPrefixOneOf", "SPEC_AssetExists").
+	Register("TransferCarton", "SPEC_IDPrefix", "SPEC_AssetExists", "SPEC_IsInvokedByAllowedOrg", "SPEC_IsNotFlagged", "SPEC_IsAllowedToOwn", "SPEC_IsCustomer").
+	Register("TransferContainer", "SPEC_IDPrefix", "SPEC_AssetExists", "SPEC_IsInvokedByAllowedOrg", "SPEC_IsNotFlagged", "SPEC_IsAllowedToOwn", "SPEC_IsCustomer").
+	Register("UpdateLotOwner", "SPEC_IsInvokedByAllowedRole", "SPEC_IsAllowedToOwn", "SPEC_IsNotRetired")
+
+// GetFunctionSpecifications returns the specifications enforced by each function of the contract, sorted by function name
+func (s *SmartContract) GetFunctionSpecifications(ctx contractapi.TransactionContextInterface) ([]*spec.FunctionSpecification, error) {
+	return specRegistry.Functions(), nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/schema"
+)
+
+// ChannelStatistics summarizes every asset of the channel by type, and the lots by owner
+type ChannelStatistics struct {
+	AssetTypes  []*AssetTypeStatistics `json:"AssetTypes"`  // sorted by asset ID prefix
+	LotsByOwner []*OwnerLots           `json:"LotsByOwner"` // sorted by owner
+	WeightUnit  string                 `json:"WeightUnit"`  // unit of every weight of the statistics
+}
+
+// AssetTypeStatistics summarizes the assets of one type. The weight difference statistics are zero for types whose assets have no WeightDifference, e.g., cotton bales
+type AssetTypeStatistics struct {
+	AssetIDPrefix        string  `json:"AssetIDPrefix"`
+	Count                int     `json:"Count"`
+	FlaggedCount         int     `json:"FlaggedCount"`
+	MaxWeightDifference  float32 `json:"MaxWeightDifference"`
+	MeanWeight           float32 `json:"MeanWeight"`
+	MeanWeightDifference float32 `json:"MeanWeightDifference"`
+	MinWeightDifference  float32 `json:"MinWeightDifference"`
+	RetiredCount         int     `json:"RetiredCount"`    // lots retired by SplitLot or MergeLots, which are left out of every other statistic as their successors hold their weight
+	TotalWeight          float32 `json:"TotalWeight"`     // sum of the TotalWeight, or GrossWeight for bills of lading, of the assets
+	UnapprovedCount      int     `json:"UnapprovedCount"` // assets whose Approval, or AllAssetsApproved for cartons, is false
+}
+
+// OwnerLots are the lots an organization owns
+type OwnerLots struct {
+	LotIDs      []string `json:"LotIDs"` // sorted by lot ID
+	Owner       string   `json:"Owner"`
+	TotalWeight float32  `json:"TotalWeight"`
+}
+
+// statisticsRecord holds the fields of a record needed to compute channel statistics. The pointers are nil for the fields the record type does not have
+type statisticsRecord struct {
+	AllAssetsApproved *bool    `json:"AllAssetsApproved"`
+	Approval          *bool    `json:"Approval"`
+	GrossWeight       *float32 `json:"GrossWeight"`
+	IsFlagged         bool     `json:"IsFlagged"`
+	Owner             string   `json:"Owner"`
+	SuccessorIDs      []string `json:"SuccessorIDs"`
+	TotalWeight       *float32 `json:"TotalWeight"`
+	WeightDifference  *float32 `json:"WeightDifference"`
+	WeightUnit        string   `json:"WeightUnit"`
+}
+
+// assetTypeTotals accumulates the statistics of an asset type while the records are read
+type assetTypeTotals struct {
+	statistics            *AssetTypeStatistics
+	totalWeight           float64
+	weightDifferenceCount int
+	weightDifferenceSum   float64
+}
+
+// GetChannelStatistics computes, in a single pass over the world state, the count, flagged count, unapproved count, total and mean weight, and minimum, maximum and mean weight difference of every asset type, and the lots each organization owns, leaving out archived assets and counting retired lots apart
+func (s *SmartContract) GetChannelStatistics(ctx contractapi.TransactionContextInterface) (*ChannelStatistics, error) {
+	return getChannelStatistics(ctx, false)
+}
+
+// GetChannelStatisticsIncludingArchived computes the statistics of GetChannelStatistics, including archived assets
+func (s *SmartContract) GetChannelStatisticsIncludingArchived(ctx contractapi.TransactionContextInterface) (*ChannelStatistics, error) {
+	return getChannelStatistics(ctx, true)
+}
+
+// getChannelStatistics computes the statistics of every asset type and the lots each organization owns, leaving out archived assets unless includeArchived is true
+func getChannelStatistics(ctx contractapi.TransactionContextInterface, includeArchived bool) (*ChannelStatistics, error) {
+	// Retrieve the IDs of the archived assets
+	archivedIDs, err := getArchivedIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Composite keys are left out of range queries, so only records are read
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state by range: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	totals := map[string]*assetTypeTotals{}
+	lotsByOwner := map[string]*OwnerLots{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through results: %v", err)
+		}
+		// Records without an ID prefix, e.g., the role policy, are not assets
+		assetIDPrefix := schema.DocType(queryResponse.Key)
+		if assetIDPrefix == "" {
+			continue
+		}
+		// Leave out archived assets unless they are requested
+		if !includeArchived && archivedIDs[queryResponse.Key] {
+			continue
+		}
+		var record statisticsRecord
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal asset %s: %v", queryResponse.Key, err)
+		}
+		typeTotals, ok := totals[assetIDPrefix]
+		if !ok {
+			typeTotals = &assetTypeTotals{statistics: &AssetTypeStatistics{AssetIDPrefix: assetIDPrefix}}
+			totals[assetIDPrefix] = typeTotals
+		}
+		// Count retired lots apart, as their weight is held by their successors
+		if len(record.SuccessorIDs) > 0 {
+			typeTotals.statistics.RetiredCount++
+			continue
+		}
+		weight, err := getStatisticsWeight(queryResponse.Key, &record)
+		if err != nil {
+			return nil, err
+		}
+		typeTotals.add(&record, weight)
+
+		if assetIDPrefix == "lot_" {
+			ownerLots, ok := lotsByOwner[record.Owner]
+			if !ok {
+				ownerLots = &OwnerLots{LotIDs: []string{}, Owner: record.Owner}
+				lotsByOwner[record.Owner] = ownerLots
+			}
+			// The range query returns the keys in order, so the lot IDs are sorted
+			ownerLots.LotIDs = append(ownerLots.LotIDs, queryResponse.Key)
+			ownerLots.TotalWeight += float32(weight)
+		}
+	}
+
+	statistics := &ChannelStatistics{AssetTypes: []*AssetTypeStatistics{}, LotsByOwner: []*OwnerLots{}, WeightUnit: ledgerUnits.Weight}
+	for _, typeTotals := range totals {
+		statistics.AssetTypes = append(statistics.AssetTypes, typeTotals.finish())
+	}
+	sort.Slice(statistics.AssetTypes, func(i, j int) bool {
+		return statistics.AssetTypes[i].AssetIDPrefix < statistics.AssetTypes[j].AssetIDPrefix
+	})
+	for _, ownerLots := range lotsByOwner {
+		statistics.LotsByOwner = append(statistics.LotsByOwner, ownerLots)
+	}
+	sort.Slice(statistics.LotsByOwner, func(i, j int) bool {
+		return statistics.LotsByOwner[i].Owner < statistics.LotsByOwner[j].Owner
+	})
+	return statistics, nil
+}
+
+// getStatisticsWeight returns the weight of a record in the ledger's weight unit, which is zero for records without a weight, e.g., recalls
+func getStatisticsWeight(id string, record *statisticsRecord) (float64, error) {
+	weight := record.TotalWeight
+	if weight == nil {
+		weight = record.GrossWeight
+	}
+	if weight == nil {
+		return 0, nil
+	}
+	// Assets created before units were recorded lack a WeightUnit and are in the ledger's weight unit
+	weightUnit := record.WeightUnit
+	if weightUnit == "" {
+		weightUnit = ledgerUnits.Weight
+	}
+	converted, err := Quantity{Unit: weightUnit, Value: *weight}.In(ledgerUnits.Weight)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert the weight of asset %s: %v", id, err)
+	}
+	return float64(converted.Value), nil
+}
+
+// add adds a record of the asset type to the totals
+func (t *assetTypeTotals) add(record *statisticsRecord, weight float64) {
+	t.statistics.Count++
+	if record.IsFlagged {
+		t.statistics.FlaggedCount++
+	}
+	if (record.Approval != nil && !*record.Approval) || (record.AllAssetsApproved != nil && !*record.AllAssetsApproved) {
+		t.statistics.UnapprovedCount++
+	}
+	t.totalWeight += weight
+	if record.WeightDifference == nil {
+		return
+	}
+	weightDifference := *record.WeightDifference
+	if t.weightDifferenceCount == 0 || weightDifference < t.statistics.MinWeightDifference {
+		t.statistics.MinWeightDifference = weightDifference
+	}
+	if t.weightDifferenceCount == 0 || weightDifference > t.statistics.MaxWeightDifference {
+		t.statistics.MaxWeightDifference = weightDifference
+	}
+	t.weightDifferenceCount++
+	t.weightDifferenceSum += float64(weightDifference)
+}
+
+// finish computes the totals and means of the asset type once every record is added
+func (t *assetTypeTotals) finish() *AssetTypeStatistics {
+	t.statistics.TotalWeight = float32(t.totalWeight)
+	// Every lot of the type may be retired
+	if t.statistics.Count > 0 {
+		t.statistics.MeanWeight = float32(t.totalWeight / float64(t.statistics.Count))
+	}
+	if t.weightDifferenceCount > 0 {
+		t.statistics.MeanWeightDifference = float32(t.weightDifferenceSum / float64(t.weightDifferenceCount))
+	}
+	return t.statistics
+}
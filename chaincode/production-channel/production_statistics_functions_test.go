@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetChannelStatistics(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.CreateLot(ctx, before, "cottonbale_", []string{"cottonbale_3"}, "Org4MSP", "", "lot_5", false, "", "Texas", "Org4MSP", 550)
+	})
+	submit(t, ctx, "Org4MSP", func() error { return s.ArchiveAsset(ctx, "lot_5", "created with the wrong weight") })
+
+	statistics, err := s.GetChannelStatisticsIncludingArchived(ctx)
+	checkErr(t, err, "")
+	lots, cottonBales := findAssetType(statistics, "lot_"), findAssetType(statistics, "cottonbale_")
+	if lots == nil || cottonBales == nil {
+		t.Fatalf("expected statistics of lots and cotton bales, got %+v", statistics.AssetTypes)
+	}
+	if cottonBales.Count != 5 || cottonBales.FlaggedCount != 1 || cottonBales.UnapprovedCount != 1 || cottonBales.TotalWeight != 2500 || cottonBales.MeanWeight != 500 {
+		t.Errorf("unexpected cotton bale statistics %+v", cottonBales)
+	}
+	if lots.Count != 5 || lots.TotalWeight != 4100 || lots.MeanWeight != 820 || lots.MinWeightDifference != 0 || lots.MaxWeightDifference != 10 || lots.MeanWeightDifference != 2 {
+		t.Errorf("unexpected lot statistics %+v", lots)
+	}
+	if len(statistics.LotsByOwner) != 2 || fmt.Sprint(statistics.LotsByOwner[0].LotIDs) != "[lot_1 lot_2 lot_5]" || statistics.LotsByOwner[1].Owner != "Org5MSP" {
+		t.Errorf("unexpected lots by owner %+v", statistics.LotsByOwner)
+	}
+	if statistics.AssetTypes[0].AssetIDPrefix != "assembledgarment_" || statistics.WeightUnit != "lb" {
+		t.Errorf("expected the asset types in ID prefix order and weights in pounds, got %s first in %s", statistics.AssetTypes[0].AssetIDPrefix, statistics.WeightUnit)
+	}
+
+	// The archived lot is left out unless requested
+	statistics, err = s.GetChannelStatistics(ctx)
+	checkErr(t, err, "")
+	if lots := findAssetType(statistics, "lot_"); lots.Count != 4 || lots.MaxWeightDifference != 0 || fmt.Sprint(statistics.LotsByOwner[0].LotIDs) != "[lot_1 lot_2]" {
+		t.Errorf("expected the archived lot to be left out, got %+v and %+v", lots, statistics.LotsByOwner)
+	}
+}
+
+func TestGetChannelStatisticsOfRetiredLots(t *testing.T) {
+	s, ctx := newSplittableLedger(t)
+	submit(t, ctx, "Org4MSP", func() error {
+		return s.SplitLot(ctx, "lot_5", []LotPortion{{Content: []string{"cottonbale_3"}, ID: "lot_6", TotalWeight: 500}, {Content: []string{"cottonbale_6"}, ID: "lot_7", TotalWeight: 300}})
+	})
+
+	// The weight of the split lot is only counted once, with its successors
+	statistics, err := s.GetChannelStatistics(ctx)
+	checkErr(t, err, "")
+	lots := findAssetType(statistics, "lot_")
+	if lots.Count != 6 || lots.RetiredCount != 1 || lots.TotalWeight != 4350 || lots.MeanWeight != 725 {
+		t.Errorf("unexpected lot statistics %+v", lots)
+	}
+	if fmt.Sprint(statistics.LotsByOwner[0].LotIDs) != "[lot_1 lot_2 lot_6 lot_7]" || statistics.LotsByOwner[0].TotalWeight != 2700 {
+		t.Errorf("expected the retired lot_5 to be left out of the lots of Org4MSP, got %+v", statistics.LotsByOwner[0])
+	}
+}
+
+// findAssetType returns the statistics of the asset type with the given ID prefix, or nil if there are none
+func findAssetType(statistics *ChannelStatistics, assetIDPrefix string) *AssetTypeStatistics {
+	for _, assetType := range statistics.AssetTypes {
+		if assetType.AssetIDPrefix == assetIDPrefix {
+			return assetType
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// parentLinkIndex is the composite key object type of the child -> parent links written by every Create* function
+const parentLinkIndex = "child~parent"
+
+// ProvenanceTrace is the genealogy of an asset, listed as the nodes of a graph in breadth-first order starting with the root
+type ProvenanceTrace struct {
+	Direction string       `json:"Direction"` // "upstream" (towards raw materials) or "downstream" (towards finished goods)
+	Nodes     []*TraceNode `json:"Nodes"`
+	RootID    string       `json:"RootID"`
+}
+
+// TraceNode is a single asset within a ProvenanceTrace. Assets shared by several branches, e.g., a lot feeding many cut parts, appear only once
+type TraceNode struct {
+	Asset map[string]interface{} `json:"Asset"`
+	Depth int                    `json:"Depth"` // distance from the root of the trace
+	ID    string                 `json:"ID"`
+	Links []string               `json:"Links"` // IDs of the assets this asset was made from (upstream) or used in (downstream)
+}
+
+// TraceUpstream returns the provenance of a finished good or shipment, i.e., every container, cut part, button, lot, fabric, yarn and cotton bale it was made from. Contains the following specifications: 1) SPEC_IDPrefixOneOf, 2) SPEC_AssetExists
+func (s *SmartContract) TraceUpstream(ctx contractapi.TransactionContextInterface, id string) (*ProvenanceTrace, error) {
+	// Ensure the id belongs to an assembled garment, carton, container or bill of lading
+	if err := SPEC_IDPrefixOneOf(id, "assembledgarment_", "carton_", "container_", "billoflading_"); err != nil {
+		return nil, err
+	}
+	// Ensure the asset exists
+	if err := SPEC_AssetExists(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return WalkUpstream(ctx, id)
+}
+
+// TraceDownstream returns every asset that used a cotton bale or lot, directly or indirectly, i.e., every lot, yarn, fabric, cut part, garment, carton, container and bill of lading derived from it. Contains the following specifications: 1) SPEC_IDPrefixOneOf, 2) SPEC_AssetExists
+func (s *SmartContract) TraceDownstream(ctx contractapi.TransactionContextInterface, id string) (*ProvenanceTrace, error) {
+	// Ensure the id belongs to a cotton bale or lot
+	if err := SPEC_IDPrefixOneOf(id, "cottonbale_", "lot_"); err != nil {
+		return nil, err
+	}
+	// Ensure the asset exists
+	if err := SPEC_AssetExists(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return WalkDownstream(ctx, id)
+}
+
+// WalkUpstream builds the upstream trace of any asset by following its Buttons, CutParts, Content and Containers fields
+func WalkUpstream(ctx contractapi.TransactionContextInterface, id string) (*ProvenanceTrace, error) {
+	return walkTrace(ctx, id, "upstream", func(nodeID string, asset map[string]interface{}) ([]string, error) {
+		return GetUpstreamLinks(asset), nil
+	})
+}
+
+// WalkDownstream builds the downstream trace of any asset by following its child -> parent links
+func WalkDownstream(ctx contractapi.TransactionContextInterface, id string) (*ProvenanceTrace, error) {
+	return walkTrace(ctx, id, "downstream", func(nodeID string, asset map[string]interface{}) ([]string, error) {
+		return GetParentIDs(ctx, nodeID)
+	})
+}
+
+// walkTrace walks the asset graph breadth-first from the root, visiting each asset once and following the links returned by getLinks
+func walkTrace(ctx contractapi.TransactionContextInterface, rootID string, direction string, getLinks func(nodeID string, asset map[string]interface{}) ([]string, error)) (*ProvenanceTrace, error) {
+	trace := &ProvenanceTrace{
+		Direction: direction,
+		Nodes:     []*TraceNode{},
+		RootID:    rootID,
+	}
+
+	visited := map[string]bool{rootID: true}
+	queue := []*TraceNode{{ID: rootID, Depth: 0}}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		asset, err := getAssetMap(ctx, node.ID)
+		if err != nil {
+			return nil, err
+		}
+		links, err := getLinks(node.ID, asset)
+		if err != nil {
+			return nil, err
+		}
+		node.Asset = asset
+		node.Links = links
+		trace.Nodes = append(trace.Nodes, node)
+
+		for _, linkID := range node.Links {
+			if visited[linkID] {
+				continue
+			}
+			visited[linkID] = true
+			queue = append(queue, &TraceNode{ID: linkID, Depth: node.Depth + 1})
+		}
+	}
+
+	return trace, nil
+}
+
+// PutParentLinks records a child -> parent link for each child asset that went into the parent, so that the parent can be found from the child without scanning the world state
+func PutParentLinks(ctx contractapi.TransactionContextInterface, parentID string, childIDs []string) error {
+	for _, childID := range childIDs {
+		linkKey, err := ctx.GetStub().CreateCompositeKey(parentLinkIndex, []string{childID, parentID})
+		if err != nil {
+			return fmt.Errorf("failed to create link key for %s -> %s: %v", childID, parentID, err)
+		}
+		// The value is irrelevant, but an empty value would delete the key
+		if err := ctx.GetStub().PutState(linkKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("failed to save link %s -> %s: %v", childID, parentID, err)
+		}
+	}
+	return nil
+}
+
+// GetParentIDs returns the IDs of every asset that the given asset went into
+func GetParentIDs(ctx contractapi.TransactionContextInterface, childID string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(parentLinkIndex, []string{childID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get links of asset %s: %v", childID, err)
+	}
+	defer resultsIterator.Close()
+
+	parentIDs := []string{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through links of asset %s: %v", childID, err)
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split link key: %v", err)
+		}
+		parentIDs = append(parentIDs, attributes[1])
+	}
+	return parentIDs, nil
+}
+
+// GetUpstreamLinks returns the IDs an asset was made from, i.e., the union of its Buttons, CutParts, Content and Containers fields
+func GetUpstreamLinks(asset map[string]interface{}) []string {
+	links := []string{}
+	for _, field := range []string{"Buttons", "CutParts", "Content", "Containers"} {
+		ids, ok := asset[field].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, id := range ids {
+			if linkID, ok := id.(string); ok {
+				links = append(links, linkID)
+			}
+		}
+	}
+	return links
+}
+
+// getAssetMap retrieves an asset from the world state and unmarshals it into a generic map
+func getAssetMap(ctx contractapi.TransactionContextInterface, id string) (map[string]interface{}, error) {
+	assetJSON, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state for asset %s: %v", id, err)
+	}
+	if assetJSON == nil {
+		return nil, fmt.Errorf("asset %s does not exist", id)
+	}
+	var asset map[string]interface{}
+	err = json.Unmarshal(assetJSON, &asset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal asset JSON for asset %s: %v", id, err)
+	}
+	return asset, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// Units of the weights and dimensions reported to the contract
+const (
+	Centimetre = "cm"
+	Inch       = "in"
+	Kilogram   = "kg"
+	Metre      = "m"
+	Pound      = "lb"
+	Yard       = "yd"
+)
+
+// unit is the dimension of a unit and its size in the SI unit of that dimension, i.e., kilograms or metres
+type unit struct {
+	Dimension string
+	SISize    float64
+}
+
+var units = map[string]unit{
+	Centimetre: {Dimension: "length", SISize: 0.01},
+	Inch:       {Dimension: "length", SISize: 0.0254},
+	Kilogram:   {Dimension: "weight", SISize: 1},
+	Metre:      {Dimension: "length", SISize: 1},
+	Pound:      {Dimension: "weight", SISize: 0.45359237},
+	Yard:       {Dimension: "length", SISize: 0.9144},
+}
+
+// Quantity is a weight or dimension together with its unit
+type Quantity struct {
+	Unit  string  `json:"Unit"`
+	Value float32 `json:"Value"`
+}
+
+// In converts the quantity to another unit of the same dimension
+func (q Quantity) In(toUnit string) (Quantity, error) {
+	from, ok := units[q.Unit]
+	if !ok {
+		return Quantity{}, fmt.Errorf("unknown unit %s", q.Unit)
+	}
+	to, ok := units[toUnit]
+	if !ok {
+		return Quantity{}, fmt.Errorf("unknown unit %s", toUnit)
+	}
+	if from.Dimension != to.Dimension {
+		return Quantity{}, fmt.Errorf("cannot convert %v %s, a %s, to %s, a %s", q.Value, q.Unit, from.Dimension, toUnit, to.Dimension)
+	}
+	if q.Unit == toUnit {
+		return q, nil
+	}
+	return Quantity{Unit: toUnit, Value: float32(float64(q.Value) * from.SISize / to.SISize)}, nil
+}
+
+// UnitSystem is the set of units an organization reports the weight, fabric length and fabric width of its assets in
+type UnitSystem struct {
+	Length string
+	Weight string
+	Width  string
+}
+
+var unitSystems = map[string]UnitSystem{
+	policy.Imperial: {Length: Yard, Weight: Pound, Width: Inch},
+	policy.Metric:   {Length: Metre, Weight: Kilogram, Width: Centimetre},
+}
+
+// ledgerUnits are the units every weight and dimension is stored in, whichever units it was reported in. Assets created before units were recorded are in these units too
+var ledgerUnits = unitSystems[policy.Imperial]
+
+// GetReportingUnits returns the unit system the invoking organization reports in, which is imperial unless the role policy registers another one
+func GetReportingUnits(ctx contractapi.TransactionContextInterface, rolePolicy *policy.RolePolicy) (UnitSystem, error) {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return UnitSystem{}, fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+	unitSystemName, ok := rolePolicy.UnitSystems[clientMSPID]
+	if !ok {
+		unitSystemName = policy.Imperial
+	}
+	unitSystem, ok := unitSystems[unitSystemName]
+	if !ok {
+		return UnitSystem{}, fmt.Errorf("organization %s reports in unknown unit system %s", clientMSPID, unitSystemName)
+	}
+	return unitSystem, nil
+}
+
+// ConvertWeight converts a weight reported in the unit system to the ledger's weight unit
+func (u UnitSystem) ConvertWeight(weight float32) float32 {
+	return convert(weight, u.Weight, ledgerUnits.Weight)
+}
+
+// ConvertWeights converts weights reported in the unit system to the ledger's weight unit
+func (u UnitSystem) ConvertWeights(weights []float32) []float32 {
+	if weights == nil {
+		return nil
+	}
+	converted := []float32{}
+	for _, weight := range weights {
+		converted = append(converted, u.ConvertWeight(weight))
+	}
+	return converted
+}
+
+// ConvertLength converts a fabric length reported in the unit system to the ledger's length unit
+func (u UnitSystem) ConvertLength(length float32) float32 {
+	return convert(length, u.Length, ledgerUnits.Length)
+}
+
+// ConvertWidth converts a fabric width reported in the unit system to the ledger's width unit
+func (u UnitSystem) ConvertWidth(width float32) float32 {
+	return convert(width, u.Width, ledgerUnits.Width)
+}
+
+// convert converts a value between two units that are known to be of the same dimension
+func convert(value float32, fromUnit string, toUnit string) float32 {
+	quantity, err := Quantity{Unit: fromUnit, Value: value}.In(toUnit)
+	if err != nil {
+		// The units of every UnitSystem are known and of the right dimension
+		panic(err)
+	}
+	return quantity.Value
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+func TestQuantityIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity Quantity
+		toUnit   string
+		want     string
+		wantErr  string
+	}{
+		{"kilograms to pounds", Quantity{Unit: Kilogram, Value: 100}, Pound, "220.46 lb", ""},
+		{"metres to yards", Quantity{Unit: Metre, Value: 100}, Yard, "109.36 yd", ""},
+		{"centimetres to inches", Quantity{Unit: Centimetre, Value: 150}, Inch, "59.06 in", ""},
+		{"same unit", Quantity{Unit: Pound, Value: 1.5}, Pound, "1.50 lb", ""},
+		{"weight to length", Quantity{Unit: Kilogram, Value: 1}, Metre, "", "cannot convert 1 kg, a weight, to m, a length"},
+		{"unknown unit", Quantity{Unit: "st", Value: 1}, Pound, "", "unknown unit st"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quantity, err := test.quantity.In(test.toUnit)
+			checkErr(t, err, test.wantErr)
+			if got := fmt.Sprintf("%.2f %s", quantity.Value, quantity.Unit); err == nil && got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMetricReporting(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	metricTextileMill := rolePolicyJSON(t, func(rolePolicy *policy.RolePolicy) {
+		rolePolicy.UnitSystems = map[string]string{"Org5MSP": policy.Metric}
+	})
+	submit(t, ctx, "Org1MSP", func() error { return s.SetRolePolicy(ctx, metricTextileMill) })
+
+	// The textile mill reports 10 kg of yarn drawn from lot_2, 100 m by 150 cm of fabric weighing 9.5 kg
+	err := ctx.Submit("Org5MSP", func() error {
+		return s.CreateUnfinishedFabricWithDraws(ctx, true, before, []string{"lot_2"}, []float32{10}, "", "unfinishedfabric_9", false, "", "Karlsruhe", 100, 9.5, 150)
+	})
+	checkErr(t, err, "")
+	fabric, err := getAssetMap(ctx, "unfinishedfabric_9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprintf("%.2f %.2f %.2f %.2f %v %v %v", fabric["ContentWeight"], fabric["TotalWeight"], fabric["Length"], fabric["Width"], fabric["WeightUnit"], fabric["LengthUnit"], fabric["WidthUnit"])
+	if want := "22.05 20.94 109.36 59.06 lb yd in"; got != want {
+		t.Errorf("expected the fabric to be stored as %s, got %s", want, got)
+	}
+	lot, err := getAssetMap(ctx, "lot_2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprintf("%.2f", lot["ConsumedWeight"]); got != "872.05" {
+		t.Errorf("expected 22.05 lb more to be drawn from lot_2, got a consumed weight of %s", got)
+	}
+
+	// Organizations without a registered unit system keep reporting in imperial units
+	submit(t, ctx, "Org6MSP", func() error {
+		return s.CreateButton(ctx, true, before, "", "button_9", false, "", "Ashulia", 0.01)
+	})
+	button, err := getAssetMap(ctx, "button_9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprintf("%.2f %v", button["TotalWeight"], button["WeightUnit"]); got != "0.01 lb" {
+		t.Errorf("expected the button to weigh 0.01 lb, got %s", got)
+	}
+}
+
+func TestGetContentWeight(t *testing.T) {
+	_, ctx := newSupplyChainLedger(t)
+	// cottonbale_7 was stored before units were recorded, and cottonbale_8 and cottonbale_9 were weighed in kilograms
+	ctx.Stub().Seed("cottonbale_7", []byte(`{"ID":"cottonbale_7","TotalWeight":500}`))
+	ctx.Stub().Seed("cottonbale_8", []byte(`{"ID":"cottonbale_8","TotalWeight":100,"WeightUnit":"kg"}`))
+	ctx.Stub().Seed("cottonbale_9", []byte(`{"ID":"cottonbale_9","TotalWeight":100,"WeightUnit":"kg"}`))
+	tests := []struct {
+		name    string
+		content []string
+		want    string
+		wantErr string
+	}{
+		{"ledger units", []string{"cottonbale_1", "cottonbale_2"}, "1000.00", ""},
+		{"legacy asset without a unit", []string{"cottonbale_1", "cottonbale_7"}, "980.00", ""},
+		{"uniform foreign unit", []string{"cottonbale_8", "cottonbale_9"}, "440.92", ""},
+		{"mixed units", []string{"cottonbale_1", "cottonbale_8"}, "", "cannot sum the weight of asset cottonbale_8 in kg with weights in lb"},
+		{"empty content", []string{}, "0.00", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var contentWeight float32
+			err := ctx.Evaluate("Org3MSP", func() (err error) {
+				contentWeight, err = GetContentWeight(ctx, test.content)
+				return err
+			})
+			checkErr(t, err, test.wantErr)
+			if got := fmt.Sprintf("%.2f", contentWeight); err == nil && got != test.want {
+				t.Errorf("expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
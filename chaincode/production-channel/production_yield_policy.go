@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// yieldPolicyObjectType is the composite key object type under which the yield policy is stored. Composite keys are skipped by GetStateByRange, so the policy never shows up among the assets
+const yieldPolicyObjectType = "yieldpolicy"
+
+// YieldPolicy holds the expected yield of each production stage, i.e., the weight of the output per unit of weight of the input
+type YieldPolicy struct {
+	ExpectedYields map[string]float32 `json:"ExpectedYields"` // stage name, e.g., "bale-to-yarn", to its expected yield
+	UpdatedAt      time.Time          `json:"UpdatedAt"`
+	UpdatedBy      string             `json:"UpdatedBy"`
+	Version        int                `json:"Version"` // 0 for the default policy that was never stored
+}
+
+// defaultYieldPolicy returns the yield policy in force until SetYieldPolicy stores one. The expected yields are the ones test-network/materials_calculation.py plans orders with, and garment assembly is assumed to lose no weight
+func defaultYieldPolicy() *YieldPolicy {
+	return &YieldPolicy{
+		ExpectedYields: map[string]float32{
+			"bale-to-yarn":                    0.85,
+			"yarn-to-greige":                  0.90,
+			"greige-to-finished":              0.95,
+			"finished-to-cutparts":            0.85,
+			"cutparts-and-buttons-to-garment": 1,
+		},
+	}
+}
+
+// ReadYieldPolicy returns the yield policy in force
+func ReadYieldPolicy(ctx contractapi.TransactionContextInterface) (*YieldPolicy, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(yieldPolicyObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create yield policy key: %v", err)
+	}
+	yieldPolicyJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read yield policy: %v", err)
+	}
+	if yieldPolicyJSON == nil {
+		return defaultYieldPolicy(), nil
+	}
+	var yieldPolicy YieldPolicy
+	err = json.Unmarshal(yieldPolicyJSON, &yieldPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal yield policy: %v", err)
+	}
+	return &yieldPolicy, nil
+}
+
+// GetYieldPolicy retrieves the yield policy in force, which is the default policy (Version 0) until SetYieldPolicy is first invoked
+func (s *SmartContract) GetYieldPolicy(ctx contractapi.TransactionContextInterface) (*YieldPolicy, error) {
+	return ReadYieldPolicy(ctx)
+}
+
+// SetYieldPolicy replaces the yield policy in force, e.g., once a mill's measured yields replace the planned ones. It is governed by the organizations allowed to update the role policy. UpdatedAt, UpdatedBy and Version are set by the contract. Contains the following specifications: 1) SPEC_IsInvokedByAllowedRole, 2) SPEC_IsValidYieldPolicy
+func (s *SmartContract) SetYieldPolicy(ctx contractapi.TransactionContextInterface, yieldPolicyJSON string) error {
+	// Retrieve the role policy in force
+	rolePolicy, err := ReadRolePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	// Ensure that the function is invoked by an organization allowed to update the policies
+	if err := SPEC_IsInvokedByAllowedRole(ctx, rolePolicy, policy.Update, policy.AssetType); err != nil {
+		return err
+	}
+	// Retrieve the yield policy in force
+	yieldPolicy, err := ReadYieldPolicy(ctx)
+	if err != nil {
+		return err
+	}
+
+	var newYieldPolicy YieldPolicy
+	err = json.Unmarshal([]byte(yieldPolicyJSON), &newYieldPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal yield policy: %v", err)
+	}
+	// Ensure that the new yield policy holds a plausible expected yield for every production stage and for nothing else
+	if err := SPEC_IsValidYieldPolicy(&newYieldPolicy); err != nil {
+		return err
+	}
+
+	// Retrieve the invoking organization's MSP ID
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	newYieldPolicy.UpdatedAt = txTimestamp
+	newYieldPolicy.UpdatedBy = clientMSPID
+	newYieldPolicy.Version = yieldPolicy.Version + 1
+
+	// Save the yield policy to the world state
+	key, err := ctx.GetStub().CreateCompositeKey(yieldPolicyObjectType, []string{})
+	if err != nil {
+		return fmt.Errorf("failed to create yield policy key: %v", err)
+	}
+	newYieldPolicyJSON, err := json.Marshal(newYieldPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal yield policy: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, newYieldPolicyJSON); err != nil {
+		return fmt.Errorf("failed to save yield policy: %v", err)
+	}
+	// Emit the YieldPolicyUpdated event
+	return events.Emit(ctx, events.YieldPolicyUpdated, events.YieldPolicyUpdatedData{ExpectedYields: newYieldPolicy.ExpectedYields, Version: newYieldPolicy.Version})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/events"
+)
+
+// yieldPolicyJSON returns the default yield policy, changed by edit, as JSON
+func yieldPolicyJSON(t *testing.T, edit func(yieldPolicy *YieldPolicy)) string {
+	t.Helper()
+	yieldPolicy := defaultYieldPolicy()
+	edit(yieldPolicy)
+	yieldPolicyJSON, err := json.Marshal(yieldPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(yieldPolicyJSON)
+}
+
+func TestSetYieldPolicy(t *testing.T) {
+	set := func(yieldPolicyJSON string) func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return func(s *SmartContract, ctx contractapi.TransactionContextInterface) error {
+			return s.SetYieldPolicy(ctx, yieldPolicyJSON)
+		}
+	}
+	measured := yieldPolicyJSON(t, func(yieldPolicy *YieldPolicy) {
+		yieldPolicy.ExpectedYields["bale-to-yarn"] = 0.8
+	})
+	missingStage := yieldPolicyJSON(t, func(yieldPolicy *YieldPolicy) {
+		delete(yieldPolicy.ExpectedYields, "yarn-to-greige")
+	})
+	unknownStage := yieldPolicyJSON(t, func(yieldPolicy *YieldPolicy) {
+		yieldPolicy.ExpectedYields["garment-to-carton"] = 1
+	})
+	gain := yieldPolicyJSON(t, func(yieldPolicy *YieldPolicy) {
+		yieldPolicy.ExpectedYields["greige-to-finished"] = 1.2
+	})
+	noOutput := yieldPolicyJSON(t, func(yieldPolicy *YieldPolicy) {
+		yieldPolicy.ExpectedYields["finished-to-cutparts"] = 0
+	})
+
+	run(t, []testCase{
+		{"agent", "Org2MSP", set(measured), ""},
+		{"retailer", "Org1MSP", set(measured), ""},
+		{"textile mill", "Org5MSP", set(measured), "not invoked by an allowed organization"},
+		{"auditor", "Org3MSP", set(measured), "not invoked by an allowed organization"},
+		{"malformed", "Org1MSP", set(`{"ExpectedYields":`), "failed to unmarshal yield policy"},
+		{"missing stage", "Org1MSP", set(missingStage), "the yield policy has no expected yield for stage yarn-to-greige"},
+		{"unknown stage", "Org1MSP", set(unknownStage), "expected yield for unknown stage garment-to-carton"},
+		{"weight gained", "Org1MSP", set(gain), "the expected yield of stage greige-to-finished must be above 0 and at most 1, got 1.2"},
+		{"nothing produced", "Org1MSP", set(noOutput), "the expected yield of stage finished-to-cutparts must be above 0 and at most 1, got 0"},
+	}, func(t *testing.T, s *SmartContract, ctx *chaincodetest.TransactionContext) {
+		yieldPolicy, err := s.GetYieldPolicy(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if yieldPolicy.Version != 1 || yieldPolicy.ExpectedYields["bale-to-yarn"] != 0.8 || yieldPolicy.UpdatedAt.IsZero() {
+			t.Errorf("unexpected yield policy %+v", yieldPolicy)
+		}
+		last := ctx.Stub().LastEvent()
+		event, err := events.Decode(last.EventName, last.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, ok := event.Data.(*events.YieldPolicyUpdatedData); !ok || data.Version != 1 || data.ExpectedYields["bale-to-yarn"] != 0.8 {
+			t.Errorf("unexpected event %s with data %+v", event.Name, event.Data)
+		}
+	})
+}
+
+func TestGetYieldPolicy(t *testing.T) {
+	s, ctx := newSupplyChainLedger(t)
+	var yieldPolicy *YieldPolicy
+	err := ctx.Evaluate("Org3MSP", func() (err error) {
+		yieldPolicy, err = s.GetYieldPolicy(ctx)
+		return err
+	})
+	checkErr(t, err, "")
+	if yieldPolicy.Version != 0 || yieldPolicy.ExpectedYields["finished-to-cutparts"] != 0.85 {
+		t.Fatalf("expected the default yield policy, got %+v", yieldPolicy)
+	}
+
+	// Every update bumps the version and records who made it
+	for i := 0; i < 2; i++ {
+		submit(t, ctx, "Org1MSP", func() error {
+			return s.SetYieldPolicy(ctx, yieldPolicyJSON(t, func(yieldPolicy *YieldPolicy) {}))
+		})
+	}
+	err = ctx.Evaluate("Org3MSP", func() (err error) {
+		yieldPolicy, err = s.GetYieldPolicy(ctx)
+		return err
+	})
+	checkErr(t, err, "")
+	if yieldPolicy.Version != 2 || yieldPolicy.UpdatedBy != "Org1MSP" {
+		t.Errorf("expected version 2 updated by Org1MSP, got %+v", yieldPolicy)
+	}
+}
@@ -0,0 +1,158 @@
+// Package batch applies several items, e.g., the creation of one asset each, in a single transaction. Fabric does not
+// let a transaction read its own writes, so each item runs against a context whose stub serves the writes of the items
+// applied before it, and holds back the writes of the item until it succeeds. The batch is atomic: its writes reach the
+// real stub only once every item succeeded, and otherwise the error of every item that failed is returned.
+package batch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ItemError is the error of one item of a batch
+type ItemError struct {
+	Err   error
+	ID    string // ID of the record the item creates
+	Index int    // position of the item in the batch, starting at 0
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d (%s): %v", e.Index, e.ID, e.Err)
+}
+
+// Error is the error of a batch in which at least one item failed
+type Error struct {
+	Items []*ItemError
+	Size  int // number of items in the batch
+}
+
+func (e *Error) Error() string {
+	messages := []string{}
+	for _, item := range e.Items {
+		messages = append(messages, item.Error())
+	}
+	return fmt.Sprintf("%d of %d items failed, so none was applied: %s", len(e.Items), e.Size, strings.Join(messages, "; "))
+}
+
+// Run calls apply for each item of the batch, in order, with a context that serves the writes of the items applied
+// before it. ids holds the ID of the record each item creates, which is used to report its error. Every item is
+// applied, even after one fails, so that the returned *Error reports all of them. Events set by the items are dropped,
+// since Fabric only delivers the last event of a transaction, and the caller is expected to emit one for the batch.
+// Range and composite key queries are served by the real stub and do not see the writes of the batch
+func Run(ctx contractapi.TransactionContextInterface, ids []string, apply func(ctx contractapi.TransactionContextInterface, i int) error) error {
+	stub := &overlayStub{ChaincodeStubInterface: ctx.GetStub(), writes: map[string][]byte{}}
+	itemCtx := &overlayContext{TransactionContextInterface: ctx, stub: stub}
+
+	batchErr := &Error{Items: []*ItemError{}, Size: len(ids)}
+	for i, id := range ids {
+		stub.begin()
+		if err := apply(itemCtx, i); err != nil {
+			batchErr.Items = append(batchErr.Items, &ItemError{Err: err, ID: id, Index: i})
+			continue
+		}
+		stub.commit()
+	}
+	if len(batchErr.Items) > 0 {
+		return batchErr
+	}
+	return stub.flush()
+}
+
+// overlayContext is a transaction context whose stub is an overlayStub
+type overlayContext struct {
+	contractapi.TransactionContextInterface
+	stub *overlayStub
+}
+
+// GetStub returns the overlay stub of the batch
+func (c *overlayContext) GetStub() shim.ChaincodeStubInterface {
+	return c.stub
+}
+
+// overlayStub holds back the writes of a batch. A nil value records a deletion
+type overlayStub struct {
+	shim.ChaincodeStubInterface
+	keys        []string          // keys written by the items that succeeded, in the order of their first write
+	pending     map[string][]byte // writes of the running item
+	pendingKeys []string          // keys written by the running item, in the order of their first write
+	writes      map[string][]byte // writes of the items that succeeded
+}
+
+// GetState returns the value the batch wrote last under the key, or else the committed value
+func (s *overlayStub) GetState(key string) ([]byte, error) {
+	if value, ok := s.pending[key]; ok {
+		return value, nil
+	}
+	if value, ok := s.writes[key]; ok {
+		return value, nil
+	}
+	return s.ChaincodeStubInterface.GetState(key)
+}
+
+// PutState holds back the write until the running item succeeds. Like Fabric, it treats an empty value as a deletion
+func (s *overlayStub) PutState(key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	if len(value) == 0 {
+		return s.DelState(key)
+	}
+	s.hold(key, append([]byte{}, value...))
+	return nil
+}
+
+// DelState holds back the deletion until the running item succeeds
+func (s *overlayStub) DelState(key string) error {
+	s.hold(key, nil)
+	return nil
+}
+
+// SetEvent drops the event of an item
+func (s *overlayStub) SetEvent(name string, payload []byte) error {
+	return nil
+}
+
+// hold records a write of the running item
+func (s *overlayStub) hold(key string, value []byte) {
+	if _, ok := s.pending[key]; !ok {
+		s.pendingKeys = append(s.pendingKeys, key)
+	}
+	s.pending[key] = value
+}
+
+// begin discards the writes of the previous item, if it failed, and starts holding back the writes of the next one
+func (s *overlayStub) begin() {
+	s.pending = map[string][]byte{}
+	s.pendingKeys = nil
+}
+
+// commit adds the writes of the running item to the writes of the batch
+func (s *overlayStub) commit() {
+	for _, key := range s.pendingKeys {
+		if _, ok := s.writes[key]; !ok {
+			s.keys = append(s.keys, key)
+		}
+		s.writes[key] = s.pending[key]
+	}
+	s.begin()
+}
+
+// flush passes the writes of the batch to the real stub
+func (s *overlayStub) flush() error {
+	for _, key := range s.keys {
+		value := s.writes[key]
+		if value == nil {
+			if err := s.ChaincodeStubInterface.DelState(key); err != nil {
+				return fmt.Errorf("failed to delete %s: %v", key, err)
+			}
+			continue
+		}
+		if err := s.ChaincodeStubInterface.PutState(key, value); err != nil {
+			return fmt.Errorf("failed to save %s: %v", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+func TestRun(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	ctx.Stub().Seed("lot_1", []byte("old"))
+	ids := []string{"button_1", "button_2", "button_3"}
+
+	err := ctx.Submit("Org1MSP", func() error {
+		return Run(ctx, ids, func(itemCtx contractapi.TransactionContextInterface, i int) error {
+			// Each item reads the writes of the items before it
+			if i > 0 {
+				previous, _ := itemCtx.GetStub().GetState(ids[i-1])
+				if string(previous) != ids[i-1] {
+					return fmt.Errorf("expected to read %s, got %q", ids[i-1], previous)
+				}
+			}
+			_ = itemCtx.GetStub().SetEvent("AssetCreated", []byte("{}"))
+			if i == 2 {
+				return itemCtx.GetStub().DelState("lot_1")
+			}
+			return itemCtx.GetStub().PutState(ids[i], []byte(ids[i]))
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ctx.Stub().Keys()) != "[button_1 button_2]" {
+		t.Errorf("expected the writes of every item to be committed, got %v", ctx.Stub().Keys())
+	}
+	if event := ctx.Stub().LastEvent(); event != nil {
+		t.Errorf("expected the events of the items to be dropped, got %s", event.EventName)
+	}
+}
+
+func TestRunReportsEveryFailedItem(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	ids := []string{"button_1", "button_2", "button_3", "button_4"}
+
+	var seen []string
+	err := ctx.Submit("Org1MSP", func() error {
+		return Run(ctx, ids, func(itemCtx contractapi.TransactionContextInterface, i int) error {
+			// The writes of a failed item are discarded before the next item runs
+			if value, _ := itemCtx.GetStub().GetState("shared"); value != nil {
+				seen = append(seen, string(value))
+			}
+			_ = itemCtx.GetStub().PutState("shared", []byte(ids[i]))
+			if i%2 == 1 {
+				return fmt.Errorf("invalid weight")
+			}
+			return nil
+		})
+	})
+	var batchErr *Error
+	if !errors.As(err, &batchErr) || len(batchErr.Items) != 2 || batchErr.Items[1].Index != 3 {
+		t.Fatalf("expected items 1 and 3 to fail, got %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "2 of 4 items failed, so none was applied: item 1 (button_2): invalid weight; item 3 (button_4)") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if fmt.Sprint(seen) != "[button_1 button_1 button_3]" {
+		t.Errorf("expected only the writes of the items that succeeded to be read, got %v", seen)
+	}
+	if keys := ctx.Stub().Keys(); len(keys) != 0 {
+		t.Errorf("expected nothing to be committed, got %v", keys)
+	}
+}
@@ -0,0 +1,114 @@
+package chaincodetest
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+var _ contractapi.TransactionContextInterface = (*TransactionContext)(nil)
+
+// ClientIdentity is an in-memory cid.ClientIdentity identified by its MSP ID and attributes only
+type ClientIdentity struct {
+	Attributes map[string]string
+	MSPID      string
+}
+
+// GetID returns an ID derived from the MSP ID, unique per organization
+func (c *ClientIdentity) GetID() (string, error) {
+	return "x509::CN=user1," + c.MSPID, nil
+}
+
+// GetMSPID returns the MSP ID of the invoking organization
+func (c *ClientIdentity) GetMSPID() (string, error) {
+	if c.MSPID == "" {
+		return "", fmt.Errorf("the client identity has no MSP ID")
+	}
+	return c.MSPID, nil
+}
+
+// GetAttributeValue returns the value of an attribute of the identity
+func (c *ClientIdentity) GetAttributeValue(attrName string) (string, bool, error) {
+	value, found := c.Attributes[attrName]
+	return value, found, nil
+}
+
+// AssertAttributeValue returns an error unless the identity has the attribute with the given value
+func (c *ClientIdentity) AssertAttributeValue(attrName, attrValue string) error {
+	value, found := c.Attributes[attrName]
+	if !found {
+		return fmt.Errorf("attribute '%s' was not found", attrName)
+	}
+	if value != attrValue {
+		return fmt.Errorf("attribute '%s' equals '%s', not '%s'", attrName, value, attrValue)
+	}
+	return nil
+}
+
+// GetX509Certificate returns nil, since the identity is not backed by a certificate
+func (c *ClientIdentity) GetX509Certificate() (*x509.Certificate, error) {
+	return nil, nil
+}
+
+// TransactionContext is an in-memory contractapi.TransactionContextInterface whose invoking identity can be switched between transactions
+type TransactionContext struct {
+	identity *ClientIdentity
+	stub     *Stub
+}
+
+// NewTransactionContext returns a context over an empty ledger for the given channel, invoked by the given organization
+func NewTransactionContext(channelID string, mspID string) *TransactionContext {
+	return &TransactionContext{
+		identity: &ClientIdentity{Attributes: map[string]string{}, MSPID: mspID},
+		stub:     NewStub(channelID),
+	}
+}
+
+// GetStub returns the in-memory stub
+func (c *TransactionContext) GetStub() shim.ChaincodeStubInterface {
+	return c.stub
+}
+
+// GetClientIdentity returns the identity of the invoking organization
+func (c *TransactionContext) GetClientIdentity() cid.ClientIdentity {
+	return c.identity
+}
+
+// Stub returns the in-memory stub with its test helpers
+func (c *TransactionContext) Stub() *Stub {
+	return c.stub
+}
+
+// SetMSPID switches the invoking organization
+func (c *TransactionContext) SetMSPID(mspID string) {
+	c.identity.MSPID = mspID
+}
+
+// SetAttribute sets an attribute of the invoking identity
+func (c *TransactionContext) SetAttribute(attrName string, attrValue string) {
+	c.identity.Attributes[attrName] = attrValue
+}
+
+// Submit runs fn as a single transaction invoked by the given organization. Its writes and event are committed if fn
+// succeeds and discarded otherwise, just as the peer would not commit a failed endorsement
+func (c *TransactionContext) Submit(mspID string, fn func() error) error {
+	c.SetMSPID(mspID)
+	c.stub.Begin()
+	if err := fn(); err != nil {
+		c.stub.Rollback()
+		return err
+	}
+	c.stub.Commit()
+	return nil
+}
+
+// Evaluate runs fn as a query invoked by the given organization. Any writes are discarded
+func (c *TransactionContext) Evaluate(mspID string, fn func() error) error {
+	c.SetMSPID(mspID)
+	c.stub.Begin()
+	defer c.stub.Rollback()
+	return fn()
+}
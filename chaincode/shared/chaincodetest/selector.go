@@ -0,0 +1,191 @@
+package chaincodetest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// GetQueryResultWithPagination evaluates the selector of a CouchDB query against the committed JSON values, composite
+// keys included, and returns at most pageSize matches in key order, starting from the bookmark. Only the part of the
+// selector syntax used by the contracts is supported: field conditions on dotted paths, the $eq, $ne, $gt, $gte, $lt,
+// $lte, $exists and $in operators, and the $and and $or combinations. The sort and use_index fields are ignored
+func (s *Stub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	var parsedQuery struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	if err := json.Unmarshal([]byte(query), &parsedQuery); err != nil {
+		return nil, nil, fmt.Errorf("invalid query %s: %v", query, err)
+	}
+	if parsedQuery.Selector == nil {
+		return nil, nil, fmt.Errorf("the query %s has no selector", query)
+	}
+
+	results := []*queryresult.KV{}
+	nextBookmark := ""
+	for _, key := range s.Keys() {
+		if key < bookmark {
+			continue
+		}
+		// Values that are not JSON objects, e.g., the values of index keys, are never matched
+		var document map[string]interface{}
+		if err := json.Unmarshal(s.state[key], &document); err != nil {
+			continue
+		}
+		ok, err := matchesSelector(document, parsedQuery.Selector)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+		if pageSize > 0 && int32(len(results)) == pageSize {
+			nextBookmark = key
+			break
+		}
+		results = append(results, &queryresult.KV{Key: key, Namespace: s.channelID, Value: s.state[key]})
+	}
+	metadata := &peer.QueryResponseMetadata{Bookmark: nextBookmark, FetchedRecordsCount: int32(len(results))}
+	return &stateIterator{results: results}, metadata, nil
+}
+
+// matchesSelector reports whether the document meets every condition of the selector
+func matchesSelector(document map[string]interface{}, selector map[string]interface{}) (bool, error) {
+	for field, condition := range selector {
+		var ok bool
+		var err error
+		switch field {
+		case "$and", "$or":
+			ok, err = matchesCombination(document, field, condition)
+		default:
+			value, exists := lookupField(document, field)
+			ok, err = matchesCondition(value, exists, condition)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchesCombination reports whether the document meets all ($and) or any ($or) of the selectors
+func matchesCombination(document map[string]interface{}, combination string, selectors interface{}) (bool, error) {
+	list, ok := selectors.([]interface{})
+	if !ok {
+		return false, fmt.Errorf("%s takes a list of selectors", combination)
+	}
+	for _, item := range list {
+		selector, ok := item.(map[string]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s takes a list of selectors", combination)
+		}
+		ok, err := matchesSelector(document, selector)
+		if err != nil {
+			return false, err
+		}
+		if ok && combination == "$or" {
+			return true, nil
+		}
+		if !ok && combination == "$and" {
+			return false, nil
+		}
+	}
+	return combination == "$and", nil
+}
+
+// matchesCondition reports whether a field value meets a condition, which is either a value the field must equal or a set of operators
+func matchesCondition(value interface{}, exists bool, condition interface{}) (bool, error) {
+	operators, ok := condition.(map[string]interface{})
+	if !ok || !hasOperators(operators) {
+		return exists && reflect.DeepEqual(value, condition), nil
+	}
+	for operator, operand := range operators {
+		var ok bool
+		switch operator {
+		case "$eq":
+			ok = exists && reflect.DeepEqual(value, operand)
+		case "$ne":
+			ok = exists && !reflect.DeepEqual(value, operand)
+		case "$gt", "$gte", "$lt", "$lte":
+			ok = exists && compares(value, operator, operand)
+		case "$exists":
+			ok = exists == (operand == true)
+		case "$in":
+			list, isList := operand.([]interface{})
+			if !isList {
+				return false, fmt.Errorf("$in takes a list of values")
+			}
+			for _, item := range list {
+				ok = ok || (exists && reflect.DeepEqual(value, item))
+			}
+		default:
+			return false, fmt.Errorf("unsupported selector operator %s", operator)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// hasOperators reports whether every key of the map is an operator
+func hasOperators(condition map[string]interface{}) bool {
+	for key := range condition {
+		if !strings.HasPrefix(key, "$") {
+			return false
+		}
+	}
+	return len(condition) > 0
+}
+
+// compares reports whether the value stands in the given relation to the operand. Like CouchDB, numbers are only compared with numbers and strings with strings, so that RFC 3339 timestamps compare chronologically
+func compares(value interface{}, operator string, operand interface{}) bool {
+	var order int
+	switch v := value.(type) {
+	case float64:
+		o, ok := operand.(float64)
+		if !ok {
+			return false
+		}
+		order = cmp.Compare(v, o)
+	case string:
+		o, ok := operand.(string)
+		if !ok {
+			return false
+		}
+		order = cmp.Compare(v, o)
+	default:
+		return false
+	}
+	switch operator {
+	case "$gt":
+		return order > 0
+	case "$gte":
+		return order >= 0
+	case "$lt":
+		return order < 0
+	default:
+		return order <= 0
+	}
+}
+
+// lookupField returns the value of a dotted field path, e.g., "Location.City", and whether it exists
+func lookupField(document map[string]interface{}, field string) (interface{}, bool) {
+	var value interface{} = document
+	for _, name := range strings.Split(field, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[name]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
@@ -0,0 +1,81 @@
+package chaincodetest
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// documentedSpec matches an entry of the "Contains the following specifications: 1) SPEC_A, 2) SPEC_B" doc comment convention
+var documentedSpec = regexp.MustCompile(`\d+\) (SPEC_\w+)`)
+
+// ContractFunction is an exported method of a contract as written in its source
+type ContractFunction struct {
+	Called     []string // SPEC_ functions called directly in the body, in order of first call
+	Documented []string // SPEC_ functions listed in the doc comment, in order, without repetitions
+}
+
+// ContractFunctions parses the non-test Go files in dir and returns every exported method of the named contract type, e.g., "SmartContract"
+func ContractFunctions(dir string, contractType string) (map[string]*ContractFunction, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	functions := map[string]*ContractFunction{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, entry.Name()), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || !funcDecl.Name.IsExported() || !isMethodOf(funcDecl, contractType) {
+				continue
+			}
+			function := &ContractFunction{Called: []string{}, Documented: []string{}}
+			for _, match := range documentedSpec.FindAllStringSubmatch(funcDecl.Doc.Text(), -1) {
+				function.Documented = appendUnique(function.Documented, match[1])
+			}
+			ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+				if call, ok := node.(*ast.CallExpr); ok {
+					if ident, ok := call.Fun.(*ast.Ident); ok && strings.HasPrefix(ident.Name, "SPEC_") {
+						function.Called = appendUnique(function.Called, ident.Name)
+					}
+				}
+				return true
+			})
+			functions[funcDecl.Name.Name] = function
+		}
+	}
+	return functions, nil
+}
+
+func isMethodOf(funcDecl *ast.FuncDecl, contractType string) bool {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) != 1 {
+		return false
+	}
+	receiverType := funcDecl.Recv.List[0].Type
+	if star, ok := receiverType.(*ast.StarExpr); ok {
+		receiverType = star.X
+	}
+	ident, ok := receiverType.(*ast.Ident)
+	return ok && ident.Name == contractType
+}
+
+func appendUnique(names []string, name string) []string {
+	for _, existing := range names {
+		if existing == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
@@ -0,0 +1,378 @@
+// Package chaincodetest provides an in-memory ledger for unit testing the contracts without a running Fabric network.
+// It mirrors the peer's behaviour where the contracts depend on it: writes only become visible once their transaction
+// is committed, range queries skip composite keys, history is returned newest first, only the last event set by a
+// transaction is delivered and rich queries evaluate the part of the CouchDB selector syntax the contracts use.
+package chaincodetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	compositeKeyNamespace = "\x00"
+	emptyKeySubstitute    = "\x01"
+)
+
+// GenesisTime is the timestamp of the first transaction submitted to a new Stub
+var GenesisTime = time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC)
+
+// TxInterval is the time that passes between two consecutive transactions
+const TxInterval = time.Minute
+
+// Chaincode answers the invocations of a chaincode the contracts call with InvokeChaincode
+type Chaincode func(args [][]byte) peer.Response
+
+// Stub is an in-memory shim.ChaincodeStubInterface. Functions the contracts do not use panic when called
+type Stub struct {
+	shim.ChaincodeStubInterface
+
+	channelID   string
+	chaincodes  map[string]Chaincode // keyed by channel and chaincode name
+	events      []*peer.ChaincodeEvent
+	history     map[string][]*queryresult.KeyModification
+	nextTxTime  time.Time
+	pending     map[string][]byte // the write set of the current transaction, nil values are deletions
+	pendingEvt  *peer.ChaincodeEvent
+	state       map[string][]byte
+	txCount     int
+	txID        string
+	txTimestamp time.Time
+}
+
+// NewStub returns an empty ledger for the given channel, with a transaction already started
+func NewStub(channelID string) *Stub {
+	stub := &Stub{
+		channelID:  channelID,
+		chaincodes: map[string]Chaincode{},
+		history:    map[string][]*queryresult.KeyModification{},
+		nextTxTime: GenesisTime,
+		state:      map[string][]byte{},
+	}
+	stub.Begin()
+	return stub
+}
+
+// Begin starts a new transaction with a fresh transaction ID and a timestamp TxInterval after the previous one, discarding any uncommitted writes
+func (s *Stub) Begin() {
+	s.txCount++
+	sum := sha256.Sum256([]byte(s.channelID + "/" + strconv.Itoa(s.txCount)))
+	s.txID = hex.EncodeToString(sum[:])
+	s.txTimestamp = s.nextTxTime
+	s.nextTxTime = s.nextTxTime.Add(TxInterval)
+	s.pending = map[string][]byte{}
+	s.pendingEvt = nil
+}
+
+// Commit applies the write set and the event of the current transaction to the ledger and starts a new transaction
+func (s *Stub) Commit() {
+	keys := make([]string, 0, len(s.pending))
+	for key := range s.pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := s.pending[key]
+		if value == nil {
+			delete(s.state, key)
+		} else {
+			s.state[key] = value
+		}
+		s.history[key] = append(s.history[key], &queryresult.KeyModification{
+			IsDelete:  value == nil,
+			Timestamp: timestamppb.New(s.txTimestamp),
+			TxId:      s.txID,
+			Value:     value,
+		})
+	}
+	if s.pendingEvt != nil {
+		s.events = append(s.events, s.pendingEvt)
+	}
+	s.Begin()
+}
+
+// Rollback discards the write set and the event of the current transaction and starts a new transaction
+func (s *Stub) Rollback() {
+	s.Begin()
+}
+
+// SetNextTxTimestamp sets the timestamp of the next transaction started by Begin, Commit or Rollback
+func (s *Stub) SetNextTxTimestamp(timestamp time.Time) {
+	s.nextTxTime = timestamp.UTC()
+}
+
+// Seed writes a value directly to the committed state in a transaction of its own, bypassing the contracts
+func (s *Stub) Seed(key string, value []byte) {
+	s.pending[key] = value
+	s.Commit()
+}
+
+// DeployChaincode makes a chaincode available to InvokeChaincode on the given channel
+func (s *Stub) DeployChaincode(channelID string, chaincodeName string, chaincode Chaincode) {
+	s.chaincodes[channelID+"/"+chaincodeName] = chaincode
+}
+
+// Events returns the events of every committed transaction, oldest first
+func (s *Stub) Events() []*peer.ChaincodeEvent {
+	return s.events
+}
+
+// LastEvent returns the event of the most recently committed transaction that set one, or nil if there is none
+func (s *Stub) LastEvent() *peer.ChaincodeEvent {
+	if len(s.events) == 0 {
+		return nil
+	}
+	return s.events[len(s.events)-1]
+}
+
+// Keys returns every committed key, composite keys included, in ledger order
+func (s *Stub) Keys() []string {
+	keys := make([]string, 0, len(s.state))
+	for key := range s.state {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetChannelID returns the channel the stub was created for
+func (s *Stub) GetChannelID() string {
+	return s.channelID
+}
+
+// GetTxID returns the ID of the current transaction
+func (s *Stub) GetTxID() string {
+	return s.txID
+}
+
+// GetTxTimestamp returns the timestamp of the current transaction
+func (s *Stub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
+	return timestamppb.New(s.txTimestamp), nil
+}
+
+// GetState returns the committed value of a key. As on a peer, writes of the current transaction are not visible
+func (s *Stub) GetState(key string) ([]byte, error) {
+	if key == "" {
+		return nil, fmt.Errorf("key must not be an empty string")
+	}
+	return s.state[key], nil
+}
+
+// PutState adds a write to the write set of the current transaction
+func (s *Stub) PutState(key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	if len(value) == 0 {
+		// The peer treats an empty value as a deletion
+		value = nil
+	}
+	s.pending[key] = value
+	return nil
+}
+
+// DelState adds a deletion to the write set of the current transaction
+func (s *Stub) DelState(key string) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+	s.pending[key] = nil
+	return nil
+}
+
+// SetEvent sets the event of the current transaction, replacing any event set before
+func (s *Stub) SetEvent(name string, payload []byte) error {
+	if name == "" {
+		return fmt.Errorf("event name can not be empty string")
+	}
+	s.pendingEvt = &peer.ChaincodeEvent{
+		ChaincodeId: s.channelID,
+		EventName:   name,
+		Payload:     payload,
+		TxId:        s.txID,
+	}
+	return nil
+}
+
+// InvokeChaincode calls a chaincode deployed with DeployChaincode. An empty channel means the channel of the stub. As on a peer, a chaincode that is not deployed on the channel answers with an error
+func (s *Stub) InvokeChaincode(chaincodeName string, args [][]byte, channel string) peer.Response {
+	if channel == "" {
+		channel = s.channelID
+	}
+	chaincode, found := s.chaincodes[channel+"/"+chaincodeName]
+	if !found {
+		return shim.Error(fmt.Sprintf("chaincode %s is not deployed on channel %s", chaincodeName, channel))
+	}
+	return chaincode(args)
+}
+
+// CreateCompositeKey combines the object type and attributes the same way as the peer
+func (s *Stub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return shim.CreateCompositeKey(objectType, attributes)
+}
+
+// SplitCompositeKey splits a composite key into its object type and attributes
+func (s *Stub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	if !isCompositeKey(compositeKey) {
+		return "", nil, fmt.Errorf("key %q is not a composite key", compositeKey)
+	}
+	components := []string{}
+	componentStart := 1
+	for i := 1; i < len(compositeKey); i++ {
+		if compositeKey[i] == 0x00 {
+			components = append(components, compositeKey[componentStart:i])
+			componentStart = i + 1
+		}
+	}
+	return components[0], components[1:], nil
+}
+
+// GetStateByRange returns the committed simple keys in [startKey, endKey). An empty endKey means no upper bound
+func (s *Stub) GetStateByRange(startKey, endKey string) (shim.StateQueryIteratorInterface, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	iterator, _ := s.rangeQuery(startKey, endKey, 0, "")
+	return iterator, nil
+}
+
+// GetStateByRangeWithPagination returns at most pageSize committed simple keys in [startKey, endKey), starting from the bookmark
+func (s *Stub) GetStateByRangeWithPagination(startKey, endKey string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, nil, err
+	}
+	if startKey == "" {
+		startKey = emptyKeySubstitute
+	}
+	iterator, metadata := s.rangeQuery(startKey, endKey, pageSize, bookmark)
+	return iterator, metadata, nil
+}
+
+// GetStateByPartialCompositeKey returns the committed composite keys that start with the given object type and attributes
+func (s *Stub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	partialKey, err := shim.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	iterator, _ := s.rangeQuery(partialKey, partialKey+string(utf8.MaxRune), 0, "")
+	return iterator, nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination returns at most pageSize committed composite keys that start with the given object type and attributes, starting from the bookmark
+func (s *Stub) GetStateByPartialCompositeKeyWithPagination(objectType string, attributes []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+	partialKey, err := shim.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+	iterator, metadata := s.rangeQuery(partialKey, partialKey+string(utf8.MaxRune), pageSize, bookmark)
+	return iterator, metadata, nil
+}
+
+// GetHistoryForKey returns every committed modification of a key, newest first
+func (s *Stub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	modifications := s.history[key]
+	newestFirst := make([]*queryresult.KeyModification, 0, len(modifications))
+	for i := len(modifications) - 1; i >= 0; i-- {
+		newestFirst = append(newestFirst, modifications[i])
+	}
+	return &historyIterator{modifications: newestFirst}, nil
+}
+
+// rangeQuery returns the committed keys in [startKey, endKey) in ledger order. A pageSize of 0 returns every key
+func (s *Stub) rangeQuery(startKey, endKey string, pageSize int32, bookmark string) (*stateIterator, *peer.QueryResponseMetadata) {
+	if bookmark != "" && bookmark > startKey {
+		startKey = bookmark
+	}
+	results := []*queryresult.KV{}
+	nextBookmark := ""
+	for _, key := range s.Keys() {
+		if key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		// A range over simple keys never returns composite keys
+		if isCompositeKey(key) && !isCompositeKey(startKey) {
+			continue
+		}
+		if pageSize > 0 && int32(len(results)) == pageSize {
+			nextBookmark = key
+			break
+		}
+		results = append(results, &queryresult.KV{Key: key, Namespace: s.channelID, Value: s.state[key]})
+	}
+	metadata := &peer.QueryResponseMetadata{Bookmark: nextBookmark, FetchedRecordsCount: int32(len(results))}
+	return &stateIterator{results: results}, metadata
+}
+
+func isCompositeKey(key string) bool {
+	return len(key) > 0 && key[0] == compositeKeyNamespace[0]
+}
+
+func validateSimpleKeys(keys ...string) error {
+	for _, key := range keys {
+		if isCompositeKey(key) {
+			return fmt.Errorf("first character of the key [%s] contains a null character which is not allowed", key)
+		}
+	}
+	return nil
+}
+
+// stateIterator iterates over the results of a range query
+type stateIterator struct {
+	closed  bool
+	results []*queryresult.KV
+}
+
+func (it *stateIterator) HasNext() bool {
+	return !it.closed && len(it.results) > 0
+}
+
+func (it *stateIterator) Next() (*queryresult.KV, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	result := it.results[0]
+	it.results = it.results[1:]
+	return result, nil
+}
+
+func (it *stateIterator) Close() error {
+	it.closed = true
+	return nil
+}
+
+// historyIterator iterates over the modifications of a key
+type historyIterator struct {
+	closed        bool
+	modifications []*queryresult.KeyModification
+}
+
+func (it *historyIterator) HasNext() bool {
+	return !it.closed && len(it.modifications) > 0
+}
+
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	if !it.HasNext() {
+		return nil, fmt.Errorf("no more results")
+	}
+	modification := it.modifications[0]
+	it.modifications = it.modifications[1:]
+	return modification, nil
+}
+
+func (it *historyIterator) Close() error {
+	it.closed = true
+	return nil
+}
@@ -0,0 +1,234 @@
+package chaincodetest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/peer"
+)
+
+func TestWritesAreOnlyVisibleAfterCommit(t *testing.T) {
+	ctx := NewTransactionContext("testchannel", "Org1MSP")
+	err := ctx.Submit("Org1MSP", func() error {
+		if err := ctx.GetStub().PutState("order_1", []byte("a")); err != nil {
+			return err
+		}
+		value, err := ctx.GetStub().GetState("order_1")
+		if err != nil {
+			return err
+		}
+		if value != nil {
+			return fmt.Errorf("uncommitted write is visible: %s", value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _ := ctx.GetStub().GetState("order_1")
+	if string(value) != "a" {
+		t.Fatalf("expected the committed value 'a', got %q", value)
+	}
+}
+
+func TestFailedTransactionIsDiscarded(t *testing.T) {
+	ctx := NewTransactionContext("testchannel", "Org1MSP")
+	err := ctx.Submit("Org6MSP", func() error {
+		_ = ctx.GetStub().PutState("order_1", []byte("a"))
+		_ = ctx.GetStub().SetEvent("AssetCreated", []byte("{}"))
+		return fmt.Errorf("endorsement failed")
+	})
+	if err == nil {
+		t.Fatal("expected the error of the transaction")
+	}
+	if value, _ := ctx.GetStub().GetState("order_1"); value != nil {
+		t.Fatalf("write of a failed transaction was committed: %s", value)
+	}
+	if event := ctx.Stub().LastEvent(); event != nil {
+		t.Fatalf("event of a failed transaction was committed: %s", event.EventName)
+	}
+}
+
+func TestSubmitSwitchesIdentity(t *testing.T) {
+	ctx := NewTransactionContext("testchannel", "Org1MSP")
+	for _, mspID := range []string{"Org1MSP", "Org6MSP"} {
+		err := ctx.Submit(mspID, func() error {
+			got, err := ctx.GetClientIdentity().GetMSPID()
+			if err != nil {
+				return err
+			}
+			if got != mspID {
+				return fmt.Errorf("expected %s, got %s", mspID, got)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRangeQuerySkipsCompositeKeys(t *testing.T) {
+	ctx := NewTransactionContext("testchannel", "Org1MSP")
+	stub := ctx.Stub()
+	linkKey, _ := stub.CreateCompositeKey("child~parent", []string{"lot_1", "cottonyarn_1"})
+	for _, key := range []string{"lot_2", linkKey, "lot_1", "cottonyarn_1"} {
+		stub.Seed(key, []byte{0x00})
+	}
+
+	iterator, err := stub.GetStateByRange("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []string{}
+	for iterator.HasNext() {
+		kv, _ := iterator.Next()
+		keys = append(keys, kv.Key)
+	}
+	if fmt.Sprint(keys) != "[cottonyarn_1 lot_1 lot_2]" {
+		t.Fatalf("unexpected range query result %v", keys)
+	}
+
+	iterator, err = stub.GetStateByPartialCompositeKey("child~parent", []string{"lot_1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !iterator.HasNext() {
+		t.Fatal("expected the link key")
+	}
+	kv, _ := iterator.Next()
+	_, attributes, _ := stub.SplitCompositeKey(kv.Key)
+	if fmt.Sprint(attributes) != "[lot_1 cottonyarn_1]" {
+		t.Fatalf("unexpected composite key attributes %v", attributes)
+	}
+}
+
+func TestRangeQueryPagination(t *testing.T) {
+	stub := NewStub("testchannel")
+	for i := 1; i <= 5; i++ {
+		stub.Seed(fmt.Sprintf("lot_%d", i), []byte{0x00})
+	}
+
+	tests := []struct {
+		bookmark     string
+		wantBookmark string
+		wantCount    int32
+	}{
+		{"", "lot_3", 2},
+		{"lot_3", "lot_5", 2},
+		{"lot_5", "", 1},
+	}
+	for _, test := range tests {
+		_, metadata, err := stub.GetStateByRangeWithPagination("lot_", "lot_~", 2, test.bookmark)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if metadata.Bookmark != test.wantBookmark || metadata.FetchedRecordsCount != test.wantCount {
+			t.Errorf("bookmark %q: expected (%q, %d), got (%q, %d)", test.bookmark, test.wantBookmark, test.wantCount, metadata.Bookmark, metadata.FetchedRecordsCount)
+		}
+	}
+}
+
+func TestHistoryIsNewestFirst(t *testing.T) {
+	stub := NewStub("testchannel")
+	stub.Seed("order_1", []byte("v1"))
+	stub.Seed("order_1", []byte("v2"))
+	_ = stub.DelState("order_1")
+	stub.Commit()
+
+	iterator, err := stub.GetHistoryForKey("order_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		isDelete bool
+		value    string
+	}{{true, ""}, {false, "v2"}, {false, "v1"}}
+	for i, w := range want {
+		modification, err := iterator.Next()
+		if err != nil {
+			t.Fatalf("modification %d: %v", i, err)
+		}
+		if modification.IsDelete != w.isDelete || string(modification.Value) != w.value {
+			t.Errorf("modification %d: expected (%t, %q), got (%t, %q)", i, w.isDelete, w.value, modification.IsDelete, modification.Value)
+		}
+	}
+	if iterator.HasNext() {
+		t.Error("expected exactly three modifications")
+	}
+}
+
+func TestOnlyLastEventIsDelivered(t *testing.T) {
+	stub := NewStub("testchannel")
+	_ = stub.SetEvent("First", nil)
+	_ = stub.SetEvent("Second", nil)
+	stub.Commit()
+
+	if len(stub.Events()) != 1 || stub.LastEvent().EventName != "Second" {
+		t.Fatalf("expected only the Second event, got %v", stub.Events())
+	}
+}
+
+func TestTxTimestampAdvances(t *testing.T) {
+	stub := NewStub("testchannel")
+	first, _ := stub.GetTxTimestamp()
+	stub.Commit()
+	second, _ := stub.GetTxTimestamp()
+	if !first.AsTime().Equal(GenesisTime) || second.AsTime().Sub(first.AsTime()) != TxInterval {
+		t.Fatalf("unexpected timestamps %v, %v", first.AsTime(), second.AsTime())
+	}
+}
+
+func TestInvokeChaincodeAcrossChannels(t *testing.T) {
+	stub := NewStub("production")
+	stub.DeployChaincode("admin-channel", "admin", func(args [][]byte) peer.Response {
+		return shim.Success([]byte(string(args[0]) + " " + string(args[1])))
+	})
+
+	response := stub.InvokeChaincode("admin", [][]byte{[]byte("GetAsset"), []byte("order_1")}, "admin-channel")
+	if response.Status != shim.OK || string(response.Payload) != "GetAsset order_1" {
+		t.Fatalf("unexpected response %v", response)
+	}
+	// The chaincode is not deployed on the stub's own channel
+	response = stub.InvokeChaincode("admin", [][]byte{[]byte("GetAsset"), []byte("order_1")}, "")
+	if response.Status != shim.ERROR || response.Message != "chaincode admin is not deployed on channel production" {
+		t.Fatalf("unexpected response %v", response)
+	}
+}
+
+func TestGetQueryResultWithPagination(t *testing.T) {
+	ctx := NewTransactionContext("testchannel", "Org1MSP")
+	ctx.Stub().Seed("lot_1", []byte(`{"AssemblyDate":"2024-07-03T00:00:00Z","IsFlagged":true,"Origin":"Vadodara"}`))
+	ctx.Stub().Seed("lot_2", []byte(`{"AssemblyDate":"2024-08-03T00:00:00Z","IsFlagged":true,"Origin":"Vadodara"}`))
+	ctx.Stub().Seed("lot_3", []byte(`{"AssemblyDate":"2024-07-04T00:00:00Z","IsFlagged":false,"Origin":"Karachi"}`))
+	ctx.Stub().Seed("lot_4", []byte(`{"AssemblyDate":"2024-07-05T00:00:00Z","IsFlagged":true,"Origin":"Vadodara"}`))
+	ctx.Stub().Seed("\x00type~id\x00lot_\x00lot_1\x00", []byte{0x00})
+	query := func(selector string, pageSize int32, bookmark string) ([]string, string) {
+		t.Helper()
+		resultsIterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(`{"selector":`+selector+`}`, pageSize, bookmark)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := []string{}
+		for resultsIterator.HasNext() {
+			result, _ := resultsIterator.Next()
+			keys = append(keys, result.Key)
+		}
+		return keys, metadata.GetBookmark()
+	}
+
+	julyFlaggedInVadodara := `{"IsFlagged":true,"Origin":"Vadodara","AssemblyDate":{"$gte":"2024-07-01T00:00:00Z","$lt":"2024-08-01T00:00:00Z"}}`
+	if keys, bookmark := query(julyFlaggedInVadodara, 1, ""); fmt.Sprint(keys) != "[lot_1]" || bookmark != "lot_4" {
+		t.Errorf("unexpected first page %v with bookmark %q", keys, bookmark)
+	}
+	if keys, bookmark := query(julyFlaggedInVadodara, 1, "lot_4"); fmt.Sprint(keys) != "[lot_4]" || bookmark != "" {
+		t.Errorf("unexpected last page %v with bookmark %q", keys, bookmark)
+	}
+	if keys, _ := query(`{"$or":[{"Origin":"Karachi"},{"AssemblyDate":{"$gt":"2024-08-01T00:00:00Z"}}]}`, 10, ""); fmt.Sprint(keys) != "[lot_2 lot_3]" {
+		t.Errorf("unexpected matches %v", keys)
+	}
+	if _, _, err := ctx.GetStub().GetQueryResultWithPagination(`{"selector":{"Origin":{"$regex":"^V"}}}`, 10, ""); err == nil {
+		t.Errorf("expected the unsupported operator to be refused")
+	}
+}
@@ -0,0 +1,22 @@
+// Package clock provides the single source of time for the contracts. Contract functions must never call time.Now(),
+// because endorsing peers execute a transaction at slightly different moments and would produce different write sets.
+package clock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TxTimestamp returns the timestamp chosen by the client when creating the transaction, which is identical on every endorsing peer
+func TxTimestamp(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	if txTimestamp == nil {
+		return time.Time{}, fmt.Errorf("the transaction has no timestamp")
+	}
+	return txTimestamp.AsTime().UTC(), nil
+}
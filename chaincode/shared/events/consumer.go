@@ -0,0 +1,71 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Event is a decoded chaincode event. Data holds a pointer to the data struct matching the event name, e.g., *AssetCreatedData
+type Event struct {
+	Envelope
+	Data interface{}
+}
+
+// Handler processes a decoded event
+type Handler func(event *Event) error
+
+// Consumer dispatches chaincode events to the handlers registered for their names. It is meant to be fed with the
+// event name and payload of each chaincode event received from a Fabric Gateway event stream
+type Consumer struct {
+	handlers map[string][]Handler
+}
+
+// NewConsumer returns a Consumer without any handlers
+func NewConsumer() *Consumer {
+	return &Consumer{handlers: make(map[string][]Handler)}
+}
+
+// On registers a handler for the named event. Handlers of the same event are called in the order they were registered
+func (c *Consumer) On(name string, handler Handler) {
+	c.handlers[name] = append(c.handlers[name], handler)
+}
+
+// Handle decodes an event and passes it to the handlers registered for its name. Events without handlers are decoded and then ignored
+func (c *Consumer) Handle(name string, payload []byte) error {
+	event, err := Decode(name, payload)
+	if err != nil {
+		return err
+	}
+	for _, handler := range c.handlers[name] {
+		if err := handler(event); err != nil {
+			return fmt.Errorf("failed to handle %s event of transaction %s: %v", name, event.TxID, err)
+		}
+	}
+	return nil
+}
+
+// Decode decodes the payload of a chaincode event, refusing payloads written with a newer schema version than this package understands
+func Decode(name string, payload []byte) (*Event, error) {
+	var envelope Envelope
+	err := json.Unmarshal(payload, &envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s event: %v", name, err)
+	}
+	if envelope.Name != name {
+		return nil, fmt.Errorf("event name %s does not match the payload's name %s", name, envelope.Name)
+	}
+	if envelope.SchemaVersion < 1 || envelope.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("unsupported schema version %d of %s event, supported versions: 1 to %d", envelope.SchemaVersion, name, SchemaVersion)
+	}
+
+	data, err := newData(name)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(envelope.Data, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s event data: %v", name, err)
+	}
+
+	return &Event{Envelope: envelope, Data: data}, nil
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsumer(t *testing.T) {
+	consumer := NewConsumer()
+	var handled []string
+	consumer.On(AssetFlagged, func(event *Event) error {
+		handled = append(handled, "first "+event.Data.(*AssetFlaggedData).AssetID)
+		return nil
+	})
+	consumer.On(AssetFlagged, func(event *Event) error {
+		handled = append(handled, "second "+event.Data.(*AssetFlaggedData).AssetID)
+		return nil
+	})
+
+	flagged, err := New(AssetFlagged, "tx1", time.Time{}, "Org3MSP", AssetFlaggedData{AssetID: "lot_1", FlagReason: "contaminated", IsFlagged: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := consumer.Handle(AssetFlagged, flagged); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(handled) != "[first lot_1 second lot_1]" {
+		t.Errorf("expected both handlers to be called in order, got %v", handled)
+	}
+
+	// Events without handlers are decoded and ignored
+	created, err := New(AssetCreated, "tx2", time.Time{}, "Org4MSP", AssetCreatedData{AssetID: "lot_2", AssetType: "lot_"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := consumer.Handle(AssetCreated, created); err != nil || len(handled) != 2 {
+		t.Errorf("expected the AssetCreated event to be ignored, got %v and %v", err, handled)
+	}
+
+	// Events this package does not know are refused
+	unknown, err := New("AssetTeleported", "tx3", time.Time{}, "Org4MSP", struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := consumer.Handle("AssetTeleported", unknown); err == nil || !strings.Contains(err.Error(), "unknown event: AssetTeleported") {
+		t.Errorf("expected the unknown event to be refused, got %v", err)
+	}
+
+	// The error of a handler is returned with the transaction ID
+	consumer.On(AssetCreated, func(event *Event) error { return fmt.Errorf("database unavailable") })
+	if err := consumer.Handle(AssetCreated, created); err == nil || !strings.Contains(err.Error(), "failed to handle AssetCreated event of transaction tx2: database unavailable") {
+		t.Errorf("expected the handler error, got %v", err)
+	}
+}
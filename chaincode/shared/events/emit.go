@@ -0,0 +1,27 @@
+package events
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/clock"
+)
+
+// Emit sets a versioned chaincode event on the transaction. Fabric only delivers the last event set by a transaction, so each function emits at most one
+func Emit(ctx contractapi.TransactionContextInterface, name string, data interface{}) error {
+	// Retrieve the invoking organization's MSP ID
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+	// Retrieve the transaction timestamp
+	txTimestamp, err := clock.TxTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := New(name, ctx.GetStub().GetTxID(), txTimestamp, clientMSPID, data)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent(name, payload)
+}
@@ -0,0 +1,260 @@
+// Package events defines the chaincode events emitted by the admin-channel and production-channel contracts. Every
+// event payload is an Envelope whose Data holds the event-specific fields, so that consumers can check the schema
+// version before decoding the rest.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is the version of the envelope and data schemas below. It is incremented whenever a field is removed or changes meaning
+const SchemaVersion = 1
+
+// Names of the events emitted by the contracts
+const (
+	AssetArchived           = "AssetArchived"
+	AssetCreated            = "AssetCreated"
+	AssetFlagged            = "AssetFlagged"
+	AssetNotesUpdated       = "AssetNotesUpdated"
+	AssetOwnershipChanged   = "AssetOwnershipChanged"
+	AssetsCreated           = "AssetsCreated"
+	AssetsMigrated          = "AssetsMigrated"
+	ContainerStatusChanged  = "ContainerStatusChanged"
+	FactoryApproved         = "FactoryApproved"
+	LotDispatched           = "LotDispatched"
+	LotOwnershipTransferred = "LotOwnershipTransferred"
+	LotReceived             = "LotReceived"
+	LotSplit                = "LotSplit"
+	LotsMerged              = "LotsMerged"
+	OrderAcceptanceChanged  = "OrderAcceptanceChanged"
+	OrderStatusChanged      = "OrderStatusChanged"
+	PlanApproved            = "PlanApproved"
+	RecallClosed            = "RecallClosed"
+	RecallInitiated         = "RecallInitiated"
+	RolePolicyUpdated       = "RolePolicyUpdated"
+	YieldPolicyUpdated      = "YieldPolicyUpdated"
+)
+
+// Envelope is the payload of every chaincode event
+type Envelope struct {
+	Data          json.RawMessage `json:"Data"`
+	InvokerID     string          `json:"InvokerID"` // MSP ID of the invoking organization
+	Name          string          `json:"Name"`
+	SchemaVersion int             `json:"SchemaVersion"`
+	Timestamp     time.Time       `json:"Timestamp"` // transaction timestamp
+	TxID          string          `json:"TxID"`
+}
+
+// AssetArchivedData is emitted by ArchiveAsset
+type AssetArchivedData struct {
+	AssetID string `json:"AssetID"`
+	Reason  string `json:"Reason"`
+}
+
+// AssetCreatedData is emitted by every Create* function
+type AssetCreatedData struct {
+	AssetID   string `json:"AssetID"`
+	AssetType string `json:"AssetType"` // ID prefix of the asset, e.g., "lot_"
+}
+
+// AssetFlaggedData is emitted by SetFlag
+type AssetFlaggedData struct {
+	AssetID    string `json:"AssetID"`
+	FlagReason string `json:"FlagReason"`
+	IsFlagged  bool   `json:"IsFlagged"`
+}
+
+// AssetNotesUpdatedData is emitted by SetNotes
+type AssetNotesUpdatedData struct {
+	AssetID string `json:"AssetID"`
+}
+
+// AssetOwnershipChangedData is emitted by TransferCarton and TransferContainer
+type AssetOwnershipChangedData struct {
+	AssetID       string   `json:"AssetID"`
+	ContentIDs    []string `json:"ContentIDs"` // IDs of the cartons transferred along with a container
+	NewOwner      string   `json:"NewOwner"`
+	PreviousOwner string   `json:"PreviousOwner"`
+}
+
+// AssetsCreatedData is emitted by every Create*Batch function instead of an AssetCreated event per asset
+type AssetsCreatedData struct {
+	AssetIDs  []string `json:"AssetIDs"`  // in the order of the batch
+	AssetType string   `json:"AssetType"` // ID prefix of the assets, e.g., "button_"
+}
+
+// AssetsMigratedData is emitted by MigrateAssets
+type AssetsMigratedData struct {
+	AssetIDs    []string `json:"AssetIDs"`
+	FromVersion int      `json:"FromVersion"`
+	HasMore     bool     `json:"HasMore"` // assets at FromVersion remain to be migrated
+	ToVersion   int      `json:"ToVersion"`
+}
+
+// ContainerStatusChangedData is emitted by DepartContainer, ArriveContainer and DeliverContainer
+type ContainerStatusChangedData struct {
+	ContainerID    string `json:"ContainerID"`
+	PreviousStatus string `json:"PreviousStatus"`
+	Status         string `json:"Status"`
+}
+
+// FactoryApprovedData is emitted by SetFactoryApproval and SetFactoryStatus
+type FactoryApprovedData struct {
+	Approval           bool   `json:"Approval"`
+	FactoryID          string `json:"FactoryID"`
+	IsAuditorApproved  bool   `json:"IsAuditorApproved"`
+	IsRetailerApproved bool   `json:"IsRetailerApproved"`
+	Status             string `json:"Status"`
+}
+
+// LotDispatchedData is emitted by DispatchLot
+type LotDispatchedData struct {
+	Destination string `json:"Destination"`
+	LotID       string `json:"LotID"`
+	ReceiverID  string `json:"ReceiverID"`
+	SenderID    string `json:"SenderID"`
+}
+
+// LotOwnershipTransferredData is emitted by UpdateLotOwner
+type LotOwnershipTransferredData struct {
+	LotID         string `json:"LotID"`
+	NewOwner      string `json:"NewOwner"`
+	PreviousOwner string `json:"PreviousOwner"`
+}
+
+// LotReceivedData is emitted by ReceiveLot
+type LotReceivedData struct {
+	Location   string `json:"Location"`
+	LotID      string `json:"LotID"`
+	ReceiverID string `json:"ReceiverID"`
+}
+
+// LotSplitData is emitted by SplitLot
+type LotSplitData struct {
+	LotID        string   `json:"LotID"`
+	SuccessorIDs []string `json:"SuccessorIDs"`
+}
+
+// LotsMergedData is emitted by MergeLots
+type LotsMergedData struct {
+	LotID          string   `json:"LotID"`
+	PredecessorIDs []string `json:"PredecessorIDs"`
+}
+
+// OrderAcceptanceChangedData is emitted by SetOrderAcceptance
+type OrderAcceptanceChangedData struct {
+	IsAccepted bool   `json:"IsAccepted"`
+	OrderID    string `json:"OrderID"`
+	PlanID     string `json:"PlanID"`
+}
+
+// OrderStatusChangedData is emitted by SetOrderStatus
+type OrderStatusChangedData struct {
+	OrderID        string `json:"OrderID"`
+	PreviousStatus string `json:"PreviousStatus"`
+	Status         string `json:"Status"`
+}
+
+// PlanApprovedData is emitted by SetPlanApproval and SetPlanStatus
+type PlanApprovedData struct {
+	Approval           bool   `json:"Approval"`
+	IsAuditorApproved  bool   `json:"IsAuditorApproved"`
+	IsRetailerApproved bool   `json:"IsRetailerApproved"`
+	PlanID             string `json:"PlanID"`
+	Status             string `json:"Status"`
+}
+
+// RecallClosedData is emitted by CloseRecall
+type RecallClosedData struct {
+	RecallID   string `json:"RecallID"`
+	Resolution string `json:"Resolution"`
+}
+
+// RecallInitiatedData is emitted by InitiateRecall
+type RecallInitiatedData struct {
+	AffectedAssetCount int    `json:"AffectedAssetCount"`
+	Reason             string `json:"Reason"`
+	RecallID           string `json:"RecallID"`
+	RootID             string `json:"RootID"`
+}
+
+// RolePolicyUpdatedData is emitted by SetRolePolicy
+type RolePolicyUpdatedData struct {
+	Roles   []string `json:"Roles"` // names of the roles of the new policy, sorted
+	Version int      `json:"Version"`
+}
+
+// YieldPolicyUpdatedData is emitted by SetYieldPolicy
+type YieldPolicyUpdatedData struct {
+	ExpectedYields map[string]float32 `json:"ExpectedYields"` // stage name to expected yield
+	Version        int                `json:"Version"`
+}
+
+// New builds the payload of an event with the current schema version
+func New(name string, txID string, timestamp time.Time, invokerID string, data interface{}) ([]byte, error) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s event data: %v", name, err)
+	}
+	envelope := Envelope{
+		Data:          dataJSON,
+		InvokerID:     invokerID,
+		Name:          name,
+		SchemaVersion: SchemaVersion,
+		Timestamp:     timestamp,
+		TxID:          txID,
+	}
+	return json.Marshal(envelope)
+}
+
+// newData returns a pointer to an empty data struct for the named event
+func newData(name string) (interface{}, error) {
+	switch name {
+	case AssetArchived:
+		return &AssetArchivedData{}, nil
+	case AssetCreated:
+		return &AssetCreatedData{}, nil
+	case AssetFlagged:
+		return &AssetFlaggedData{}, nil
+	case AssetNotesUpdated:
+		return &AssetNotesUpdatedData{}, nil
+	case AssetOwnershipChanged:
+		return &AssetOwnershipChangedData{}, nil
+	case AssetsCreated:
+		return &AssetsCreatedData{}, nil
+	case AssetsMigrated:
+		return &AssetsMigratedData{}, nil
+	case ContainerStatusChanged:
+		return &ContainerStatusChangedData{}, nil
+	case FactoryApproved:
+		return &FactoryApprovedData{}, nil
+	case LotDispatched:
+		return &LotDispatchedData{}, nil
+	case LotOwnershipTransferred:
+		return &LotOwnershipTransferredData{}, nil
+	case LotReceived:
+		return &LotReceivedData{}, nil
+	case LotSplit:
+		return &LotSplitData{}, nil
+	case LotsMerged:
+		return &LotsMergedData{}, nil
+	case OrderAcceptanceChanged:
+		return &OrderAcceptanceChangedData{}, nil
+	case OrderStatusChanged:
+		return &OrderStatusChangedData{}, nil
+	case PlanApproved:
+		return &PlanApprovedData{}, nil
+	case RecallClosed:
+		return &RecallClosedData{}, nil
+	case RecallInitiated:
+		return &RecallInitiatedData{}, nil
+	case RolePolicyUpdated:
+		return &RolePolicyUpdatedData{}, nil
+	case YieldPolicyUpdated:
+		return &YieldPolicyUpdatedData{}, nil
+	default:
+		return nil, fmt.Errorf("unknown event: %s", name)
+	}
+}
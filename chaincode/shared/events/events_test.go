@@ -0,0 +1,72 @@
+package events
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	timestamp := time.Date(2024, time.July, 1, 12, 0, 0, 0, time.UTC)
+	payload, err := New(LotOwnershipTransferred, "tx1", timestamp, "Org4MSP", LotOwnershipTransferredData{LotID: "lot_1", NewOwner: "Org5MSP", PreviousOwner: "Org4MSP"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := Decode(LotOwnershipTransferred, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Name != LotOwnershipTransferred || event.TxID != "tx1" || event.InvokerID != "Org4MSP" || !event.Timestamp.Equal(timestamp) || event.SchemaVersion != SchemaVersion {
+		t.Errorf("unexpected envelope %+v", event.Envelope)
+	}
+	data, ok := event.Data.(*LotOwnershipTransferredData)
+	if !ok || data.LotID != "lot_1" || data.NewOwner != "Org5MSP" || data.PreviousOwner != "Org4MSP" {
+		t.Errorf("unexpected data %+v", event.Data)
+	}
+}
+
+func TestDecodeRefusesInvalidPayloads(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr string
+	}{
+		{"newer schema version", `{"Name":"AssetCreated","SchemaVersion":2,"Data":{}}`, "unsupported schema version 2 of AssetCreated event, supported versions: 1 to 1"},
+		{"missing schema version", `{"Name":"AssetCreated","Data":{}}`, "unsupported schema version 0"},
+		{"other event", `{"Name":"AssetFlagged","SchemaVersion":1,"Data":{}}`, "event name AssetCreated does not match the payload's name AssetFlagged"},
+		{"not JSON", `AssetCreated`, "failed to unmarshal AssetCreated event"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Decode(AssetCreated, []byte(test.payload))
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("expected error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestEmit(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org4MSP")
+	var txID string
+	err := ctx.Submit("Org4MSP", func() error {
+		txID = ctx.GetStub().GetTxID()
+		return Emit(ctx, AssetCreated, AssetCreatedData{AssetID: "lot_1", AssetType: "lot_"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := ctx.Stub().LastEvent()
+	if last == nil || last.EventName != AssetCreated {
+		t.Fatalf("expected an AssetCreated event, got %v", last)
+	}
+	event, err := Decode(last.EventName, last.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.TxID != txID || event.InvokerID != "Org4MSP" || event.Data.(*AssetCreatedData).AssetID != "lot_1" {
+		t.Errorf("unexpected event %+v with data %+v", event.Envelope, event.Data)
+	}
+}
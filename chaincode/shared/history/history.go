@@ -0,0 +1,57 @@
+// Package history reads the committed versions of the records of the admin-channel and production-channel contracts
+// from the ledger's history, so that auditors can see who changed a field and when.
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AssetVersion is a single committed version of a record as stored in the ledger's history
+type AssetVersion struct {
+	Asset     map[string]interface{} `json:"Asset,omitempty" metadata:",optional"` // empty when the version deleted the record
+	IsDelete  bool                   `json:"IsDelete"`
+	Timestamp time.Time              `json:"Timestamp"`
+	TxID      string                 `json:"TxID"`
+}
+
+// GetAssetHistory returns every committed version of the record with a specific ID, newest first
+func GetAssetHistory(ctx contractapi.TransactionContextInterface, id string) ([]*AssetVersion, error) {
+	resultsIterator, err := ctx.GetStub().GetHistoryForKey(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of asset %s: %v", id, err)
+	}
+	defer resultsIterator.Close()
+
+	var versions []*AssetVersion
+	for resultsIterator.HasNext() {
+		modification, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through history of asset %s: %v", id, err)
+		}
+
+		version := AssetVersion{
+			IsDelete: modification.IsDelete,
+			TxID:     modification.TxId,
+		}
+		if modification.Timestamp != nil {
+			version.Timestamp = modification.Timestamp.AsTime()
+		}
+		// Deleted versions carry no value
+		if !modification.IsDelete {
+			err = json.Unmarshal(modification.Value, &version.Asset)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal version %s of asset %s: %v", modification.TxId, id, err)
+			}
+		}
+		versions = append(versions, &version)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("the asset %s has no history", id)
+	}
+
+	return versions, nil
+}
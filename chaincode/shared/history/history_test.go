@@ -0,0 +1,49 @@
+package history
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+func TestGetAssetHistory(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	writes := []func() error{
+		func() error { return ctx.GetStub().PutState("lot_1", []byte(`{"ID":"lot_1","IsFlagged":false}`)) },
+		func() error { return ctx.GetStub().PutState("lot_1", []byte(`{"ID":"lot_1","IsFlagged":true}`)) },
+		func() error { return ctx.GetStub().DelState("lot_1") },
+	}
+	var txIDs []string
+	for _, write := range writes {
+		err := ctx.Submit("Org1MSP", func() error {
+			txIDs = append(txIDs, ctx.GetStub().GetTxID())
+			return write()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := GetAssetHistory(ctx, "lot_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("expected 3 versions, got %d", len(versions))
+	}
+	// The newest version comes first, and the deletion carries no asset
+	if !versions[0].IsDelete || versions[0].Asset != nil || versions[0].TxID != txIDs[2] {
+		t.Errorf("expected the deletion first, got %+v", versions[0])
+	}
+	if versions[1].IsDelete || versions[1].Asset["IsFlagged"] != true || versions[2].Asset["IsFlagged"] != false {
+		t.Errorf("expected the flagged version before the original one, got %+v and %+v", versions[1], versions[2])
+	}
+	if !versions[1].Timestamp.After(versions[2].Timestamp) {
+		t.Errorf("expected the timestamps to decrease, got %v and %v", versions[1].Timestamp, versions[2].Timestamp)
+	}
+
+	if _, err := GetAssetHistory(ctx, "lot_2"); err == nil || !strings.Contains(err.Error(), "the asset lot_2 has no history") {
+		t.Errorf("expected the missing history to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package index keeps the composite keys under which the admin-channel and production-channel contracts find their
+// records by type and by owner, so that a query reads only its own partition of the world state instead of scanning
+// every record and matching keys by substring.
+package index
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/schema"
+)
+
+const (
+	// TypeIndex is the composite key object type of the type -> ID keys of every record
+	TypeIndex = "type~id"
+	// OwnerIndex is the composite key object type of the owner -> type -> ID keys of every record with an Owner
+	OwnerIndex = "owner~type~id"
+)
+
+// Result is the outcome of a backfill
+type Result struct {
+	OwnerKeys int `json:"OwnerKeys"`
+	TypeKeys  int `json:"TypeKeys"`
+}
+
+// PutType records the record stored under id in the partition of its document type, i.e., its ID prefix
+func PutType(ctx contractapi.TransactionContextInterface, id string) error {
+	docType := schema.DocType(id)
+	if docType == "" {
+		return fmt.Errorf("the key %s has no ID prefix", id)
+	}
+	return putKey(ctx, TypeIndex, []string{docType, id})
+}
+
+// PutOwner records the record stored under id in the partition of its owner
+func PutOwner(ctx contractapi.TransactionContextInterface, owner string, id string) error {
+	return putKey(ctx, OwnerIndex, []string{owner, schema.DocType(id), id})
+}
+
+// MoveOwner moves the record stored under id from the partition of its previous owner to the one of its new owner
+func MoveOwner(ctx contractapi.TransactionContextInterface, previousOwner string, newOwner string, id string) error {
+	ownerKey, err := ctx.GetStub().CreateCompositeKey(OwnerIndex, []string{previousOwner, schema.DocType(id), id})
+	if err != nil {
+		return fmt.Errorf("failed to create owner key of %s: %v", id, err)
+	}
+	if err := ctx.GetStub().DelState(ownerKey); err != nil {
+		return fmt.Errorf("failed to delete owner key of %s: %v", id, err)
+	}
+	return PutOwner(ctx, newOwner, id)
+}
+
+// IDsOfType returns the IDs of every record of the given document type, in key order
+func IDsOfType(ctx contractapi.TransactionContextInterface, docType string) ([]string, error) {
+	return getIDs(ctx, TypeIndex, []string{docType})
+}
+
+// IDsOfTypeWithPagination returns the IDs of at most pageSize records of the given document type, in key order, starting
+// from the bookmark, together with the bookmark of the next page, which is empty after the last page. Like every
+// paginated query, it can only be used in read-only transactions
+func IDsOfTypeWithPagination(ctx contractapi.TransactionContextInterface, docType string, pageSize int32, bookmark string) ([]string, string, error) {
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(TypeIndex, []string{docType}, pageSize, bookmark)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get %s keys: %v", TypeIndex, err)
+	}
+	defer resultsIterator.Close()
+
+	ids, err := splitIDs(ctx, resultsIterator)
+	if err != nil {
+		return nil, "", err
+	}
+	return ids, metadata.GetBookmark(), nil
+}
+
+// IDsOfOwner returns the IDs of every record the given organization owns, in key order. An empty docType returns the records of every type
+func IDsOfOwner(ctx contractapi.TransactionContextInterface, owner string, docType string) ([]string, error) {
+	attributes := []string{owner}
+	if docType != "" {
+		attributes = append(attributes, docType)
+	}
+	return getIDs(ctx, OwnerIndex, attributes)
+}
+
+// Backfill records the type key of every record, and the owner key of every record with an Owner. It is meant to be
+// invoked once on ledgers created before the index keys existed, and rewriting a key that exists already is harmless
+func Backfill(ctx contractapi.TransactionContextInterface) (*Result, error) {
+	// Composite keys are left out of range queries, so only records are read
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state by range: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	result := &Result{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through results: %v", err)
+		}
+		// Records without an ID prefix, e.g., the role policy, are not indexed
+		if schema.DocType(queryResponse.Key) == "" {
+			continue
+		}
+		if err := PutType(ctx, queryResponse.Key); err != nil {
+			return nil, err
+		}
+		result.TypeKeys++
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		owner, _ := record["Owner"].(string)
+		if owner == "" {
+			continue
+		}
+		if err := PutOwner(ctx, owner, queryResponse.Key); err != nil {
+			return nil, err
+		}
+		result.OwnerKeys++
+	}
+	return result, nil
+}
+
+// putKey saves an index key
+func putKey(ctx contractapi.TransactionContextInterface, objectType string, attributes []string) error {
+	indexKey, err := ctx.GetStub().CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return fmt.Errorf("failed to create %s key of %s: %v", objectType, attributes[len(attributes)-1], err)
+	}
+	// The value is irrelevant, but an empty value would delete the key
+	if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("failed to save %s key of %s: %v", objectType, attributes[len(attributes)-1], err)
+	}
+	return nil
+}
+
+// getIDs returns the last attribute, i.e., the record ID, of every index key that starts with the given attributes
+func getIDs(ctx contractapi.TransactionContextInterface, objectType string, attributes []string) ([]string, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s keys: %v", objectType, err)
+	}
+	defer resultsIterator.Close()
+
+	return splitIDs(ctx, resultsIterator)
+}
+
+// splitIDs returns the last attribute, i.e., the record ID, of every index key returned by the iterator
+func splitIDs(ctx contractapi.TransactionContextInterface, resultsIterator shim.StateQueryIteratorInterface) ([]string, error) {
+	ids := []string{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through index keys: %v", err)
+		}
+		_, keyAttributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split index key: %v", err)
+		}
+		ids = append(ids, keyAttributes[len(keyAttributes)-1])
+	}
+	return ids, nil
+}
@@ -0,0 +1,101 @@
+package index
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+func TestIDsOfType(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	err := ctx.Submit("Org1MSP", func() error {
+		for _, id := range []string{"lot_2", "lot_1", "cottonbale_lot_1", "billoflading_1"} {
+			if err := PutType(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The partition of lots holds only keys prefixed by "lot_", not every key containing it
+	ids, err := IDsOfType(ctx, "lot_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[lot_1 lot_2]" {
+		t.Errorf("expected [lot_1 lot_2], got %v", ids)
+	}
+	if ids, err := IDsOfType(ctx, "carton_"); err != nil || len(ids) != 0 {
+		t.Errorf("expected no cartons, got %v, error %v", ids, err)
+	}
+	if err := PutType(ctx, "rolepolicy"); err == nil {
+		t.Errorf("expected the key without an ID prefix to be refused")
+	}
+}
+
+func TestMoveOwner(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	if err := ctx.Submit("Org6MSP", func() error { return PutOwner(ctx, "Org6MSP", "carton_1") }); err != nil {
+		t.Fatal(err)
+	}
+	if err := ctx.Submit("Org6MSP", func() error { return MoveOwner(ctx, "Org6MSP", "Org1MSP", "carton_1") }); err != nil {
+		t.Fatal(err)
+	}
+	if ids, err := IDsOfOwner(ctx, "Org6MSP", ""); err != nil || len(ids) != 0 {
+		t.Errorf("expected the previous owner to own nothing, got %v, error %v", ids, err)
+	}
+	if ids, err := IDsOfOwner(ctx, "Org1MSP", "carton_"); err != nil || fmt.Sprint(ids) != "[carton_1]" {
+		t.Errorf("expected the new owner to own carton_1, got %v, error %v", ids, err)
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	ctx.Stub().Seed("cottonbale_1", []byte(`{"ID":"cottonbale_1"}`))
+	ctx.Stub().Seed("lot_1", []byte(`{"ID":"lot_1","Owner":"Org4MSP"}`))
+	ctx.Stub().Seed("rolepolicy", []byte(`{"Roles":{}}`))
+	var result *Result
+	if err := ctx.Submit("Org1MSP", func() (err error) {
+		result, err = Backfill(ctx)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if result.TypeKeys != 2 || result.OwnerKeys != 1 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if ids, err := IDsOfType(ctx, "cottonbale_"); err != nil || fmt.Sprint(ids) != "[cottonbale_1]" {
+		t.Errorf("expected cottonbale_1 to be indexed, got %v, error %v", ids, err)
+	}
+	if ids, err := IDsOfOwner(ctx, "Org4MSP", "lot_"); err != nil || fmt.Sprint(ids) != "[lot_1]" {
+		t.Errorf("expected lot_1 to be indexed under its owner, got %v, error %v", ids, err)
+	}
+}
+
+func TestIDsOfTypeWithPagination(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	err := ctx.Submit("Org1MSP", func() error {
+		for _, id := range []string{"lot_1", "lot_2", "lot_3", "cottonbale_1"} {
+			if err := PutType(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, bookmark, err := IDsOfTypeWithPagination(ctx, "lot_", 2, "")
+	if err != nil || fmt.Sprint(ids) != "[lot_1 lot_2]" || bookmark == "" {
+		t.Errorf("unexpected first page %v with bookmark %q, error %v", ids, bookmark, err)
+	}
+	ids, bookmark, err = IDsOfTypeWithPagination(ctx, "lot_", 2, bookmark)
+	if err != nil || fmt.Sprint(ids) != "[lot_3]" || bookmark != "" {
+		t.Errorf("unexpected last page %v with bookmark %q, error %v", ids, bookmark, err)
+	}
+}
@@ -0,0 +1,153 @@
+// Package policy defines the role policy that decides which organizations may perform each access-controlled operation
+// of the admin-channel and production-channel contracts. Each contract keeps its policy in its own world state and falls
+// back to a compiled-in default until the policy is first updated, so that onboarding an organization, e.g., a second
+// textile mill, is a transaction rather than a chaincode upgrade.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// AssetType is the asset type under which the permission to update the role policy itself is granted
+const AssetType = "rolepolicy"
+
+// objectType is the composite key object type under which the role policy is stored. Composite keys are skipped by
+// GetStateByRange, so the policy never shows up among the assets
+const objectType = "rolepolicy"
+
+// Names of the roles of the default policies
+const (
+	Agent               = "agent"
+	Auditor             = "auditor"
+	Carrier             = "carrier"
+	FullPackageSupplier = "full-package-supplier"
+	RawMaterialSupplier = "raw-material-supplier"
+	Retailer            = "retailer"
+	TextileMill         = "textile-mill"
+)
+
+// Names of the operations a role can be allowed to perform on an asset type
+const (
+	Approve     = "approve"     // approve a plan or factory on behalf of the role
+	Arrive      = "arrive"      // record the arrival of a container at its destination port
+	Backfill    = "backfill"    // backfill index keys of existing assets, with asset type lot_ for lot membership and AssetType for the type and owner indexes
+	Create      = "create"      // create an asset of the type
+	CreateLot   = "createLot"   // create a lot holding assets of the type
+	Deliver     = "deliver"     // record the delivery of a container to its consignee
+	Depart      = "depart"      // record the departure of a container from its origin port
+	Migrate     = "migrate"     // migrate the records of every asset type to a newer schema version, with asset type AssetType
+	Own         = "own"         // be the owner of an asset of the type, e.g., a carton
+	OwnLot      = "ownLot"      // be the owner of a lot holding assets of the type
+	Recall      = "recall"      // initiate and close recalls
+	SetStatus   = "setStatus"   // set the status of an asset of the type
+	TransferLot = "transferLot" // transfer the ownership of a lot holding assets of the type
+	Update      = "update"      // update the role policy and, on the production channel, the expected yields, with asset type AssetType
+)
+
+// Names of the unit systems an organization can report weights and dimensions in
+const (
+	Imperial = "imperial" // pounds, yards and inches, the units of the ledger
+	Metric   = "metric"   // kilograms, metres and centimetres
+)
+
+// Role is a set of organizations together with the operations they may perform on each asset type
+type Role struct {
+	MSPIDs     []string            `json:"MSPIDs"`
+	Operations map[string][]string `json:"Operations"` // asset type, e.g., "cottonbale_", to the operations allowed on it
+}
+
+// RolePolicy maps each role, e.g., "textile-mill", to its member organizations and allowed operations, and each organization to its registered site and unit system
+type RolePolicy struct {
+	Roles       map[string]*Role  `json:"Roles"`
+	Sites       map[string]string `json:"Sites,omitempty"`       // MSP ID to the registered site where the organization receives goods, e.g., "Ashulia, Bangladesh"
+	UnitSystems map[string]string `json:"UnitSystems,omitempty"` // MSP ID to the unit system the organization reports in, Imperial if absent
+	UpdatedAt   time.Time         `json:"UpdatedAt"`
+	UpdatedBy   string            `json:"UpdatedBy"`
+	Version     int               `json:"Version"` // 0 for a default policy that was never stored
+}
+
+// Read returns the role policy stored in the world state, or defaultPolicy if none was stored yet
+func Read(ctx contractapi.TransactionContextInterface, defaultPolicy *RolePolicy) (*RolePolicy, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create role policy key: %v", err)
+	}
+	policyJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read role policy: %v", err)
+	}
+	if policyJSON == nil {
+		return defaultPolicy, nil
+	}
+	var rolePolicy RolePolicy
+	err = json.Unmarshal(policyJSON, &rolePolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal role policy: %v", err)
+	}
+	return &rolePolicy, nil
+}
+
+// Write stores the role policy in the world state
+func Write(ctx contractapi.TransactionContextInterface, rolePolicy *RolePolicy) error {
+	key, err := ctx.GetStub().CreateCompositeKey(objectType, []string{})
+	if err != nil {
+		return fmt.Errorf("failed to create role policy key: %v", err)
+	}
+	policyJSON, err := json.Marshal(rolePolicy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal role policy: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, policyJSON); err != nil {
+		return fmt.Errorf("failed to save role policy: %v", err)
+	}
+	return nil
+}
+
+// MSPIDsAllowedTo returns the sorted MSP IDs of the organizations holding a role that allows the operation on the asset type
+func (p *RolePolicy) MSPIDsAllowedTo(operation string, assetType string) []string {
+	allowed := map[string]bool{}
+	for _, role := range p.Roles {
+		if role == nil || !contains(role.Operations[assetType], operation) {
+			continue
+		}
+		for _, mspID := range role.MSPIDs {
+			allowed[mspID] = true
+		}
+	}
+	mspIDs := make([]string, 0, len(allowed))
+	for mspID := range allowed {
+		mspIDs = append(mspIDs, mspID)
+	}
+	sort.Strings(mspIDs)
+	return mspIDs
+}
+
+// HasRole reports whether the organization is a member of the role
+func (p *RolePolicy) HasRole(mspID string, role string) bool {
+	r, ok := p.Roles[role]
+	return ok && r != nil && contains(r.MSPIDs, mspID)
+}
+
+// RoleNames returns the sorted names of the roles of the policy
+func (p *RolePolicy) RoleNames() []string {
+	names := make([]string, 0, len(p.Roles))
+	for name := range p.Roles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+func newTestPolicy() *RolePolicy {
+	return &RolePolicy{
+		Roles: map[string]*Role{
+			Retailer: {
+				MSPIDs:     []string{"Org1MSP"},
+				Operations: map[string][]string{"cottonbale_": {CreateLot, OwnLot}, AssetType: {Update}},
+			},
+			RawMaterialSupplier: {
+				MSPIDs:     []string{"Org4MSP", "Org7MSP"},
+				Operations: map[string][]string{"cottonbale_": {Create, CreateLot}},
+			},
+			TextileMill: {
+				MSPIDs:     []string{"Org5MSP"},
+				Operations: map[string][]string{},
+			},
+		},
+	}
+}
+
+func TestMSPIDsAllowedTo(t *testing.T) {
+	rolePolicy := newTestPolicy()
+	tests := []struct {
+		operation string
+		assetType string
+		want      string
+	}{
+		{Create, "cottonbale_", "[Org4MSP Org7MSP]"},
+		{CreateLot, "cottonbale_", "[Org1MSP Org4MSP Org7MSP]"},
+		{OwnLot, "cottonbale_", "[Org1MSP]"},
+		{Create, "cottonyarn_", "[]"},
+		{Update, AssetType, "[Org1MSP]"},
+	}
+	for _, test := range tests {
+		t.Run(test.operation+" "+test.assetType, func(t *testing.T) {
+			got := rolePolicy.MSPIDsAllowedTo(test.operation, test.assetType)
+			if got == nil || fmt.Sprint(got) != test.want {
+				t.Errorf("expected %s, got %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	rolePolicy := newTestPolicy()
+	if !rolePolicy.HasRole("Org7MSP", RawMaterialSupplier) || rolePolicy.HasRole("Org7MSP", Retailer) || rolePolicy.HasRole("Org1MSP", Auditor) {
+		t.Error("unexpected role membership")
+	}
+	if got := fmt.Sprint(rolePolicy.RoleNames()); got != "[raw-material-supplier retailer textile-mill]" {
+		t.Errorf("unexpected role names %s", got)
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	defaultPolicy := newTestPolicy()
+
+	rolePolicy, err := Read(ctx, defaultPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolePolicy != defaultPolicy {
+		t.Fatal("expected the default policy before any policy is stored")
+	}
+
+	err = ctx.Submit("Org1MSP", func() error {
+		return Write(ctx, &RolePolicy{Roles: map[string]*Role{Auditor: {MSPIDs: []string{"Org3MSP"}}}, UpdatedBy: "Org1MSP", Version: 1})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rolePolicy, err = Read(ctx, defaultPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rolePolicy.Version != 1 || !rolePolicy.HasRole("Org3MSP", Auditor) || rolePolicy.HasRole("Org1MSP", Retailer) {
+		t.Errorf("expected the stored policy, got %+v", rolePolicy)
+	}
+
+	// The policy is stored under a composite key, so it never shows up among the assets
+	for _, key := range ctx.Stub().Keys() {
+		if key[0] != 0x00 {
+			t.Errorf("expected only composite keys, got %q", key)
+		}
+	}
+}
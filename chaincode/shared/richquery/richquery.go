@@ -0,0 +1,48 @@
+// Package richquery runs the CouchDB rich queries of the admin-channel and production-channel contracts. Rich queries
+// need a CouchDB state database, and the indexes packaged with each contract under META-INF/statedb/couchdb/indexes
+// keep the common filters from scanning every record.
+package richquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/schema"
+)
+
+// compositeKeyNamespace is the first character of every composite key
+const compositeKeyNamespace = "\x00"
+
+// Records returns a page of at most pageSize records matching the selector, starting from the bookmark, together with
+// the bookmark of the next page, which is empty after the last page. CouchDB matches index keys and records without an
+// ID prefix, e.g., the role policy, as well, so they are left out after the page is read and a page may hold fewer
+// records than pageSize while more remain. Like every paginated query, it can only be used in read-only transactions
+func Records(ctx contractapi.TransactionContextInterface, selectorJSON string, pageSize int32, bookmark string) ([]*queryresult.KV, string, error) {
+	queryJSON, err := json.Marshal(struct {
+		Selector json.RawMessage `json:"selector"`
+	}{Selector: json.RawMessage(selectorJSON)})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build query: %v", err)
+	}
+	resultsIterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(string(queryJSON), pageSize, bookmark)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to run query %s: %v", queryJSON, err)
+	}
+	defer resultsIterator.Close()
+
+	records := []*queryresult.KV{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to iterate through query results: %v", err)
+		}
+		if strings.HasPrefix(queryResponse.Key, compositeKeyNamespace) || schema.DocType(queryResponse.Key) == "" {
+			continue
+		}
+		records = append(records, queryResponse)
+	}
+	return records, metadata.GetBookmark(), nil
+}
@@ -0,0 +1,23 @@
+package richquery
+
+import (
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+func TestRecords(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	ctx.Stub().Seed("lot_1", []byte(`{"ID":"lot_1","Reason":"wrong weight"}`))
+	ctx.Stub().Seed("\x00archived~asset\x00lot_1\x00", []byte(`{"AssetID":"lot_1","Reason":"wrong weight"}`))
+	ctx.Stub().Seed("rolepolicy", []byte(`{"Reason":"wrong weight"}`))
+
+	// Only the record is returned, not the tombstone or the role policy that match as well
+	records, bookmark, err := Records(ctx, `{"Reason":"wrong weight"}`, 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Key != "lot_1" || bookmark != "" {
+		t.Errorf("expected lot_1 alone, got %v with bookmark %q", records, bookmark)
+	}
+}
@@ -0,0 +1,145 @@
+// Package schema versions the records stored by the admin-channel and production-channel contracts. Every record
+// carries the DocType and SchemaVersion it was written under, and each contract keeps the chain of Migrations that
+// upgrades older records one version at a time, so that the model can evolve without recreating the network.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Step upgrades a record, changed in place, from one schema version to the next
+type Step func(key string, record map[string]interface{}) error
+
+// Migrations is the chain of steps of a contract. The step at index i upgrades records from version i to version i+1,
+// where version 0 is every record written before versions were recorded
+type Migrations []Step
+
+// Result is the outcome of one page of a migration
+type Result struct {
+	FromVersion  int      `json:"FromVersion"`
+	HasMore      bool     `json:"HasMore"` // records at FromVersion remain, so the migration must be invoked again
+	MigratedIDs  []string `json:"MigratedIDs"`
+	NextStartKey string   `json:"NextStartKey"` // key the next page starts from, empty once HasMore is false
+	ToVersion    int      `json:"ToVersion"`
+}
+
+// PendingRecord is a record written under an older schema version than the current one
+type PendingRecord struct {
+	DocType       string `json:"DocType"`
+	ID            string `json:"ID"`
+	SchemaVersion int    `json:"SchemaVersion"`
+}
+
+// CurrentVersion returns the schema version new records are written under
+func (m Migrations) CurrentVersion() int {
+	return len(m)
+}
+
+// DocType returns the document type of the record stored under key, i.e., its ID prefix, e.g., "cottonbale_"
+func DocType(key string) string {
+	i := strings.Index(key, "_")
+	if i < 0 {
+		return ""
+	}
+	return key[:i+1]
+}
+
+// Version returns the schema version of a record, which is 0 for records written before versions were recorded
+func Version(record map[string]interface{}) int {
+	version, ok := record["SchemaVersion"].(float64)
+	if !ok {
+		return 0
+	}
+	return int(version)
+}
+
+// AddDocType is the first step of every contract. It records the document type of records written before DocType existed
+func AddDocType(key string, record map[string]interface{}) error {
+	docType := DocType(key)
+	if docType == "" {
+		return fmt.Errorf("the key %s has no ID prefix", key)
+	}
+	record["DocType"] = docType
+	return nil
+}
+
+// Migrate upgrades at most pageSize records from fromVersion to toVersion, in key order, starting from startKey. Each
+// page returns the NextStartKey of the following one, so that no page reads the records already migrated
+func (m Migrations) Migrate(ctx contractapi.TransactionContextInterface, fromVersion int, toVersion int, startKey string, pageSize int) (*Result, error) {
+	// Range queries with pagination are limited to read-only transactions, so the page is cut from a plain range query
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state by range: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	result := &Result{FromVersion: fromVersion, MigratedIDs: []string{}, ToVersion: toVersion}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through results: %v", err)
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		if Version(record) != fromVersion {
+			continue
+		}
+		if len(result.MigratedIDs) == pageSize {
+			result.HasMore = true
+			result.NextStartKey = queryResponse.Key
+			break
+		}
+		for version := fromVersion; version < toVersion; version++ {
+			if err := m[version](queryResponse.Key, record); err != nil {
+				return nil, fmt.Errorf("failed to migrate record %s to schema version %d: %v", queryResponse.Key, version+1, err)
+			}
+			record["SchemaVersion"] = version + 1
+		}
+		recordJSON, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal record %s: %v", queryResponse.Key, err)
+		}
+		if err := ctx.GetStub().PutState(queryResponse.Key, recordJSON); err != nil {
+			return nil, fmt.Errorf("failed to save record %s: %v", queryResponse.Key, err)
+		}
+		result.MigratedIDs = append(result.MigratedIDs, queryResponse.Key)
+	}
+	return result, nil
+}
+
+// Pending lists the records written under an older schema version than the current one, in key order
+func (m Migrations) Pending(ctx contractapi.TransactionContextInterface) ([]*PendingRecord, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state by range: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	pending := []*PendingRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate through results: %v", err)
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal(queryResponse.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", queryResponse.Key, err)
+		}
+		version := Version(record)
+		if version >= m.CurrentVersion() {
+			continue
+		}
+		docType, ok := record["DocType"].(string)
+		if !ok {
+			docType = DocType(queryResponse.Key)
+		}
+		pending = append(pending, &PendingRecord{DocType: docType, ID: queryResponse.Key, SchemaVersion: version})
+	}
+	return pending, nil
+}
@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+)
+
+// testMigrations upgrades records to version 1 by adding their DocType and to version 2 by renaming Weight to TotalWeight
+var testMigrations = Migrations{
+	AddDocType,
+	func(key string, record map[string]interface{}) error {
+		record["TotalWeight"] = record["Weight"]
+		delete(record, "Weight")
+		return nil
+	},
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	for _, id := range []string{"cottonbale_1", "cottonbale_2", "lot_1"} {
+		ctx.Stub().Seed(id, []byte(fmt.Sprintf(`{"ID":%q,"Weight":480}`, id)))
+	}
+	ctx.Stub().Seed("cottonbale_3", []byte(`{"DocType":"cottonbale_","ID":"cottonbale_3","SchemaVersion":2,"TotalWeight":500}`))
+	migrate := func(startKey string, pageSize int) *Result {
+		t.Helper()
+		var result *Result
+		if err := ctx.Submit("Org1MSP", func() (err error) {
+			result, err = testMigrations.Migrate(ctx, 0, 2, startKey, pageSize)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// The first page stops before the third record at version 0, and the second page resumes from it
+	first := migrate("", 2)
+	if fmt.Sprint(first.MigratedIDs) != "[cottonbale_1 cottonbale_2]" || !first.HasMore || first.NextStartKey != "lot_1" {
+		t.Errorf("unexpected first page %+v", first)
+	}
+	if result := migrate(first.NextStartKey, 2); fmt.Sprint(result.MigratedIDs) != "[lot_1]" || result.HasMore || result.NextStartKey != "" {
+		t.Errorf("unexpected second page %+v", result)
+	}
+	if result := migrate("", 2); len(result.MigratedIDs) != 0 || result.HasMore {
+		t.Errorf("expected nothing left to migrate, got %+v", result)
+	}
+
+	recordJSON, err := ctx.Stub().GetState("lot_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(recordJSON, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["DocType"] != "lot_" || Version(record) != 2 || record["TotalWeight"] != 480.0 || record["Weight"] != nil {
+		t.Errorf("unexpected migrated record %v", record)
+	}
+}
+
+func TestPending(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org1MSP")
+	ctx.Stub().Seed("cottonbale_1", []byte(`{"ID":"cottonbale_1"}`))
+	ctx.Stub().Seed("cottonbale_2", []byte(`{"DocType":"cottonbale_","ID":"cottonbale_2","SchemaVersion":1}`))
+	ctx.Stub().Seed("cottonbale_3", []byte(`{"DocType":"cottonbale_","ID":"cottonbale_3","SchemaVersion":2}`))
+	var pending []*PendingRecord
+	err := ctx.Evaluate("Org3MSP", func() (err error) {
+		pending, err = testMigrations.Pending(ctx)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, record := range pending {
+		got = append(got, fmt.Sprintf("%s %s %d", record.ID, record.DocType, record.SchemaVersion))
+	}
+	if want := "cottonbale_1 cottonbale_ 0, cottonbale_2 cottonbale_ 1"; strings.Join(got, ", ") != want {
+		t.Errorf("expected %s, got %s", want, strings.Join(got, ", "))
+	}
+}
+
+func TestAddDocType(t *testing.T) {
+	record := map[string]interface{}{}
+	if err := AddDocType("order_1", record); err != nil || record["DocType"] != "order_" {
+		t.Errorf("unexpected DocType %v, error %v", record["DocType"], err)
+	}
+	if err := AddDocType("rolepolicy", record); err == nil || !strings.Contains(err.Error(), "has no ID prefix") {
+		t.Errorf("expected the key without an ID prefix to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package spec
+
+import "sort"
+
+// FunctionSpecification lists the specifications a contract function enforces, in the order it checks them
+type FunctionSpecification struct {
+	Function       string   `json:"Function"`
+	Specifications []string `json:"Specifications"`
+}
+
+// Registry records which specifications each contract function enforces
+type Registry struct {
+	functions map[string][]string
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{functions: map[string][]string{}}
+}
+
+// Register records the specifications enforced by a function, replacing any earlier record. It returns the registry so that calls can be chained
+func (r *Registry) Register(function string, specifications ...string) *Registry {
+	if specifications == nil {
+		specifications = []string{}
+	}
+	r.functions[function] = specifications
+	return r
+}
+
+// Functions returns the specifications of every registered function, sorted by function name
+func (r *Registry) Functions() []*FunctionSpecification {
+	names := make([]string, 0, len(r.functions))
+	for name := range r.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	functions := make([]*FunctionSpecification, 0, len(names))
+	for _, name := range names {
+		functions = append(functions, &FunctionSpecification{Function: name, Specifications: r.functions[name]})
+	}
+	return functions
+}
@@ -0,0 +1,212 @@
+// Package spec holds the specifications enforced by both the admin-channel and the production-channel contracts.
+// The contracts bind each of them to a SPEC_ name, e.g., SPEC_IsNewAsset, which is the name used in doc comments and
+// in the specification registry.
+package spec
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// IsNewAsset ensures that the asset does not already exist
+func IsNewAsset(ctx contractapi.TransactionContextInterface, id string) error {
+	exists, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if exists != nil {
+		return fmt.Errorf("the asset %s already exists", id)
+	}
+	return nil
+}
+
+// AssetExists ensures that the asset exists
+func AssetExists(ctx contractapi.TransactionContextInterface, assetID string) error {
+	assetJSON, err := ctx.GetStub().GetState(assetID)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if assetJSON == nil {
+		return fmt.Errorf("the asset %s does not exist", assetID)
+	}
+	return nil
+}
+
+// IDPrefix ensures that the id starts with the correct prefix
+func IDPrefix(id string, prefix string) error {
+	if !strings.HasPrefix(id, prefix) {
+		return fmt.Errorf("the id '%s' must start with '%s'", id, prefix)
+	}
+	return nil
+}
+
+// IsValidFlag ensures that (the flagReason is provided if isFlagged is true) and (the flagReason is empty or N/A if isFlagged is false)
+func IsValidFlag(isFlagged bool, flagReason string) error {
+	if isFlagged && (len(flagReason) == 0 || flagReason == "N/A") {
+		return fmt.Errorf("flagReason must be provided if isFlagged is true")
+	} else if !isFlagged && (len(flagReason) != 0 && flagReason != "N/A") {
+		return fmt.Errorf("flagReason must be empty or N/A if isFlagged is false")
+	}
+	return nil
+}
+
+// Chronology ensures that the provided dates are in chronological order
+func Chronology(dates ...time.Time) error {
+	for i := 1; i < len(dates); i++ {
+		if !dates[i].After(dates[i-1]) {
+			return fmt.Errorf("date %v is not after date %v", dates[i], dates[i-1])
+		}
+	}
+	return nil
+}
+
+// IsInvokedByAllowedOrg checks if the function is invoked by one of the allowed OrgMSPIDs
+func IsInvokedByAllowedOrg(ctx contractapi.TransactionContextInterface, allowedOrgMSPIDs ...string) error {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+
+	for _, orgMSPID := range allowedOrgMSPIDs {
+		if clientMSPID == orgMSPID {
+			return nil
+		}
+	}
+	return fmt.Errorf("the function is not invoked by an allowed organization. Invoked by: %s. Allowed organizations: %v", clientMSPID, allowedOrgMSPIDs)
+}
+
+// IsInvokedByAllowedRole checks if the function is invoked by an organization holding a role that the role policy allows to perform the operation on the asset type
+func IsInvokedByAllowedRole(ctx contractapi.TransactionContextInterface, rolePolicy *policy.RolePolicy, operation string, assetType string) error {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to get client MSPID: %v", err)
+	}
+
+	allowedOrgMSPIDs := rolePolicy.MSPIDsAllowedTo(operation, assetType)
+	for _, orgMSPID := range allowedOrgMSPIDs {
+		if clientMSPID == orgMSPID {
+			return nil
+		}
+	}
+	return fmt.Errorf("the function is not invoked by an allowed organization. Invoked by: %s. Organizations allowed to %s %s: %v", clientMSPID, operation, assetType, allowedOrgMSPIDs)
+}
+
+// IsValidRolePolicy ensures that every role, member organization, asset type, operation and site of the role policy is named, and that at least one organization remains allowed to update the policy
+func IsValidRolePolicy(rolePolicy *policy.RolePolicy) error {
+	if len(rolePolicy.Roles) == 0 {
+		return fmt.Errorf("the role policy must define at least one role")
+	}
+	for _, name := range rolePolicy.RoleNames() {
+		role := rolePolicy.Roles[name]
+		if len(name) == 0 || role == nil {
+			return fmt.Errorf("the role policy contains an unnamed or empty role")
+		}
+		for _, mspID := range role.MSPIDs {
+			if len(mspID) == 0 {
+				return fmt.Errorf("role %s contains an empty MSP ID", name)
+			}
+		}
+		// Visit the asset types in sorted order so that every peer reports the same error
+		assetTypes := make([]string, 0, len(role.Operations))
+		for assetType := range role.Operations {
+			assetTypes = append(assetTypes, assetType)
+		}
+		sort.Strings(assetTypes)
+		for _, assetType := range assetTypes {
+			if len(assetType) == 0 {
+				return fmt.Errorf("role %s grants operations on an empty asset type", name)
+			}
+			for _, operation := range role.Operations[assetType] {
+				if len(operation) == 0 {
+					return fmt.Errorf("role %s grants an empty operation on %s", name, assetType)
+				}
+			}
+		}
+	}
+	// Visit the sites in sorted order too
+	siteMSPIDs := make([]string, 0, len(rolePolicy.Sites))
+	for mspID := range rolePolicy.Sites {
+		siteMSPIDs = append(siteMSPIDs, mspID)
+	}
+	sort.Strings(siteMSPIDs)
+	for _, mspID := range siteMSPIDs {
+		if len(mspID) == 0 {
+			return fmt.Errorf("the role policy registers a site for an empty MSP ID")
+		}
+		if len(rolePolicy.Sites[mspID]) == 0 {
+			return fmt.Errorf("organization %s has an empty registered site", mspID)
+		}
+	}
+	// And the unit systems
+	unitSystemMSPIDs := make([]string, 0, len(rolePolicy.UnitSystems))
+	for mspID := range rolePolicy.UnitSystems {
+		unitSystemMSPIDs = append(unitSystemMSPIDs, mspID)
+	}
+	sort.Strings(unitSystemMSPIDs)
+	for _, mspID := range unitSystemMSPIDs {
+		if len(mspID) == 0 {
+			return fmt.Errorf("the role policy registers a unit system for an empty MSP ID")
+		}
+		if unitSystem := rolePolicy.UnitSystems[mspID]; unitSystem != policy.Imperial && unitSystem != policy.Metric {
+			return fmt.Errorf("organization %s reports in unknown unit system %s", mspID, unitSystem)
+		}
+	}
+	if len(rolePolicy.MSPIDsAllowedTo(policy.Update, policy.AssetType)) == 0 {
+		return fmt.Errorf("the role policy must allow at least one organization to %s %s", policy.Update, policy.AssetType)
+	}
+	return nil
+}
+
+// IsValidMigration ensures that the migration goes from an older to a newer schema version that the contract knows of, one page of a positive size at a time
+func IsValidMigration(fromVersion int, toVersion int, currentVersion int, pageSize int) error {
+	if fromVersion < 0 || toVersion <= fromVersion {
+		return fmt.Errorf("the migration must go from an older to a newer schema version, got %d to %d", fromVersion, toVersion)
+	}
+	if toVersion > currentVersion {
+		return fmt.Errorf("schema version %d is newer than the current schema version %d", toVersion, currentVersion)
+	}
+	if pageSize <= 0 {
+		return fmt.Errorf("the page size must be positive, got %d", pageSize)
+	}
+	return nil
+}
+
+// MaxPageSize is the largest page a paginated query returns, so that a response stays well within the peer's limits
+const MaxPageSize = 1000
+
+// IsValidPageSize ensures that a paginated query asks for at least one and at most MaxPageSize records
+func IsValidPageSize(pageSize int32) error {
+	if pageSize <= 0 || pageSize > MaxPageSize {
+		return fmt.Errorf("the page size must be between 1 and %d, got %d", MaxPageSize, pageSize)
+	}
+	return nil
+}
+
+// MaxBatchSize is the largest number of items a batch transaction creates, so that its write set stays well within the peer's limits
+const MaxBatchSize = 500
+
+// IsValidBatchSize ensures that a batch transaction holds at least one and at most MaxBatchSize items
+func IsValidBatchSize(batchSize int) error {
+	if batchSize <= 0 || batchSize > MaxBatchSize {
+		return fmt.Errorf("the batch must hold between 1 and %d items, got %d", MaxBatchSize, batchSize)
+	}
+	return nil
+}
+
+// IsValidSelector ensures that the selector of a rich query is a JSON object with at least one condition, e.g., {"DocType": "lot_", "IsFlagged": true}
+func IsValidSelector(selectorJSON string) error {
+	var selector map[string]interface{}
+	if err := json.Unmarshal([]byte(selectorJSON), &selector); err != nil {
+		return fmt.Errorf("the selector must be a JSON object: %v", err)
+	}
+	if len(selector) == 0 {
+		return fmt.Errorf("the selector must have at least one condition")
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package spec
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/chaincodetest"
+	"github.com/ritwiktakkar/enhancing-traceability-in-bdccs-poc/chaincode/shared/policy"
+)
+
+// checkErr fails the test unless err matches wantErr, where an empty wantErr means no error
+func checkErr(t *testing.T, err error, wantErr string) {
+	t.Helper()
+	if wantErr == "" && err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if wantErr != "" && (err == nil || !strings.Contains(err.Error(), wantErr)) {
+		t.Fatalf("expected error containing %q, got %v", wantErr, err)
+	}
+}
+
+func TestIsNewAsset(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("production", "Org4MSP")
+	ctx.Stub().Seed("cottonbale_1", []byte(`{"ID":"cottonbale_1"}`))
+	tests := []struct {
+		id      string
+		wantErr string
+	}{
+		{"cottonbale_1", "the asset cottonbale_1 already exists"},
+		{"cottonbale_2", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			checkErr(t, IsNewAsset(ctx, test.id), test.wantErr)
+		})
+	}
+}
+
+func TestAssetExists(t *testing.T) {
+	ctx := chaincodetest.NewTransactionContext("admin", "Org1MSP")
+	ctx.Stub().Seed("order_1", []byte(`{"ID":"order_1"}`))
+	tests := []struct {
+		id      string
+		wantErr string
+	}{
+		{"order_1", ""},
+		{"order_2", "the asset order_2 does not exist"},
+	}
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			checkErr(t, AssetExists(ctx, test.id), test.wantErr)
+		})
+	}
+}
+
+func TestIDPrefix(t *testing.T) {
+	tests := []struct {
+		id      string
+		prefix  string
+		wantErr string
+	}{
+		{"order_1", "order_", ""},
+		{"plan_1", "order_", "must start with 'order_'"},
+		{"CottonBale_1", "cottonbale_", "must start with 'cottonbale_'"},
+		{"", "factory_", "must start with 'factory_'"},
+	}
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			checkErr(t, IDPrefix(test.id, test.prefix), test.wantErr)
+		})
+	}
+}
+
+func TestIsValidFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		isFlagged  bool
+		flagReason string
+		wantErr    string
+	}{
+		{"flagged with reason", true, "contaminated", ""},
+		{"flagged without reason", true, "", "flagReason must be provided"},
+		{"flagged with N/A", true, "N/A", "flagReason must be provided"},
+		{"not flagged without reason", false, "", ""},
+		{"not flagged with N/A", false, "N/A", ""},
+		{"not flagged with reason", false, "contaminated", "flagReason must be empty or N/A"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, IsValidFlag(test.isFlagged, test.flagReason), test.wantErr)
+		})
+	}
+}
+
+func TestChronology(t *testing.T) {
+	t1 := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	t3 := t2.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		dates   []time.Time
+		wantErr string
+	}{
+		{"no dates", nil, ""},
+		{"single date", []time.Time{t1}, ""},
+		{"in order", []time.Time{t1, t2, t3}, ""},
+		{"equal dates", []time.Time{t1, t1}, "is not after"},
+		{"out of order", []time.Time{t1, t3, t2}, "is not after"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, Chronology(test.dates...), test.wantErr)
+		})
+	}
+}
+
+func TestIsInvokedByAllowedOrg(t *testing.T) {
+	tests := []struct {
+		name    string
+		mspID   string
+		allowed []string
+		wantErr string
+	}{
+		{"retailer allowed", "Org1MSP", []string{"Org1MSP", "Org3MSP"}, ""},
+		{"full-package supplier allowed", "Org6MSP", []string{"Org1MSP", "Org2MSP", "Org6MSP"}, ""},
+		{"full-package supplier not allowed", "Org6MSP", []string{"Org1MSP", "Org3MSP"}, "Invoked by: Org6MSP"},
+		{"retailer not allowed", "Org1MSP", []string{"Org6MSP"}, "Invoked by: Org1MSP"},
+		{"nobody allowed", "Org1MSP", nil, "not invoked by an allowed organization"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := chaincodetest.NewTransactionContext("admin", test.mspID)
+			checkErr(t, IsInvokedByAllowedOrg(ctx, test.allowed...), test.wantErr)
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry().
+		Register("SetNotes").
+		Register("CreateOrder", "SPEC_IDPrefix", "SPEC_IsNewAsset").
+		Register("AssetExists")
+
+	functions := registry.Functions()
+	got := []string{}
+	for _, function := range functions {
+		if function.Specifications == nil {
+			t.Errorf("%s: specifications must never be nil", function.Function)
+		}
+		got = append(got, fmt.Sprintf("%s%v", function.Function, function.Specifications))
+	}
+	if want := "[AssetExists[] CreateOrder[SPEC_IDPrefix SPEC_IsNewAsset] SetNotes[]]"; fmt.Sprint(got) != want {
+		t.Errorf("expected %s, got %v", want, got)
+	}
+}
+
+func TestIsInvokedByAllowedRole(t *testing.T) {
+	rolePolicy := &policy.RolePolicy{
+		Roles: map[string]*policy.Role{
+			policy.TextileMill: {
+				MSPIDs:     []string{"Org5MSP", "Org7MSP"},
+				Operations: map[string][]string{"unfinishedfabric_": {policy.Create}},
+			},
+		},
+	}
+	tests := []struct {
+		name      string
+		mspID     string
+		operation string
+		assetType string
+		wantErr   string
+	}{
+		{"member", "Org5MSP", policy.Create, "unfinishedfabric_", ""},
+		{"onboarded member", "Org7MSP", policy.Create, "unfinishedfabric_", ""},
+		{"not a member", "Org6MSP", policy.Create, "unfinishedfabric_", "Invoked by: Org6MSP. Organizations allowed to create unfinishedfabric_: [Org5MSP Org7MSP]"},
+		{"operation not granted", "Org5MSP", policy.CreateLot, "unfinishedfabric_", "not invoked by an allowed organization"},
+		{"asset type not granted", "Org5MSP", policy.Create, "cottonbale_", "not invoked by an allowed organization"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := chaincodetest.NewTransactionContext("production", test.mspID)
+			checkErr(t, IsInvokedByAllowedRole(ctx, rolePolicy, test.operation, test.assetType), test.wantErr)
+		})
+	}
+}
+
+func TestIsValidRolePolicy(t *testing.T) {
+	governor := &policy.Role{MSPIDs: []string{"Org1MSP"}, Operations: map[string][]string{policy.AssetType: {policy.Update}}}
+	tests := []struct {
+		name        string
+		roles       map[string]*policy.Role
+		sites       map[string]string
+		unitSystems map[string]string
+		wantErr     string
+	}{
+		{"valid", map[string]*policy.Role{policy.Retailer: governor, policy.TextileMill: {MSPIDs: []string{}}}, map[string]string{"Org1MSP": "Los Angeles, California, USA"}, map[string]string{"Org5MSP": policy.Metric}, ""},
+		{"no roles", nil, nil, nil, "must define at least one role"},
+		{"unnamed role", map[string]*policy.Role{policy.Retailer: governor, "": {}}, nil, nil, "unnamed or empty role"},
+		{"empty role", map[string]*policy.Role{policy.Retailer: governor, policy.Agent: nil}, nil, nil, "unnamed or empty role"},
+		{"empty MSP ID", map[string]*policy.Role{policy.Retailer: governor, policy.Agent: {MSPIDs: []string{""}}}, nil, nil, "role agent contains an empty MSP ID"},
+		{"empty asset type", map[string]*policy.Role{policy.Retailer: governor, policy.Agent: {Operations: map[string][]string{"": {policy.Create}}}}, nil, nil, "role agent grants operations on an empty asset type"},
+		{"empty operation", map[string]*policy.Role{policy.Retailer: governor, policy.Agent: {Operations: map[string][]string{"order_": {""}}}}, nil, nil, "role agent grants an empty operation on order_"},
+		{"site of an empty MSP ID", map[string]*policy.Role{policy.Retailer: governor}, map[string]string{"": "Ashulia, Bangladesh"}, nil, "registers a site for an empty MSP ID"},
+		{"empty site", map[string]*policy.Role{policy.Retailer: governor}, map[string]string{"Org6MSP": ""}, nil, "organization Org6MSP has an empty registered site"},
+		{"unit system of an empty MSP ID", map[string]*policy.Role{policy.Retailer: governor}, nil, map[string]string{"": policy.Metric}, "registers a unit system for an empty MSP ID"},
+		{"unknown unit system", map[string]*policy.Role{policy.Retailer: governor}, nil, map[string]string{"Org5MSP": "furlongs"}, "organization Org5MSP reports in unknown unit system furlongs"},
+		{"nobody governs", map[string]*policy.Role{policy.Agent: {MSPIDs: []string{"Org2MSP"}}}, nil, nil, "must allow at least one organization to update rolepolicy"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, IsValidRolePolicy(&policy.RolePolicy{Roles: test.roles, Sites: test.sites, UnitSystems: test.unitSystems}), test.wantErr)
+		})
+	}
+}
+
+func TestIsValidMigration(t *testing.T) {
+	tests := []struct {
+		name        string
+		fromVersion int
+		toVersion   int
+		pageSize    int
+		wantErr     string
+	}{
+		{"valid", 0, 2, 50, ""},
+		{"downgrade", 2, 1, 50, "must go from an older to a newer schema version, got 2 to 1"},
+		{"same version", 1, 1, 50, "must go from an older to a newer schema version, got 1 to 1"},
+		{"negative version", -1, 1, 50, "must go from an older to a newer schema version, got -1 to 1"},
+		{"unknown version", 1, 3, 50, "schema version 3 is newer than the current schema version 2"},
+		{"empty page", 0, 1, 0, "the page size must be positive, got 0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, IsValidMigration(test.fromVersion, test.toVersion, 2, test.pageSize), test.wantErr)
+		})
+	}
+}
+
+func TestIsValidPageSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageSize int32
+		wantErr  string
+	}{
+		{"valid", 50, ""},
+		{"largest page", MaxPageSize, ""},
+		{"empty page", 0, "the page size must be between 1 and 1000, got 0"},
+		{"negative page", -1, "the page size must be between 1 and 1000, got -1"},
+		{"oversized page", MaxPageSize + 1, "the page size must be between 1 and 1000, got 1001"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, IsValidPageSize(test.pageSize), test.wantErr)
+		})
+	}
+}
+
+func TestIsValidBatchSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		wantErr   string
+	}{
+		{"valid", 20, ""},
+		{"largest batch", MaxBatchSize, ""},
+		{"empty batch", 0, "the batch must hold between 1 and 500 items, got 0"},
+		{"oversized batch", MaxBatchSize + 1, "the batch must hold between 1 and 500 items, got 501"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, IsValidBatchSize(test.batchSize), test.wantErr)
+		})
+	}
+}
+
+func TestIsValidSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		wantErr  string
+	}{
+		{"valid", `{"DocType":"lot_","IsFlagged":true}`, ""},
+		{"empty", `{}`, "the selector must have at least one condition"},
+		{"null", `null`, "the selector must have at least one condition"},
+		{"list", `[{"DocType":"lot_"}]`, "the selector must be a JSON object"},
+		{"malformed", `{"DocType":`, "the selector must be a JSON object"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			checkErr(t, IsValidSelector(test.selector), test.wantErr)
+		})
+	}
+}
@@ -26,11 +26,15 @@ check_status "Deploying production-channel chaincode"
 #   2. Add cotton yarn to ledger as raw materials supplier (org4)
 #   2.1 Assemble cotton yarn into lots (org4)
 #   3. Update cotton yarn lots owner to textiles (org5)
+#   3.1 Dispatch cotton yarn lots to textiles (org4)
+#   3.2 Receive cotton yarn lots (org5)
 #   4. Add unfinished fabric to ledger (org5)
 #   4.1 Assemble unfinished fabric into lots (org5)
 #   5. Add finished fabric to ledger as textiles (org5)
 #   5.1 Assemble finished fabric into lots (org6)
 #   6. Update finished fabric lots owner to fps (org6)
+#   6.1 Dispatch finished fabric lots to fps (org5)
+#   6.2 Receive finished fabric lots (org6)
 #   7. Add cut parts to ledger as fps (org6)
 #   8. Add buttons to ledger as fps (org6)
 #   9. Assemble cut parts and buttons into shirts (org6)
@@ -101,7 +105,7 @@ check_status "Assembling cotton yarn into 2 lots, i.e., lot_2 and lot_3"
 
 sleep 10s
 
-infoln "3/11. Update cotton yarn lots ownership to textiles manufacturer (org5)..."
+infoln "3/11. Update cotton yarn lots ownership to textiles manufacturer (org5) and ship them..."
 setGlobals 5
 # Update cotton yarn lots owner to textiles (org5)
 for i in {2..3}
@@ -112,6 +116,26 @@ check_status "Updating cotton yarn lots ownership to textiles manufacturer (org5
 
 sleep 5s
 
+setGlobals 4
+# Dispatch cotton yarn lots to textiles (org5), which remain in the custody of org4 until they are received
+for i in {2..3}
+do
+  peer chaincode invoke -o localhost:7050 --ordererTLSHostnameOverride orderer.example.com --tls --cafile "$ORDERER_CA" -C production-channel -n production 4 1 2 3 5 6 -c "{\"Args\":[\"DispatchLot\",\"2024-07-05T09:00:00Z\",\"lot_$i\",\"Org5MSP\"]}"
+done
+check_status "Dispatching cotton yarn lots to textiles manufacturer (org5), i.e., lot_2 and lot_3"
+
+sleep 5s
+
+setGlobals 5
+# Receive cotton yarn lots at the textiles site (org5)
+for i in {2..3}
+do
+  peer chaincode invoke -o localhost:7050 --ordererTLSHostnameOverride orderer.example.com --tls --cafile "$ORDERER_CA" -C production-channel -n production 5 1 2 3 4 6 -c "{\"Args\":[\"ReceiveLot\",\"lot_$i\",\"2024-07-05T15:00:00Z\"]}"
+done
+check_status "Receiving cotton yarn lots at textiles manufacturer (org5), i.e., lot_2 and lot_3"
+
+sleep 5s
+
 infoln "4/11. Adding unfinished fabric to ledger (org5)..."
 setGlobals 5
 # Add unfinished fabric
@@ -207,7 +231,7 @@ check_status "Assembling finished fabric into 2 lots, i.e., lot_6 and lot_7"
 
 sleep 5s
 
-infoln "6/11. Update finished fabric lots ownership to fps (org6)..."
+infoln "6/11. Update finished fabric lots ownership to fps (org6) and ship them..."
 setGlobals 6
 # Update finished fabric lots owner to fps (org6)
 for i in {6..7}
@@ -218,6 +242,26 @@ check_status "Updating finished fabric lots ownership to fps (org6), i.e., lot_6
 
 sleep 5s
 
+setGlobals 5
+# Dispatch finished fabric lots to fps (org6), which remain in the custody of org5 until they are received
+for i in {6..7}
+do
+  peer chaincode invoke -o localhost:7050 --ordererTLSHostnameOverride orderer.example.com --tls --cafile "$ORDERER_CA" -C production-channel -n production 5 1 2 3 4 6 -c "{\"Args\":[\"DispatchLot\",\"2024-07-11T09:00:00Z\",\"lot_$i\",\"Org6MSP\"]}"
+done
+check_status "Dispatching finished fabric lots to fps (org6), i.e., lot_6 and lot_7"
+
+sleep 5s
+
+setGlobals 6
+# Receive finished fabric lots at the fps site (org6)
+for i in {6..7}
+do
+  peer chaincode invoke -o localhost:7050 --ordererTLSHostnameOverride orderer.example.com --tls --cafile "$ORDERER_CA" -C production-channel -n production 6 1 2 3 4 5 -c "{\"Args\":[\"ReceiveLot\",\"lot_$i\",\"2024-07-12T15:00:00Z\"]}"
+done
+check_status "Receiving finished fabric lots at fps (org6), i.e., lot_6 and lot_7"
+
+sleep 5s
+
 infoln "7/11. Adding cut parts to ledger as fps (org6)..."
 setGlobals 6
 # Array of parts
@@ -238,7 +282,12 @@ do
   part_index=$(( (i-1) / $ORDER_QUANTITY ))
   # Get the appropriate part
   current_part=${parts[$part_index]}
-  peer chaincode invoke -o localhost:7050 --ordererTLSHostnameOverride orderer.example.com --tls --cafile "$ORDERER_CA" -C production-channel -n production 6 1 2 3 4 5 -c "{\"Args\":[\"CreateCutPart\",\"true\",\"2024-07-13T11:10:00Z\",\"[\\\"lot_6\\\", \\\"lot_7\\\"]\",\"\",\"cutpart_$i\",\"false\",\"Weight in lbs.\",\"Ashulia, Bangladesh\",\"$current_part\", \"0.091\"]}"
+  # Cut the first half of the parts from lot_6 and the rest from lot_7
+  cut_lot="lot_6"
+  if [ $i -gt $((total_parts / 2)) ]; then
+    cut_lot="lot_7"
+  fi
+  peer chaincode invoke -o localhost:7050 --ordererTLSHostnameOverride orderer.example.com --tls --cafile "$ORDERER_CA" -C production-channel -n production 6 1 2 3 4 5 -c "{\"Args\":[\"CreateCutPart\",\"true\",\"2024-07-13T11:10:00Z\",\"[\\\"$cut_lot\\\"]\",\"\",\"cutpart_$i\",\"false\",\"Weight in lbs.\",\"Ashulia, Bangladesh\",\"$current_part\", \"0.091\"]}"
 done
 check_status "Creating $total_parts cut parts for shirts"
 
@@ -331,8 +380,8 @@ do
     done
     garments=${garments%,}  # Remove trailing comma
 
-    # Invoke the chaincode to create a carton
-    peer chaincode invoke -o localhost:7050 --ordererTLSHostnameOverride orderer.example.com --tls --cafile "$ORDERER_CA" -C production-channel -n production 6 1 2 3 4 5 -c "{\"Args\":[\"CreateCarton\",\"true\",\"2024-07-18T10:00:00Z\",\"[$garments]\",\"Org1MSP\",\"\",\"carton_$carton_num\",\"false\",\"Weight in lbs.\",\"Ashulia, Bangladesh\",\"Org6MSP\",\"$carton_weight\"]}"
+    # Invoke the chaincode to create a carton for order_1, endorsed by the retailer's peer since only the peers on admin-channel can read the order
+    peer chaincode invoke -o localhost:7050 --ordererTLSHostnameOverride orderer.example.com --tls --cafile "$ORDERER_CA" -C production-channel -n production --peerAddresses localhost:7051 --tlsRootCertFiles "$PEER0_ORG1_CA" -c "{\"Args\":[\"CreateCarton\",\"true\",\"2024-07-18T10:00:00Z\",\"[$garments]\",\"Org1MSP\",\"\",\"carton_$carton_num\",\"false\",\"Weight in lbs.\",\"order_1\",\"Ashulia, Bangladesh\",\"Org6MSP\",\"$carton_weight\"]}"
 done
 check_status "Packing $ORDER_QUANTITY shirts into $total_cartons cartons"
 
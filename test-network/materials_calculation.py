@@ -29,7 +29,8 @@ def calculate_supply_chain(order_size):
     CARTON_CAPACITY = 20  # shirts
     CONTAINER_CAPACITY = 400  # cartons
 
-    # Waste factors (adjusted to increase overall loss)
+    # Waste factors (adjusted to increase overall loss). The production chaincode reports actual yields against
+    # the same values until SetYieldPolicy stores others (defaultYieldPolicy in production_yield_policy.go)
     COTTON_TO_YARN_YIELD = 0.85
     YARN_TO_UNFINISHED_FABRIC_YIELD = 0.90
     UNFINISHED_TO_FINISHED_FABRIC_YIELD = 0.95